@@ -0,0 +1,134 @@
+package godi
+
+import (
+	"cmp"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// BodyCodec encodes and decodes request and response bodies in a media type other
+// than JSON, e.g. protocol buffers, so typed handlers serve both on the same routes:
+//
+//	type protoCodec struct{}
+//
+//	func (protoCodec) MediaType() string                   { return "application/x-protobuf" }
+//	func (protoCodec) Marshal(v any) ([]byte, error)      { return proto.Marshal(v.(proto.Message)) }
+//	func (protoCodec) Unmarshal(data []byte, v any) error { return proto.Unmarshal(data, v.(proto.Message)) }
+//
+//	app, err := godi.New(module, godi.WithBodyCodecs(protoCodec{}))
+//
+// Unmarshal receives a pointer to the request body, or the body itself, allocated,
+// if its type is a pointer, e.g. a *pb.CreateUserRequest.
+type BodyCodec interface {
+	MediaType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// WithBodyCodecs sets the codecs HandleJSON handlers use, besides JSON, for requests
+// whose Content-Type is their media type, and responses to requests preferring it in
+// their Accept header.
+func WithBodyCodecs(codecs ...BodyCodec) Option {
+	return func(o *options) {
+		o.bodyCodecs = append(o.bodyCodecs, codecs...)
+	}
+}
+
+// requestCodec returns the codec of the request body's media type, or nil if it has
+// none and the body is decoded as JSON.
+func requestCodec(r *http.Request) BodyCodec {
+	state := requestStateFrom(r.Context())
+	if state == nil || len(state.codecs) == 0 {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil
+	}
+
+	for _, c := range state.codecs {
+		if strings.EqualFold(c.MediaType(), mediaType) {
+			return c
+		}
+	}
+	return nil
+}
+
+// responseCodec returns the codec of the media type the request prefers in its Accept
+// header, or nil if it prefers JSON or none of the codecs' media types.
+func responseCodec(r *http.Request) BodyCodec {
+	state := requestStateFrom(r.Context())
+	if state == nil || len(state.codecs) == 0 {
+		return nil
+	}
+
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+
+	var ranges []accepted
+	for _, value := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			q, err := strconv.ParseFloat(params["q"], 64)
+			if err != nil {
+				q = 1
+			}
+			if q > 0 {
+				ranges = append(ranges, accepted{mediaType: mediaType, q: q})
+			}
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b accepted) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	for _, a := range ranges {
+		if matchMediaType(a.mediaType, "application/json") {
+			return nil
+		}
+		for _, c := range state.codecs {
+			if matchMediaType(a.mediaType, c.MediaType()) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// decodeBody decodes the request body into v with the codec, allocating v's
+// value first if it is a nil pointer.
+func decodeBody[T any](c BodyCodec, body io.Reader, v *T) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v).Elem()
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return c.Unmarshal(data, rv.Interface())
+	}
+	return c.Unmarshal(data, v)
+}
+
+// mediaTypes returns the media types typed handlers negotiate: JSON, then those of the codecs.
+func mediaTypes(codecs []BodyCodec) []string {
+	types := []string{"application/json"}
+	for _, c := range codecs {
+		types = append(types, c.MediaType())
+	}
+	return types
+}
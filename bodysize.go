@@ -0,0 +1,63 @@
+package godi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// MaxBodySizeMetadataKey is the metadata key overriding the maximum size of the request
+// bodies of a route, or of every route of a controller, in bytes, e.g.
+// map[string]string{"maxBodySize": "10485760"}. A zero size disables the limit.
+const MaxBodySizeMetadataKey = "maxBodySize"
+
+// defaultMaxBodySize is the maximum size of request bodies decoded by typed handlers.
+const defaultMaxBodySize = 1 << 20
+
+// ErrBodyTooLarge is rendered with 413 when a request body exceeds the maximum size of its route.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// WithMaxBodySize sets the maximum size, in bytes, of the request bodies HandleJSON handlers
+// decode, 1 MiB by default. Larger bodies are rejected with 413 and ErrBodyTooLarge. Routes
+// override it with their MaxBodySizeMetadataKey metadata, and a size of zero disables it.
+func WithMaxBodySize(n int64) Option {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// limitBody bounds the request body to the route's maximum body size,
+// if the request is handled by a route with one.
+func limitBody(w http.ResponseWriter, r *http.Request) {
+	state := requestStateFrom(r.Context())
+	if state == nil || state.maxBodySize <= 0 || r.Body == nil {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, state.maxBodySize)
+}
+
+// bodyErrorStatus returns the status of an error reading a request body:
+// 413 if it exceeded its maximum size, or 400.
+func bodyErrorStatus(err error) (int, error) {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return http.StatusRequestEntityTooLarge, ErrBodyTooLarge
+	}
+	return http.StatusBadRequest, err
+}
+
+// routeMaxBodySize returns the maximum body size set by the MaxBodySizeMetadataKey
+// metadata, which take precedence in order, or fallback.
+func routeMaxBodySize(fallback int64, metadata ...any) (int64, error) {
+	v, ok := MetadataValue(MaxBodySizeMetadataKey, metadata...)
+	if !ok {
+		return fallback, nil
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s metadata (%s): %w", MaxBodySizeMetadataKey, v, err)
+	}
+	return n, nil
+}
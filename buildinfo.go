@@ -0,0 +1,117 @@
+package godi
+
+import (
+	"cmp"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata injected at link time, taking precedence over the values
+// read from the binary's embedded build information:
+//
+//	go build -ldflags "-X github.com/huboh/godi.Version=v1.2.0 -X github.com/huboh/godi.Commit=$(git rev-parse HEAD)"
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+// BuildInfo describes the build of the running binary.
+//
+// It is provided to every module, so constructors can attach it to loggers
+// and metrics.
+type BuildInfo struct {
+	// Version is the version of the main module, e.g. "v1.2.0" or "(devel)".
+	Version string `json:"version"`
+
+	// Commit is the VCS revision the binary was built from.
+	Commit string `json:"commit,omitempty"`
+
+	// BuildDate is the time of the commit, or the date injected at link time.
+	BuildDate string `json:"buildDate,omitempty"`
+
+	// GoVersion is the version of the Go toolchain that built the binary.
+	GoVersion string `json:"goVersion"`
+
+	// Path is the import path of the main package.
+	Path string `json:"path,omitempty"`
+}
+
+// ReadBuildInfo returns the build information of the running binary, read from
+// the values injected at link time and the binary's embedded build information.
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Path = bi.Path
+	info.Version = cmp.Or(info.Version, bi.Main.Version)
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = cmp.Or(info.Commit, s.Value)
+		case "vcs.time":
+			info.BuildDate = cmp.Or(info.BuildDate, s.Value)
+		}
+	}
+
+	return info
+}
+
+// Labels returns the non-empty build information as metric labels.
+func (b BuildInfo) Labels() map[string]string {
+	labels := map[string]string{}
+	for key, value := range map[string]string{
+		"version":    b.Version,
+		"commit":     b.Commit,
+		"build_date": b.BuildDate,
+		"go_version": b.GoVersion,
+	} {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// LogValue implements the slog.LogValuer interface, so the build information
+// can be attached to log records, e.g. logger.With("build", info).
+func (b BuildInfo) LogValue() slog.Value {
+	var attrs []slog.Attr
+	for _, attr := range []slog.Attr{
+		slog.String("version", b.Version),
+		slog.String("commit", b.Commit),
+		slog.String("build_date", b.BuildDate),
+		slog.String("go_version", b.GoVersion),
+	} {
+		if attr.Value.String() != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// WithVersionEndpoint mounts an endpoint at the given path serving the
+// application's BuildInfo as JSON, e.g. "/version".
+func WithVersionEndpoint(path string) Option {
+	return func(o *options) {
+		o.versionPath = path
+	}
+}
+
+func (a *App) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(a.buildInfo)
+}
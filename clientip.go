@@ -0,0 +1,76 @@
+package godi
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// WithTrustedProxies trusts the X-Forwarded-For header of the requests sent by the
+// proxies in the prefixes, e.g. a load balancer, so ClientIP returns the address of
+// the client they forwarded the request for rather than the address of the last proxy:
+//
+//	godi.WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))
+//
+// Only the proxies the application runs behind must be trusted, as the header is
+// otherwise set by clients themselves to spoof their address.
+func WithTrustedProxies(prefixes ...netip.Prefix) Option {
+	return func(o *options) {
+		o.trustedProxies = append(o.trustedProxies, prefixes...)
+	}
+}
+
+// ClientIP returns the IP address of the client that sent the request.
+//
+// The address is taken from the connection, unless it is the address of a proxy trusted
+// with WithTrustedProxies, in which case the X-Forwarded-For header is read from right to
+// left, skipping the addresses of trusted proxies, up to the address of the first hop that
+// is not trusted. Forwarding headers are ignored for requests not handled by a route.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+
+	state := requestStateFrom(r.Context())
+	if state == nil || len(state.proxies) == 0 {
+		return addr.Unmap().String()
+	}
+
+	hops := forwardedHops(r.Header.Values("X-Forwarded-For"))
+	for len(hops) > 0 && isTrusted(addr, state.proxies) {
+		hop, err := netip.ParseAddr(hops[len(hops)-1])
+		if err != nil {
+			break
+		}
+		addr, hops = hop, hops[:len(hops)-1]
+	}
+	return addr.Unmap().String()
+}
+
+// forwardedHops returns the addresses listed by the X-Forwarded-For header values,
+// from the client to the last proxy.
+func forwardedHops(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, hop := range strings.Split(v, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// isTrusted reports whether the address is in any of the trusted prefixes.
+func isTrusted(addr netip.Addr, proxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	return slices.ContainsFunc(proxies, func(p netip.Prefix) bool {
+		return p.Contains(addr)
+	})
+}
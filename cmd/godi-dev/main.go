@@ -0,0 +1,66 @@
+// Command godi-dev rebuilds and restarts a godi application whenever its sources change.
+//
+// Usage:
+//
+//	godi-dev [flags] [package] [-- args...]
+//
+// For example:
+//
+//	go run github.com/huboh/godi/cmd/godi-dev -addr localhost:5000 ./cmd/api
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/huboh/godi/pkg/dev"
+)
+
+func main() {
+	var (
+		cfg     dev.Config
+		exts    string
+		exclude string
+	)
+
+	flag.StringVar(&cfg.Root, "root", ".", "directory watched for changes")
+	flag.StringVar(&cfg.Addr, "addr", "", "address held across restarts, e.g. localhost:5000")
+	flag.StringVar(&exts, "exts", "", "comma separated extensions that trigger a rebuild")
+	flag.StringVar(&exclude, "exclude", "", "comma separated directory names that are not watched")
+	flag.DurationVar(&cfg.Interval, "interval", 500*time.Millisecond, "how often sources are checked for changes")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: godi-dev [flags] [package] [-- args...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) > 0 && args[0] != "--" {
+		cfg.Package, args = args[0], args[1:]
+	}
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	cfg.Args = args
+
+	if exts != "" {
+		cfg.Exts = strings.Split(exts, ",")
+	}
+	if exclude != "" {
+		cfg.Exclude = strings.Split(exclude, ",")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := dev.Run(ctx, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,100 @@
+// Command godi-gen scaffolds godi modules, controllers, services and guards.
+//
+// Usage:
+//
+//	godi-gen module     [-dir dir] [-root file] <name>
+//	godi-gen controller [-dir dir] <module> <name>
+//	godi-gen service    [-dir dir] <module> <name>
+//	godi-gen guard      [-dir dir] <module> <name>
+//
+// For example:
+//
+//	go run github.com/huboh/godi/cmd/godi-gen module -dir internal/modules -root internal/app/module.go users
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/huboh/godi/pkg/gen"
+)
+
+const usage = `usage:
+  godi-gen module     [-dir dir] [-root file] [-force] <name>
+  godi-gen controller [-dir dir] [-force] <module> <name>
+  godi-gen service    [-dir dir] [-force] <module> <name>
+  godi-gen guard      [-dir dir] [-force] <module> <name>
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "godi-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("missing command")
+	}
+
+	var (
+		g    gen.Generator
+		kind = args[0]
+		fs   = flag.NewFlagSet(kind, flag.ExitOnError)
+	)
+
+	fs.StringVar(&g.Dir, "dir", "modules", "directory holding the module packages")
+	fs.BoolVar(&g.Force, "force", false, "overwrite existing files")
+	if kind == "module" {
+		fs.StringVar(&g.Root, "root", "", "file declaring the root module the module is imported into")
+	}
+	fs.Usage = func() { fmt.Fprint(fs.Output(), usage); fs.PrintDefaults() }
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	var (
+		files []string
+		err   error
+	)
+
+	switch kind {
+	case "module":
+		if fs.NArg() != 1 {
+			fs.Usage()
+			return fmt.Errorf("module takes a name")
+		}
+		files, err = g.Module(fs.Arg(0))
+
+	case "controller", "service", "guard":
+		if fs.NArg() != 2 {
+			fs.Usage()
+			return fmt.Errorf("%s takes a module and a name", kind)
+		}
+
+		generate := map[string]func(string, string) (string, error){
+			"controller": g.Controller,
+			"service":    g.Service,
+			"guard":      g.Guard,
+		}[kind]
+
+		var file string
+		file, err = generate(fs.Arg(0), fs.Arg(1))
+		if file != "" {
+			files = append(files, file)
+		}
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", kind)
+	}
+
+	for _, file := range files {
+		fmt.Println("created", file)
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package godi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Coalescer is an Interceptor collapsing identical concurrent requests into a single
+// execution of the handler, whose response is sent to every waiting request. It avoids
+// stampedes on expensive endpoints, e.g. when many clients refill a cache at once.
+//
+// Only safe requests (GET and HEAD) are coalesced, and handlers wrapped by a
+// Coalescer should not stream their responses.
+type Coalescer struct {
+	key func(r *http.Request) string
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall is an in-flight execution of a handler shared by identical requests.
+type coalescedCall struct {
+	done     chan struct{}
+	response *ResponseBuffer
+}
+
+// Coalesce returns a Coalescer identifying identical requests by the key returned by key.
+// A nil key identifies requests by their method, URL, content negotiation headers and
+// credentials: their Authorization and Cookie headers, the principal set by guards and
+// the TLS client certificate, so responses are never shared between users or between
+// clients negotiating different representations.
+//
+// Routes authenticating requests in other ways, e.g. with an API key header checked by the
+// handler rather than by a guard setting a principal, must pass a key including it.
+//
+// Waiting requests receive the response of the request that executed the handler, even
+// if its context was canceled while the handler ran, e.g. because its client went away.
+func Coalesce(key func(r *http.Request) string) *Coalescer {
+	if key == nil {
+		key = defaultCoalesceKey
+	}
+
+	return &Coalescer{
+		key:   key,
+		calls: map[string]*coalescedCall{},
+	}
+}
+
+// coalesceKeyHeaders are the request headers identical requests share, as they
+// select the representation of the response or carry credentials.
+var coalesceKeyHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language", "Authorization", "Cookie"}
+
+func defaultCoalesceKey(r *http.Request) string {
+	var b strings.Builder
+
+	b.WriteString(r.Method + " " + r.URL.String())
+	for _, name := range coalesceKeyHeaders {
+		b.WriteString("\x00" + strings.Join(r.Header.Values(name), ","))
+	}
+
+	if principal := principalFrom(r.Context()); principal != nil {
+		fmt.Fprintf(&b, "\x00%T:%+v", principal, principal)
+	}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		sum := sha256.Sum256(r.TLS.PeerCertificates[0].Raw)
+		b.WriteString("\x00" + hex.EncodeToString(sum[:]))
+	}
+	return b.String()
+}
+
+// Intercept implements the Interceptor interface.
+func (c *Coalescer) Intercept(ictx InterceptorContext, next http.Handler) error {
+	var (
+		w = ictx.Http.W
+		r = ictx.Http.R
+	)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		next.ServeHTTP(w, r)
+		return nil
+	}
+
+	key := c.key(r)
+
+	c.mu.Lock()
+	call, inFlight := c.calls[key]
+	if !inFlight {
+		call = &coalescedCall{done: make(chan struct{})}
+		c.calls[key] = call
+	}
+	c.mu.Unlock()
+
+	if !inFlight {
+		// the response is recorded before the call is released,
+		// even if the handler panics
+		func() {
+			defer func() {
+				c.mu.Lock()
+				delete(c.calls, key)
+				c.mu.Unlock()
+				close(call.done)
+			}()
+
+			rec := NewResponseBuffer()
+			next.ServeHTTP(rec, r)
+			call.response = rec
+		}()
+	} else {
+		select {
+		case <-call.done:
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+
+	if call.response == nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil
+	}
+	return call.response.Replay(w)
+}
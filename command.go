@@ -0,0 +1,32 @@
+package godi
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// CommandRunner dispatches command line arguments to the commands registered by
+// modules, e.g. migrations, admin tasks and seeders that reuse the app's dependency graph.
+//
+// It is provided by the cli module in github.com/huboh/godi/pkg/modules/cli.
+type CommandRunner interface {
+	RunCommand(ctx context.Context, args []string) error
+}
+
+// RunCLI runs the command named by the first argument, passing it the remaining arguments.
+//
+// The command's context is canceled when the process receives SIGINT or SIGTERM.
+func (a *App) RunCLI(args []string) error {
+	runner, err := Resolve[CommandRunner](a)
+	if err != nil {
+		return fmt.Errorf("error resolving command runner (is the cli module imported?): %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(a.opts.ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return runner.RunCommand(ctx, args)
+}
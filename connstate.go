@@ -0,0 +1,47 @@
+package godi
+
+import (
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// ConnStateHook is a function called when a client connection changes state.
+type ConnStateHook func(c net.Conn, state http.ConnState)
+
+// OnConnState registers a hook called whenever a client connection changes state,
+// e.g. to record connection metrics. Hooks run in the order they were registered,
+// and should be registered before the server starts listening.
+func (s *HttpServer) OnConnState(hook ConnStateHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.connStateHooks = append(s.connStateHooks, hook)
+}
+
+// connState runs the connection state hooks.
+func (s *HttpServer) connState(c net.Conn, state http.ConnState) {
+	s.mu.Lock()
+	hooks := slices.Clone(s.connStateHooks)
+	s.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(c, state)
+	}
+}
+
+// SetKeepAlivesEnabled controls whether HTTP keep-alives are enabled. They are
+// enabled by default, and disabling them makes the server close connections after
+// each request, e.g. to rebalance clients across instances.
+func (s *HttpServer) SetKeepAlivesEnabled(v bool) {
+	s.server.SetKeepAlivesEnabled(v)
+}
+
+// WithIdleTimeout sets how long an idle keep-alive connection is kept open
+// before the server closes it. Defaults to no timeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = d
+	}
+}
@@ -2,11 +2,13 @@ package godi
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"slices"
 	"strings"
-
-	"go.uber.org/dig"
+	"time"
 )
 
 // Controller is any type that can receive inbound requests and produce responses.
@@ -21,6 +23,13 @@ type ControllerConfig struct {
 	// within the controller.
 	Pattern string
 
+	// Host restricts the controller's routes to requests for the host, e.g. "admin.example.com".
+	// Routes setting their own host override it. Routes match every host if empty.
+	//
+	// Wildcard labels like "{tenant}.example.com" match a single label of the request's host,
+	// whose value is available with r.PathValue("tenant").
+	Host string
+
 	// Metadata holds arbitrary metadata associated with the controller.
 	Metadata any
 
@@ -33,6 +42,14 @@ type ControllerConfig struct {
 	// GuardsCtors provides constructors for creating guard instances that
 	// requires dependency injection.
 	GuardsCtors []GuardConstructor
+
+	// Interceptors contains interceptor instances applied to all routes in the controller.
+	// Controller interceptors wrap the interceptors of each route.
+	Interceptors []Interceptor
+
+	// InterceptorsCtors provides constructors for creating interceptor instances that
+	// requires dependency injection.
+	InterceptorsCtors []InterceptorConstructor
 }
 
 // ControllerConstructor is a function type that creates Controller instances. It may have dependencies as
@@ -45,9 +62,13 @@ type ControllerConstructor constructor
 // controller is a wrapper for managing an instance of a Controller.
 type controller struct {
 	Controller
+	cfg    *ControllerConfig
 	module *module
 	routes []*route
 	guards []*guard
+
+	interceptors  []*interceptor
+	errorRenderer ErrorRenderer
 }
 
 const (
@@ -61,15 +82,31 @@ const (
 
 func newController(c Controller, m *module) (*controller, error) {
 	ctrl := &controller{
+		cfg:        c.Config(),
 		module:     m,
 		Controller: c,
 	}
 
-	err := ctrl._registerGuards()
+	err := ctrl.Config().validate()
+	if err != nil {
+		return nil, wrapErrors(err, "invalid config for controller (%T) in module (%T)", c, m.Module)
+	}
+
+	ctrl.errorRenderer, err = m.resolveErrorRenderer()
+	if err != nil {
+		return nil, fmt.Errorf("error resolving error renderer: %w", err)
+	}
+
+	ctrl.guards, err = buildGuards(m, ctrl.Config().Guards, ctrl.Config().GuardsCtors)
 	if err != nil {
 		return nil, fmt.Errorf("error registering guards: %w", err)
 	}
 
+	ctrl.interceptors, err = buildInterceptors(m, ctrl.Config().Interceptors, ctrl.Config().InterceptorsCtors)
+	if err != nil {
+		return nil, fmt.Errorf("error registering interceptors: %w", err)
+	}
+
 	err = ctrl._registerRoutes()
 	if err != nil {
 		return nil, err
@@ -78,113 +115,219 @@ func newController(c Controller, m *module) (*controller, error) {
 	return ctrl, nil
 }
 
+// Config returns the controller's config, which is read once when the controller
+// is created rather than on every request.
+func (c *controller) Config() *ControllerConfig {
+	return c.cfg
+}
+
 // getPath constructs the full path for a route
 // by combining the controller's root pattern with the route's pattern.
 func (c *controller) getPath(r route) string {
-	root := cmp.Or(c.Config().Pattern, defaultPath)
+	root := strings.TrimSuffix(cmp.Or(c.Config().Pattern, defaultPath), pathSeparator)
 	path := strings.TrimPrefix(r.Pattern, pathSeparator)
 
-	return strings.TrimSpace(
-		fmt.Sprintf(
-			"%s %s", r.Method, strings.Join([]string{root, path}, pathSeparator),
-		),
-	)
+	return strings.Join([]string{root, path}, pathSeparator)
 }
 
-// getGuards retrieves the list of guards for a given route,
-// including both controller-scoped guards and route-scoped guards.
-func (c *controller) getGuards(r route) []*guard {
-	return append(c.guards, r.guards...)
+// getHost returns the host the route is restricted to, if any.
+// It may be a template with wildcards like "{tenant}.example.com".
+func (c *controller) getHost(r route) string {
+	return cmp.Or(r.Host, c.Config().Host)
 }
 
-func (c *controller) getHandler(r route) http.Handler {
+// getGuards retrieves the list of guards for a given route, in the order they run:
+// global, module-scoped, controller-scoped and route-scoped guards. A guard instance
+// listed at several levels only runs at the first one.
+func (c *controller) getGuards(r *route) []*guard {
+	guards := slices.Concat(c.module.app.guards, c.module.guards, c.guards, r.guards)
+	return uniqueBy(guards, func(g *guard) any { return g.Guard })
+}
+
+// getInterceptors retrieves the list of interceptors for a given route, from the outermost
+// to the innermost: global, module-scoped, controller-scoped and route-scoped interceptors.
+// An interceptor instance listed at several levels only wraps the route at the first one,
+// and selective interceptors only wrap the routes they select.
+func (c *controller) getInterceptors(r *route) []*interceptor {
+	interceptors := slices.Concat(c.module.app.interceptors, c.module.interceptors, c.interceptors, r.interceptors)
+	interceptors = uniqueBy(interceptors, func(i *interceptor) any { return i.Interceptor })
+	return selectInterceptors(interceptors, r.info)
+}
+
+// uniqueBy removes the items whose value is identical to the value of a previous item,
+// keeping the first occurrence. Values that are not comparable, like functions, are
+// never considered identical.
+func uniqueBy[T any](items []T, value func(T) any) []T {
 	var (
-		guards  = c.getGuards(r)
-		handler = r.Handler
+		seen   []any
+		unique = items[:0:0]
 	)
 
+	for _, item := range items {
+		v := value(item)
+		if reflect.ValueOf(v).Comparable() {
+			if slices.Contains(seen, v) {
+				continue
+			}
+			seen = append(seen, v)
+		}
+		unique = append(unique, item)
+	}
+	return unique
+}
+
+// buildPipeline builds the request pipeline of the route once, so dispatching
+// a request only copies the guard context and runs the prebuilt chain.
+func (c *controller) buildPipeline(r *route) {
+	r.guardCtx = GuardContext{
+		RouteCfg:      *r.RouteConfig,
+		ControllerCfg: *c.Config(),
+	}
+	r.interceptorCtx = InterceptorContext{
+		RouteCfg:      *r.RouteConfig,
+		ControllerCfg: *c.Config(),
+	}
+
+	r.info = newRouteInfo(c, r)
+	r.chain = c.getGuards(r)
+	r.exempt = c.module.app.isMaintenanceExempt(r.info)
+	if shedder := c.module.app.shedder; shedder != nil {
+		shedder.addPriority(r.priority)
+	}
+	r.handler = chainInterceptors(c, r, c.getInterceptors(r), c.module.app.observeHandler(r.Handler))
+}
+
+func (c *controller) getHandler(r *route) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, req *http.Request) {
-			gCtx := newGuardCtx(*c, r, w, req)
-			allowed, err := c.runGuards(gCtx, guards)
-			// TODO: panic with errors and handle with filters
-			if !allowed {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+			req, state := withRequestState(w, req, r.info, c.errorRenderer, c.module.app.opts.logger, c.module.app.opts.ids)
+			state.translators = c.module.app.opts.validationTranslators
+			state.codecs = c.module.app.opts.bodyCodecs
+			state.proxies = c.module.app.opts.trustedProxies
+			state.maxBodySize = r.maxBodySize
+			if !r.exempt && c.module.app.maintenance.reject(w, req, c.errorRenderer) {
 				return
 			}
+			if shedder := c.module.app.shedder; shedder != nil {
+				if shedder.reject(w, req, r.priority, c.errorRenderer) {
+					return
+				}
+				defer shedder.observe(time.Now())
+			}
 
+			req, allowed, err := c.runGuards(r.newGuardCtx(w, state), r.chain)
+			if errors.Is(err, ErrResponded) {
+				return
+			}
 			if err != nil {
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				c.errorRenderer.RenderError(w, req, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+
+			if !allowed {
+				c.errorRenderer.RenderError(w, req, http.StatusForbidden, ErrForbidden)
+				return
+			}
+
+			if !r.acceptsContentType(req) {
+				c.errorRenderer.RenderError(w, req, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType)
 				return
 			}
 
-			handler.ServeHTTP(w, req)
+			if !r.producesAccepted(req) {
+				c.errorRenderer.RenderError(w, req, http.StatusNotAcceptable, ErrNotAcceptable)
+				return
+			}
+
+			r.handler.ServeHTTP(w, req)
 		},
 	)
 }
 
-func (c *controller) runGuards(gCtx GuardContext, guards []*guard) (bool, error) {
+// runGuards runs the guards in order, returning the request with the context
+// set by the guards that enriched it.
+func (c *controller) runGuards(gCtx GuardContext, guards []*guard) (*http.Request, bool, error) {
 	for _, guard := range guards {
+		gCtx.Http.R = gCtx.req.r
+
+		start := time.Now()
 		allowed, err := guard.Allow(gCtx)
+		elapsed := time.Since(start)
+
+		c.module.app.observeLayer(gCtx.req.r, LayerGuard, guard.Guard, elapsed)
+		c.module.app.debugRequest(gCtx.req.r, "guard %T: allowed=%t err=%v in %s", guard.Guard, allowed, err, elapsed)
+
 		if (!allowed) || (err != nil) {
-			return false, err
+			return gCtx.req.r, false, err
 		}
 	}
-	return true, nil
+	return gCtx.req.r, true, nil
 }
 
 func (c *controller) _registerRoutes() error {
-	return c.module.scope.Invoke(
+	return c.module.invoke(
 		func(server *HttpServer) error {
+			var errs []error
 			for _, rCfg := range c.Config().RoutesCfgs {
-				// create route from config
-				r, err := newRoute(rCfg, c)
-				if err != nil {
-					return err
-				}
-				c.routes = append(c.routes, r)
-
-				// register route handler for it's path
-				server.mux.Handle(c.getPath(*r), c.getHandler(*r))
+				errs = append(errs, c.registerRoute(rCfg))
 			}
-			return nil
+			return errors.Join(errs...)
 		},
 	)
 }
 
-func (c *controller) _registerGuards() error {
-	var (
-		cCfg = c.Config()
-		opts = []dig.ProvideOption{
-			dig.As(new(Guard)),
-			dig.Group(groupGuards.String()),
+// registerRoute creates the route from its config and registers its handler
+// for each of its methods, sharing the guards and interceptors between them.
+func (c *controller) registerRoute(rCfg *RouteConfig) error {
+	r, err := newRoute(rCfg, c)
+	if err != nil {
+		return err
+	}
+	c.routes = append(c.routes, r)
+
+	if r.Name != "" {
+		err = c.module.app.registerRouteName(r.Name, c.getPath(*r), c)
+		if err != nil {
+			return err
 		}
+	}
+
+	c.buildPipeline(r)
+
+	var (
+		handler = c.getHandler(r)
+		host    = c.getHost(*r)
+		path    = c.getPath(*r)
 	)
 
-	for _, grd := range cCfg.Guards {
-		err := c.module.scope.Provide(func() Guard { return grd }, opts...)
+	for _, method := range r.methods() {
+		pattern := strings.TrimSpace(method + " " + host + path)
+
+		err = c.module.app.registerRoute(pattern, c)
 		if err != nil {
-			return fmt.Errorf("error providing controller guard (%T): %w", grd, err)
+			return err
 		}
-	}
 
-	for _, grdCtor := range cCfg.GuardsCtors {
-		err := c.module.scope.Provide(grdCtor, opts...)
+		err = c.module.app.handleRoute(method, host, path, handler)
 		if err != nil {
-			return fmt.Errorf("error providing controller guard (%T): %w", grdCtor, err)
+			return fmt.Errorf(
+				"error registering route (%s) of controller (%T) in module (%T): %w",
+				pattern, c.Controller, c.module.Module, err,
+			)
 		}
 	}
+	return nil
+}
 
-	return c.module.scope.Invoke(
-		func(input guardGroupInput) error {
-			for _, grd := range input.Guards {
-				g, err := newGuard(grd)
-				if err != nil {
-					return err
-				}
-				c.guards = append(c.guards, g)
-			}
-			return nil
-		},
-	)
+// handle registers the handler for the pattern, returning the error ServeMux
+// panics with when the pattern is malformed or conflicts with another one.
+func handle(mux *http.ServeMux, pattern string, handler http.Handler) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%v", v)
+		}
+	}()
+
+	mux.Handle(pattern, handler)
+	return nil
 }
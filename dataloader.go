@@ -0,0 +1,220 @@
+package godi
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotLoaded is returned by DataLoader for keys missing from the values returned by its BatchFunc.
+var ErrNotLoaded = errors.New("key not loaded by batch function")
+
+// BatchFunc loads the values of a batch of distinct keys in a single call, e.g. with
+// one "WHERE id IN (...)" query. Keys missing from the returned map are reported
+// with ErrNotLoaded, and a panic fails the whole batch with an error.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// DataLoader batches and caches the loads of values by key within a request, so
+// handlers and resolvers loading related values one by one make a single call to
+// its BatchFunc instead of one per value, avoiding N+1 queries.
+//
+// A DataLoader is provided once and shared by every request, while the keys it
+// batches and the values it caches are kept per request:
+//
+//	ProvidersCtors: []godi.ProviderConstructor{
+//		func(users *UserRepository) *godi.DataLoader[int, *User] {
+//			return godi.NewDataLoader(users.FindByIDs, time.Millisecond, 100)
+//		},
+//	}
+//
+//	author, err := c.users.Load(r.Context(), post.AuthorID)
+//
+// Loads made outside of a route's request are not cached, and only the keys of a
+// single LoadMany call are batched together.
+type DataLoader[K comparable, V any] struct {
+	batch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+}
+
+// NewDataLoader returns a DataLoader calling batch with the keys loaded within wait of
+// the first key of the batch, or as soon as maxBatch keys are collected. wait defaults
+// to a millisecond if zero, and batches are not bounded if maxBatch is zero.
+func NewDataLoader[K comparable, V any](batch BatchFunc[K, V], wait time.Duration, maxBatch int) *DataLoader[K, V] {
+	return &DataLoader[K, V]{
+		batch:    batch,
+		wait:     cmp.Or(wait, time.Millisecond),
+		maxBatch: maxBatch,
+	}
+}
+
+// Load returns the value of the key, loading it with the other keys of its batch
+// unless the request has already loaded it.
+func (l *DataLoader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return l.batcher(ctx).load(ctx, key)
+}
+
+// LoadMany returns the values of the keys in the order of the keys, loading them
+// in as few batches as possible. The first error encountered is returned.
+func (l *DataLoader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	var (
+		b       = l.batcher(ctx)
+		results = make([]*loaderResult[V], len(keys))
+		values  = make([]V, len(keys))
+	)
+
+	for i, key := range keys {
+		results[i] = b.enqueue(key)
+	}
+
+	for i, res := range results {
+		v, err := res.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// batcher returns the request's batcher of the loader,
+// or a batcher discarded after the load outside of a request.
+func (l *DataLoader[K, V]) batcher(ctx context.Context) *loaderBatcher[K, V] {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return newLoaderBatcher(ctx, l)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if b, ok := state.loaders[l].(*loaderBatcher[K, V]); ok {
+		return b
+	}
+
+	if state.loaders == nil {
+		state.loaders = map[any]any{}
+	}
+
+	// batches run detached from cancellation so a canceled load
+	// does not fail the loads sharing its batch.
+	b := newLoaderBatcher(context.WithoutCancel(ctx), l)
+	state.loaders[l] = b
+	return b
+}
+
+// loaderBatcher collects the keys loaded within a request and caches their results.
+type loaderBatcher[K comparable, V any] struct {
+	ctx    context.Context
+	loader *DataLoader[K, V]
+
+	mu      sync.Mutex
+	cache   map[K]*loaderResult[V]
+	pending []K
+}
+
+func newLoaderBatcher[K comparable, V any](ctx context.Context, l *DataLoader[K, V]) *loaderBatcher[K, V] {
+	return &loaderBatcher[K, V]{
+		ctx:    ctx,
+		loader: l,
+		cache:  map[K]*loaderResult[V]{},
+	}
+}
+
+func (b *loaderBatcher[K, V]) load(ctx context.Context, key K) (V, error) {
+	return b.enqueue(key).wait(ctx)
+}
+
+// enqueue returns the result of the key, adding the key to the pending batch
+// if it was not loaded yet.
+func (b *loaderBatcher[K, V]) enqueue(key K) *loaderResult[V] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if res, ok := b.cache[key]; ok {
+		return res
+	}
+
+	res := &loaderResult[V]{done: make(chan struct{})}
+	b.cache[key] = res
+	b.pending = append(b.pending, key)
+
+	switch {
+	case b.loader.maxBatch > 0 && len(b.pending) >= b.loader.maxBatch:
+		go b.dispatch(b.takePending())
+	case len(b.pending) == 1:
+		time.AfterFunc(b.loader.wait, b.flush)
+	}
+
+	return res
+}
+
+// takePending removes the pending keys, which must be done with the lock held.
+func (b *loaderBatcher[K, V]) takePending() []K {
+	keys := b.pending
+	b.pending = nil
+	return keys
+}
+
+func (b *loaderBatcher[K, V]) flush() {
+	b.mu.Lock()
+	keys := b.takePending()
+	b.mu.Unlock()
+
+	if len(keys) > 0 {
+		b.dispatch(keys)
+	}
+}
+
+// dispatch loads the keys with the batch function and completes their results.
+// A panicking batch function fails every result of the batch with the panic.
+func (b *loaderBatcher[K, V]) dispatch(keys []K) {
+	values, err := b.runBatch(keys)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range keys {
+		res := b.cache[key]
+		switch v, ok := values[key]; {
+		case err != nil:
+			res.err = err
+		case !ok:
+			res.err = ErrNotLoaded
+		default:
+			res.value = v
+		}
+		close(res.done)
+	}
+}
+
+// runBatch calls the batch function, which runs on its own goroutine, recovering
+// from its panics so they fail the batch rather than crash the process.
+func (b *loaderBatcher[K, V]) runBatch(keys []K) (values map[K]V, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("batch function panicked: %v", v)
+		}
+	}()
+	return b.loader.batch(b.ctx, keys)
+}
+
+// loaderResult is the result of loading a key, available once done is closed.
+type loaderResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func (r *loaderResult[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,126 @@
+package godi
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers carrying the caller's remaining time budget for a request.
+const (
+	// GRPCTimeoutHeader holds a timeout in the gRPC format, e.g. "250m" for 250 milliseconds.
+	GRPCTimeoutHeader = "Grpc-Timeout"
+
+	// RequestTimeoutHeader holds a timeout as a duration, e.g. "1.5s", or a number of seconds.
+	RequestTimeoutHeader = "X-Request-Timeout"
+)
+
+// DeadlinePropagator is an Interceptor applying the timeout sent by the caller in the
+// Grpc-Timeout or X-Request-Timeout header to the request's context, so database queries
+// and outgoing HTTP calls made with it stop once the caller has given up.
+//
+// Requests without a valid timeout header are handled with their context unchanged.
+type DeadlinePropagator struct {
+	max time.Duration
+}
+
+// PropagateDeadline returns a DeadlinePropagator bounding caller timeouts by max,
+// so callers cannot hold resources longer than the route allows. A zero max
+// applies caller timeouts as they are.
+func PropagateDeadline(max time.Duration) *DeadlinePropagator {
+	return &DeadlinePropagator{max: max}
+}
+
+// Intercept implements the Interceptor interface.
+func (d *DeadlinePropagator) Intercept(ictx InterceptorContext, next http.Handler) error {
+	var (
+		w = ictx.Http.W
+		r = ictx.Http.R
+	)
+
+	timeout, ok := requestTimeout(r.Header)
+	if !ok {
+		next.ServeHTTP(w, r)
+		return nil
+	}
+
+	if d.max > 0 {
+		timeout = min(timeout, d.max)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+	return nil
+}
+
+// requestTimeout returns the timeout sent in the request headers, preferring
+// the gRPC timeout when both headers are present.
+func requestTimeout(h http.Header) (time.Duration, bool) {
+	if v := h.Get(GRPCTimeoutHeader); v != "" {
+		if d, ok := parseGRPCTimeout(v); ok {
+			return d, true
+		}
+	}
+
+	if v := h.Get(RequestTimeoutHeader); v != "" {
+		if d, ok := parseRequestTimeout(v); ok {
+			return d, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseGRPCTimeout parses a timeout of at most eight digits followed by a unit:
+// H (hours), M (minutes), S (seconds), m (milliseconds), u (microseconds) or n (nanoseconds).
+func parseGRPCTimeout(v string) (time.Duration, bool) {
+	if len(v) < 2 || len(v) > 9 {
+		return 0, false
+	}
+
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+
+	unit, ok := units[v[len(v)-1]]
+	if !ok {
+		return 0, false
+	}
+
+	// eight digits of hours overflow a Duration
+	n, err := strconv.ParseUint(v[:len(v)-1], 10, 64)
+	if err != nil || n == 0 || n > uint64(math.MaxInt64/unit) {
+		return 0, false
+	}
+
+	return time.Duration(n) * unit, true
+}
+
+// parseRequestTimeout parses a timeout written as a duration or a number of seconds.
+func parseRequestTimeout(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+
+	if d, err := time.ParseDuration(v); err == nil {
+		if d <= 0 {
+			return 0, false
+		}
+		return d, true
+	}
+
+	secs, err := strconv.ParseFloat(v, 64)
+	if err != nil || !(secs > 0 && secs < float64(math.MaxInt64)/float64(time.Second)) {
+		return 0, false
+	}
+
+	return time.Duration(secs * float64(time.Second)), true
+}
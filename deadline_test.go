@@ -0,0 +1,128 @@
+package godi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseGRPCTimeout(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"1H", time.Hour, true},
+		{"30M", 30 * time.Minute, true},
+		{"5S", 5 * time.Second, true},
+		{"250m", 250 * time.Millisecond, true},
+		{"100u", 100 * time.Microsecond, true},
+		{"99999999n", 99999999 * time.Nanosecond, true},
+		{"2562047H", 2562047 * time.Hour, true},
+		{"2562048H", 0, false}, // overflows a Duration
+		{"99999999H", 0, false},
+		{"99999999M", 99999999 * time.Minute, true},
+		{"", 0, false},
+		{"S", 0, false},
+		{"0S", 0, false},
+		{"-1S", 0, false},
+		{"+1S", 0, false},
+		{"1.5S", 0, false},
+		{"1s", 0, false},
+		{"123456789S", 0, false}, // more than eight digits
+		{"1 S", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := parseGRPCTimeout(tt.in)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseGRPCTimeout(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseRequestTimeout(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"1.5s", 1500 * time.Millisecond, true},
+		{"250ms", 250 * time.Millisecond, true},
+		{" 2m ", 2 * time.Minute, true},
+		{"3", 3 * time.Second, true},
+		{"0.25", 250 * time.Millisecond, true},
+		{"0", 0, false},
+		{"0s", 0, false},
+		{"-1s", 0, false},
+		{"-3", 0, false},
+		{"1e300", 0, false},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := parseRequestTimeout(tt.in)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseRequestTimeout(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDeadlinePropagator(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		max    time.Duration
+		want   time.Duration // zero if the context has no deadline.
+	}{
+		{"grpc timeout", http.Header{GRPCTimeoutHeader: {"2S"}}, 0, 2 * time.Second},
+		{"request timeout", http.Header{RequestTimeoutHeader: {"3s"}}, 0, 3 * time.Second},
+		{"grpc timeout preferred", http.Header{GRPCTimeoutHeader: {"2S"}, RequestTimeoutHeader: {"3s"}}, 0, 2 * time.Second},
+		{"invalid grpc timeout falls back", http.Header{GRPCTimeoutHeader: {"99999999H"}, RequestTimeoutHeader: {"3s"}}, 0, 3 * time.Second},
+		{"bounded by max", http.Header{GRPCTimeoutHeader: {"10H"}}, time.Second, time.Second},
+		{"no header", http.Header{}, time.Second, 0},
+		{"overflowing header", http.Header{GRPCTimeoutHeader: {"99999999H"}}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctx context.Context
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx = r.Context()
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header = tt.header
+
+			ictx := InterceptorContext{}
+			ictx.Http.W, ictx.Http.R = httptest.NewRecorder(), r
+
+			start := time.Now()
+			err := PropagateDeadline(tt.max).Intercept(ictx, next)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			deadline, ok := ctx.Deadline()
+			switch {
+			case tt.want == 0 && ok:
+				t.Errorf("deadline set in %v, want none", deadline.Sub(start))
+			case tt.want != 0 && !ok:
+				t.Errorf("no deadline, want one in %v", tt.want)
+			case tt.want != 0:
+				if d := deadline.Sub(start); d < tt.want-time.Second/10 || d > tt.want+time.Second/10 {
+					t.Errorf("deadline in %v, want %v", d, tt.want)
+				}
+			}
+		})
+	}
+}
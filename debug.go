@@ -0,0 +1,83 @@
+package godi
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.uber.org/dig"
+)
+
+// WithDebug enables debug mode, logging every Provide and Invoke performed by
+// godi along with the module scope it happened in, the dependencies resolved,
+// the values produced and their group memberships.
+//
+// Each guard and interceptor run for a request is logged too, along with its
+// outcome and duration, so rejected requests can be traced to their guard.
+//
+// This makes "why isn't my provider found" investigations tractable.
+func WithDebug() Option {
+	return WithDebugLogger(log.New(os.Stderr, "[godi] ", log.LstdFlags))
+}
+
+// WithDebugLogger enables debug mode, writing the trace to the given logger.
+func WithDebugLogger(l *log.Logger) Option {
+	return func(o *options) {
+		o.debug = l
+	}
+}
+
+// debugf logs a debug trace line when debug mode is enabled,
+// masking any secrets it contains.
+func (a *App) debugf(format string, args ...any) {
+	if a.opts.debug != nil {
+		a.opts.debug.Print(a.opts.redactor.RedactString(fmt.Sprintf(format, args...)))
+	}
+}
+
+func (a *App) debugProvide(m *module, ctor any, info dig.ProvideInfo, err error) {
+	if a.opts.debug == nil {
+		return
+	}
+	if err != nil {
+		a.debugf("provide %T in %s failed: %v", ctor, m.path(), err)
+		return
+	}
+	a.debugf(
+		"provide %T in %s: inputs [%s] outputs [%s]",
+		ctor, m.path(), joinStrings(info.Inputs), joinStrings(info.Outputs),
+	)
+}
+
+func (a *App) debugInvoke(m *module, fn any, info dig.InvokeInfo, err error) {
+	if a.opts.debug == nil {
+		return
+	}
+	if err != nil {
+		a.debugf("invoke %T in %s failed: %v", fn, m.path(), err)
+		return
+	}
+	a.debugf("invoke %T in %s: inputs [%s]", fn, m.path(), joinStrings(info.Inputs))
+}
+
+// debugRequest logs a debug trace line about the request handled by a route
+// when debug mode is enabled.
+func (a *App) debugRequest(r *http.Request, format string, args ...any) {
+	if a.opts.debug == nil {
+		return
+	}
+	a.debugf(
+		"request %s %s (%s): %s",
+		r.Method, r.URL.Path, RequestIDFrom(r.Context()), fmt.Sprintf(format, args...),
+	)
+}
+
+func joinStrings[T fmt.Stringer](values []T) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.String()
+	}
+	return strings.Join(s, ", ")
+}
@@ -36,6 +36,23 @@
 // If a dependency itself does not require any other dependencies, you can opt to inject it directly without using a constructor.
 // This can be more convenient than defining a constructor, especially for simple dependencies.
 //
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Supply(&Config{Port: "5000"})}
+//
+// The typed helpers [godi.Provide], [godi.Supply] and [godi.Named] state the provided type at the registration
+// site, and [godi.Resolve] retrieves a value from a created application.
+//
+// # Field Injection
+//
+// Types that prefer field injection over long constructor parameter lists can be registered with
+// [godi.Struct], which populates the exported fields tagged `godi:"inject"` from the container.
+//
+//	type UserService struct {
+//		DB    *sql.DB `godi:"inject"`
+//		Cache *Cache  `godi:"inject,optional"`
+//	}
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Struct[UserService]()}
+//
 // # Modules
 //
 // Modules are the core organizational unit in Godi. Each module encapsulates related functionality
@@ -59,6 +76,13 @@
 //		}
 //	}
 //
+// Modules listed in ImportsOptional are not imported, but their exports are available within the module when
+// the application imports them elsewhere, so reusable modules can integrate with metrics or tracing modules
+// through optional dependencies without requiring them.
+//
+// Modules configured through their fields can implement [godi.Validator] to check them when the application
+// is created, before any module is wired, reporting missing options as configuration errors.
+//
 // # Controllers
 //
 // Controllers handle HTTP routing and request processing. They provide a structured way to define
@@ -104,6 +128,13 @@
 //		}
 //	}
 //
+// Routes without a Method handle every method, and routes listing several Methods share their
+// handler, guards and metadata between them.
+//
+// Controllers and routes setting a Host, e.g. "admin.example.com", only match requests for that host,
+// so multi-domain applications can route per host within one App. Hosts may contain wildcard labels,
+// e.g. "{tenant}.example.com", whose values guards and handlers read like path wildcards with r.PathValue.
+//
 // # Guards
 //
 // Guards are used to control access to controllers or individual routes,
@@ -134,6 +165,42 @@
 //	    return validated, nil
 //	}
 //
+// Guards that authenticate requests can attach the principal with [GuardContext.SetPrincipal],
+// and later guards, interceptors and handlers retrieve it with [godi.PrincipalFrom].
+//
+//	func (g *AuthGuard) Allow(gCtx godi.GuardContext) (bool, error) {
+//	    claims, err := g.auth.Validate(gCtx.BearerToken())
+//	    if err != nil {
+//	        return false, err
+//	    }
+//
+//	    gCtx.SetPrincipal(claims)
+//	    return true, nil
+//	}
+//
+//	claims, ok := godi.PrincipalFrom[*Claims](r.Context())
+//
+// Applications served over mutual TLS with [godi.WithTLS] attach the identity of the peer service, its SPIFFE ID
+// or certificate common name, as the initial [godi.PeerIdentity] principal of its requests, and [godi.AllowPeers]
+// restricts routes to the listed services.
+//
+// [godi.ClientIP] returns the address of a request's client, read from the X-Forwarded-For header of the
+// requests sent by the proxies trusted with [godi.WithTrustedProxies], and used by the modules keying on it.
+//
+// Guards can also enrich the request context with [GuardContext.SetContext], e.g. with the resolved tenant,
+// and later guards, interceptors and the handler receive the request with the new context.
+//
+// [godi.LoggerFrom] returns a request's logger, derived from the logger set with [godi.WithLogger] and
+// populated with its request ID, trace ID, route pattern and the principal attached by a guard. Request IDs
+// are generated with the [godi.IDGenerator] set with [godi.WithIDGenerator], which is also provided to every
+// module along with the source of randomness set with [godi.WithRandSource], so tests can reproduce them.
+//
+// [godi.RouteFrom] returns the [godi.RouteInfo] of the route matched by a request, including its name,
+// pattern and metadata, so generic guards and interceptors can make route-aware decisions.
+//
+// A [godi.DataLoader] provided once batches and caches the values its handlers load by key within each request,
+// so loading related values one by one makes a single call to its [godi.BatchFunc] instead of N.
+//
 // **Controller-scoped Guards**:
 //
 // Guards defined at the controller level will be applied to all routes within that controller.
@@ -166,6 +233,125 @@
 //		}
 //	}
 //
+// Guard constructors are invoked once for the controller or route that lists them, so route guards only
+// apply to their route. Controller guards run before route guards, in the order they are declared.
+//
+// **Global Guards**:
+//
+// Guards registered with [godi.WithGuards] and [godi.WithGuardsCtors] apply to every route of the application
+// and run before module guards. Their constructors are built once in the root module's scope.
+//
+//	app, err := godi.New(&app.Module{}, godi.WithGuardsCtors(auth.NewAuthGuard))
+//
+// **Module-scoped Guards**:
+//
+// Guards listed in the Guards and GuardsCtors fields of a module config apply to every route of the module's
+// own controllers, but not to the controllers of the modules it imports.
+//
+// **Execution Order**:
+//
+// Guards run from the outermost layer to the innermost: global, module, controller and route guards, each in the
+// order they are declared. Interceptors wrap routes in the same order, global interceptors being the outermost.
+// A guard or interceptor instance listed at several layers, e.g. the same *AuthGuard in a controller and one of
+// its routes, only runs at the outermost layer listing it. Instances that are not comparable, like functions,
+// are never deduplicated.
+//
+// # Interceptors
+//
+// Interceptors wrap the execution of route handlers once guards have allowed a request, and are used to
+// transform requests or responses. They can be applied to a whole controller or to individual routes through
+// the Interceptors and InterceptorsCtors fields, with controller interceptors wrapping route interceptors.
+// Modules wrap the routes of their controllers with the Interceptors and InterceptorsCtors fields of their config,
+// and interceptors registered with [godi.WithInterceptors] and [godi.WithInterceptorsCtors] wrap every route of the
+// application, outside of module interceptors.
+//
+// [godi.Select] restricts an interceptor to the routes matching a [godi.RouteSelector], by metadata key and value,
+// path glob or method, e.g. to cache only the routes tagged "cacheable":
+//
+//	godi.WithInterceptors(godi.Select(godi.RouteSelector{Metadata: map[string]string{"cacheable": ""}}, cache))
+//
+// [godi.ResponseMapper] transforms JSON responses, e.g. to convert internal models to DTOs or strip fields based
+// on the user's roles, and [godi.Envelope] wraps successful responses in a {"data": ..., "meta": ...} envelope.
+//
+//	func (c *UserController) Config() *godi.ControllerConfig {
+//		return &godi.ControllerConfig{
+//			...
+//			Interceptors: []godi.Interceptor{godi.Envelope(nil)},
+//		}
+//	}
+//
+// Handlers respond with [godi.Redirect], [godi.Created] and [godi.Paginated], which writes pages of items in
+// the same envelope with the cursor of the next page, whether or not the route is wrapped by Envelope.
+//
+// [godi.Paginate] parses the page, limit and cursor query parameters of list endpoints into a [godi.PageRequest]
+// retrieved with [godi.PageRequestFrom], and [godi.WritePage] responds with the page's metadata and Link headers
+// to the surrounding pages. [godi.ParseListQuery] parses the sort, fields and filter[...] query parameters into
+// a [godi.ListQuery] retrieved with [godi.ListQueryFrom], rejecting fields and operators its spec does not allow.
+//
+// Services record domain events with [godi.RecordEvent] while handling a request, and [godi.DispatchEvents] hands them
+// to an [godi.EventDispatcher] once the request completes successfully, discarding them otherwise.
+//
+// [godi.Coalesce] collapses identical concurrent requests into a single execution of the handler,
+// and long-poll handlers wait for changes broadcast through a [godi.Notifier].
+//
+// [godi.PropagateDeadline] applies the timeout sent by callers in the Grpc-Timeout or X-Request-Timeout
+// header to the request's context, so downstream database and HTTP calls respect the caller's budget.
+//
+// [godi.Timeout] bounds the time handlers take to respond, canceling the request's context and rendering 504 with
+// [godi.ErrHandlerTimeout] once it expires. [godi.WithRequestTimeout] applies it to every route, and routes override
+// it with a "timeout" metadata entry, e.g. map[string]string{"timeout": "30s"}, or "0" for streaming routes.
+//
+// [App.Maintenance] switches the application to maintenance mode, e.g. during migrations, responding to requests
+// with 503 and a Retry-After header before their guards run. Routes exempt with the [godi.MaintenanceExemptKey]
+// metadata or [godi.WithMaintenanceExempt] stay live, like the probes of the health module and the admin module,
+// which toggles maintenance mode at runtime.
+//
+// [godi.WithLoadShedding] protects core endpoints under overload, responding to the requests of the lowest-priority
+// routes with 503 and [godi.ErrOverloaded] while the CPU usage, goroutine count or 99th percentile latency exceed
+// their thresholds, shedding one more priority every interval they do. Routes set their priority with a
+// "priority" metadata entry, e.g. map[string]string{"priority": "10"}, and [App.LoadShedder] reports the signals.
+//
+// # Error Rendering
+//
+// Error responses, e.g. when a guard rejects a request, are rendered by the [godi.ErrorRenderer] provided
+// in the route's module scope, so a module providing one overrides the rendering of its controllers and
+// of the modules it imports. Handlers render errors consistently with their module with [godi.RenderError].
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Supply[godi.ErrorRenderer](godi.JSONErrorRenderer)}
+//
+// Errors returned by guards and interceptors are rendered with 500, unless they wrap a [godi.StatusError]
+// carrying another status, e.g. 429 for a consumer that exhausted its quota. Guards and interceptors that
+// responded to the request themselves, e.g. with a bot challenge, return [godi.ErrResponded] to stop the
+// pipeline without rendering an error.
+//
+// **Error Codes**:
+//
+// Modules declare the [godi.ErrorCode]s they return in their config's ErrorCodes. Errors carrying one are
+// rendered with its status, and the built-in renderers include its code and user-facing message, so clients
+// handle errors by code. [godi.App.ErrorCodes] lists the registry, e.g. to publish it for client teams.
+//
+//	var ErrOutOfStock = &godi.ErrorCode{Code: "orders.out_of_stock", Status: http.StatusConflict, Message: "Out of stock."}
+//
+// # Typed Handlers
+//
+// [godi.HandleJSON] adapts a function taking and returning typed bodies into a route handler,
+// decoding JSON requests and encoding JSON responses. The request and response types of typed
+// handlers are exported as JSON Schemas by [App.Schemas], for contract tests and client generators.
+//
+//	Handler: godi.HandleJSON(func(r *http.Request, req CreateUserRequest) (*User, error) {
+//		return c.users.Create(r.Context(), req)
+//	}),
+//
+// Request bodies implementing [godi.Validator] are validated before the function is called, and rejected
+// with 422 when they return [godi.ValidationErrors], whose messages are translated in the languages the
+// request accepts by the translators set with [godi.WithValidationTranslators].
+//
+//	godi.WithValidationTranslators(godi.Messages{"en": {"required": "{field} is required"}})
+//
+// Typed handlers also serve the media types of the [godi.BodyCodec]s set with [godi.WithBodyCodecs], e.g.
+// protocol buffers, negotiated by the request's Content-Type and Accept headers. Their request bodies are
+// limited to 1 MiB, or the size set with [godi.WithMaxBodySize], and larger ones are rejected with 413.
+//
 // # Structuring Modules
 //
 // Godi applications are modular, and the main root module is configured to import other modules.
@@ -212,6 +398,75 @@
 //		}
 //	}
 //
+// Applications can also be mounted in an existing server, a serverless adapter or a test harness
+// through [HttpServer.Handler], without calling Listen.
+//
+//	mux.Handle("/api/", http.StripPrefix("/api", app.Handler()))
+//
+// Listening on port "0" binds an ephemeral port, reported by [HttpServer.Addr] once [HttpServer.Ready]
+// is closed, and [godi.WithListenRetry] retries binding an address that is still in use, e.g. by a previous process.
+//
+// Modules implementing [godi.Starter] are started once the server listens, and modules implementing
+// [godi.Stopper] are stopped as soon as it shuts down. Constructors can inject the [*godi.HttpServer]
+// and register hooks with [HttpServer.OnStart] and [HttpServer.OnShutdown] instead.
+//
+// # Startup Context
+//
+// Constructors may accept a context.Context to receive the startup context. It is derived from the
+// context passed with [godi.WithContext] and bounded by [godi.WithStartupTimeout], so blocking
+// initializations like dialing a database or running migrations can be canceled cleanly.
+//
+//	func NewDatabase(ctx context.Context, cfg *Config) (*sql.DB, error) {
+//		db, err := sql.Open("postgres", cfg.DSN)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return db, db.PingContext(ctx)
+//	}
+//
+//	app, err := godi.New(&app.Module{}, godi.WithStartupTimeout(10*time.Second))
+//
+// The startup context is canceled once [godi.New] returns and should not be retained.
+//
+// # Profiles
+//
+// Applications created with [godi.WithProfile] only import the modules whose config lists the active profile
+// in Profiles, and only provide the constructors wrapped with [godi.OnProfile] for the active profile, replacing
+// scattered environment checks. Modules and constructors without profiles are wired in every profile.
+//
+//	app, err := godi.New(&app.Module{}, godi.WithProfile(godi.Profile(os.Getenv("APP_PROFILE"))))
+//
+// # Lazy Modules
+//
+// Imported modules setting Lazy in their config build their controllers, and the providers they depend on,
+// when a request under one of their LazyPaths is first received, which keeps rarely used modules like admin
+// panels out of the startup path. Their start and stop hooks are still registered when the application is created.
+//
+//	return &godi.ModuleConfig{Lazy: true, LazyPaths: []string{"/reports"}, ...}
+//
+// # Build Information
+//
+// The [godi.BuildInfo] of the running binary is provided to every module, read from its embedded build
+// information and the [godi.Version], [godi.Commit] and [godi.BuildDate] values injected with -ldflags.
+// It can be attached to loggers as a slog value, and [godi.WithVersionEndpoint] serves it as JSON.
+//
+//	app, err := godi.New(&app.Module{}, godi.WithVersionEndpoint("/version"))
+//
+// # Debugging
+//
+// [godi.WithDebug] logs every registration and resolution godi performs along with the module it
+// happened in, which helps track down missing providers. [App.StartupReport] and [godi.WithStartupLog]
+// report how long each module and constructor took to initialize, and [App.Instances] lists the values
+// built so far with their module and creation time, which helps find eager or duplicated instances.
+//
+// Debug mode also logs each guard and interceptor run for a request with its outcome and duration,
+// which helps find the guard rejecting a request. [godi.WithLayerObserver] reports the same durations to a function,
+// e.g. to benchmark the request pipeline.
+//
+// Debug traces and constructor errors are masked by the application's [godi.Redactor], which can be
+// set with [godi.WithRedactor]. Fields tagged `redact:"true"` and keys matching its pattern are masked,
+// and [Redactor.ReplaceAttr] applies the same rules to slog records, e.g. in access logs.
+//
 // # Best Practices
 //
 //   - Keep modules focused and cohesive - each module should have a single responsibility
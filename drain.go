@@ -0,0 +1,97 @@
+package godi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DrainStatus reports the server's in-flight requests, e.g. to observe the
+// progress of draining requests during shutdown.
+type DrainStatus struct {
+	// ShuttingDown reports whether the server's shutdown has started.
+	ShuttingDown bool `json:"shuttingDown"`
+
+	// InFlight is the number of requests being handled.
+	InFlight int `json:"inFlight"`
+
+	// OldestRequestAge is how long the oldest in-flight request has been running.
+	OldestRequestAge time.Duration `json:"oldestRequestAge"`
+}
+
+// DrainStatus returns the server's in-flight requests and whether it is shutting down.
+func (s *HttpServer) DrainStatus() DrainStatus {
+	status := DrainStatus{
+		ShuttingDown: s.shuttingDown.Load(),
+	}
+
+	s.inflight.mu.Lock()
+	defer s.inflight.mu.Unlock()
+
+	now := time.Now()
+	status.InFlight = len(s.inflight.requests)
+	for _, start := range s.inflight.requests {
+		status.OldestRequestAge = max(status.OldestRequestAge, now.Sub(start))
+	}
+
+	return status
+}
+
+// requestTracker records the start time of in-flight requests.
+type requestTracker struct {
+	mu       sync.Mutex
+	nextID   uint64
+	requests map[uint64]time.Time
+}
+
+func (t *requestTracker) start() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.requests == nil {
+		t.requests = map[uint64]time.Time{}
+	}
+
+	t.nextID++
+	t.requests[t.nextID] = time.Now()
+	return t.nextID
+}
+
+func (t *requestTracker) done(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.requests, id)
+}
+
+// track wraps the handler to record its in-flight requests.
+func (s *HttpServer) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := s.inflight.start()
+			defer s.inflight.done(id)
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// WithReadinessEndpoint mounts a readiness endpoint at the given path reporting the
+// server's DrainStatus. It responds with 503 Service Unavailable as soon as shutdown
+// starts, so load balancers stop sending traffic while in-flight requests drain.
+func WithReadinessEndpoint(path string) Option {
+	return func(o *options) {
+		o.readinessPath = path
+	}
+}
+
+func (s *HttpServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	status := s.DrainStatus()
+	code := http.StatusOK
+	if status.ShuttingDown {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(status)
+}
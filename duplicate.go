@@ -0,0 +1,187 @@
+package godi
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"go.uber.org/dig"
+)
+
+// providerKey identifies a value in the dependency injection container.
+type providerKey struct {
+	t    reflect.Type
+	name string
+}
+
+func (k providerKey) String() string {
+	if k.name != "" {
+		return fmt.Sprintf("%v[name=%q]", k.t, k.name)
+	}
+	return k.t.String()
+}
+
+// in returns a dig.In struct type whose second field receives the value identified by k.
+func (k providerKey) in() reflect.Type {
+	fields := []reflect.StructField{
+		{Name: "In", Type: inType, Anonymous: true},
+		{Name: "Value", Type: k.t},
+	}
+
+	if k.name != "" {
+		fields[1].Tag = reflect.StructTag(fmt.Sprintf("name:%q", k.name))
+	}
+
+	return reflect.StructOf(fields)
+}
+
+// registration records a provider constructor and the module scope it was registered in.
+type registration struct {
+	ctor   constructor
+	owner  *module // the module declaring the constructor.
+	scope  *module // the module whose scope the constructor was provided in.
+	global bool    // whether the constructor is visible in every scope.
+	export bool    // whether the registration forwards an export of the owner's providers.
+}
+
+// overlaps reports whether the two registrations are visible from a common scope.
+func (r registration) overlaps(o registration) bool {
+	return r.global || o.global || r.scope.isAncestorOf(o.scope) || o.scope.isAncestorOf(r.scope)
+}
+
+// registerProvider records the constructor's results and reports a descriptive
+// error if any of them is already provided in an overlapping scope.
+//
+// The same constructor provided in several scopes is not a conflict, nor are the
+// exports of a module forwarding its own providers to importing modules.
+func (a *App) registerProvider(reg registration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.providers == nil {
+		a.providers = map[providerKey][]registration{}
+	}
+
+	keys := resultKeys(reg.ctor)
+	for _, k := range keys {
+		if k == errorRendererKey {
+			continue
+		}
+		for _, existing := range a.providers[k] {
+			if sameConstructor(existing.ctor, reg.ctor) || !existing.overlaps(reg) {
+				continue
+			}
+			if existing.owner == reg.owner && (existing.export || reg.export) {
+				continue
+			}
+			return fmt.Errorf(
+				"duplicate provider for %v: provided by %s in module %s and by %s in module %s; "+
+					"move the provider to a shared module that both import, or provide distinct types",
+				k, funcName(existing.ctor), existing.owner.path(), funcName(reg.ctor), reg.owner.path(),
+			)
+		}
+	}
+
+	for _, k := range keys {
+		a.providers[k] = append(a.providers[k], reg)
+	}
+
+	return nil
+}
+
+// sameConstructor reports whether both constructors are the same function value.
+//
+// Function values are compared by the address of their closure rather than by name,
+// as the closures of generic functions such as Supply and the functions created with
+// reflect.MakeFunc such as Struct's share a name regardless of what they construct.
+func sameConstructor(a, b constructor) bool {
+	pa, pb := funcValue(a), funcValue(b)
+	return pa != nil && pa == pb
+}
+
+// funcValue returns the address of the closure of a constructor function, which is
+// the same for every reference to a top-level function, or nil if it is not a function.
+func funcValue(ctor constructor) unsafe.Pointer {
+	if a, ok := ctor.(annotated); ok {
+		return funcValue(a.ctor)
+	}
+
+	v := reflect.ValueOf(ctor)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil
+	}
+
+	// a func value is a pointer to its closure
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return *(*unsafe.Pointer)(p.UnsafePointer())
+}
+
+// errorRendererKey is the key of the ErrorRenderer, which modules override for
+// their controllers and the modules they import by providing one.
+var errorRendererKey = providerKey{t: reflect.TypeFor[ErrorRenderer]()}
+
+var (
+	errorType = reflect.TypeFor[error]()
+	inType    = reflect.TypeFor[dig.In]()
+	outType   = reflect.TypeFor[dig.Out]()
+)
+
+// resultKeys returns the keys of the values a constructor produces,
+// excluding value groups which may have any number of providers.
+func resultKeys(ctor constructor) []providerKey {
+	if a, ok := ctor.(annotated); ok {
+		if a.group != "" {
+			return nil
+		}
+
+		keys := resultKeys(a.ctor)
+		for _, iface := range a.as {
+			keys = append(keys, providerKey{t: iface})
+		}
+		if a.name != "" {
+			for i := range keys {
+				keys[i].name = a.name
+			}
+		}
+		return keys
+	}
+
+	t := reflect.TypeOf(ctor)
+	if t == nil || t.Kind() != reflect.Func {
+		return nil
+	}
+
+	var keys []providerKey
+	for i := range t.NumOut() {
+		out := t.Out(i)
+		switch {
+		case out == errorType:
+			continue
+		case isOutStruct(out):
+			for j := range out.NumField() {
+				f := out.Field(j)
+				if (f.Anonymous && f.Type == outType) || (f.Tag.Get("group") != "") || (!f.IsExported()) {
+					continue
+				}
+				keys = append(keys, providerKey{t: f.Type, name: f.Tag.Get("name")})
+			}
+		default:
+			keys = append(keys, providerKey{t: out})
+		}
+	}
+
+	return keys
+}
+
+func isOutStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range t.NumField() {
+		if f := t.Field(i); f.Anonymous && f.Type == outType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package godi
+
+import (
+	"strings"
+	"testing"
+)
+
+// testModule is a module with a fixed config. Modules are identified by their type,
+// so the modules of a tree embed it in distinct types.
+type testModule struct {
+	cfg *ModuleConfig
+}
+
+func (m *testModule) Config() *ModuleConfig {
+	return m.cfg
+}
+
+type (
+	rootModule   struct{ testModule }
+	usersModule  struct{ testModule }
+	ordersModule struct{ testModule }
+)
+
+func newPort() int {
+	return 8080
+}
+
+func TestDuplicateProviders(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    *ModuleConfig
+		users   *ModuleConfig
+		orders  *ModuleConfig
+		wantErr string
+	}{
+		{
+			name:    "same type in parent and child",
+			root:    &ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(1)}},
+			users:   &ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(2)}},
+			wantErr: "duplicate provider for int",
+		},
+		{
+			name:    "same type exported by a global module",
+			users:   &ModuleConfig{IsGlobal: true, ProvidersCtors: []ProviderConstructor{newPort}, ExportsCtors: []ProviderConstructor{newPort}},
+			orders:  &ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(2)}},
+			wantErr: "duplicate provider for int",
+		},
+		{
+			name:    "same type exported to the parent of a sibling",
+			users:   &ModuleConfig{ProvidersCtors: []ProviderConstructor{newPort}, ExportsCtors: []ProviderConstructor{newPort}},
+			orders:  &ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(2)}},
+			wantErr: "duplicate provider for int",
+		},
+		{
+			name:   "same type in a global module without exports",
+			users:  &ModuleConfig{IsGlobal: true, ProvidersCtors: []ProviderConstructor{Supply(1)}},
+			orders: &ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(2)}},
+		},
+		{
+			name:    "same name",
+			root:    &ModuleConfig{ProvidersCtors: []ProviderConstructor{Named[int]("port").Supply(1)}},
+			users:   &ModuleConfig{ProvidersCtors: []ProviderConstructor{Named[int]("port").Supply(2)}},
+			wantErr: `duplicate provider for int[name="port"]`,
+		},
+		{
+			name:   "same type in sibling modules",
+			users:  &ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(1)}},
+			orders: &ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(2)}},
+		},
+		{
+			name:  "same constructor in parent and child",
+			root:  &ModuleConfig{ProvidersCtors: []ProviderConstructor{newPort}},
+			users: &ModuleConfig{ProvidersCtors: []ProviderConstructor{newPort}},
+		},
+		{
+			name:  "distinct names",
+			root:  &ModuleConfig{ProvidersCtors: []ProviderConstructor{Named[int]("port").Supply(1)}},
+			users: &ModuleConfig{ProvidersCtors: []ProviderConstructor{Named[int]("workers").Supply(2)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(newTestTree(tt.root, tt.users, tt.orders))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("New = %v, want no error", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("New = %v, want %q", err, tt.wantErr)
+			}
+			// the error names the module declaring each constructor
+			if strings.Count(err.Error(), "in module *godi.") != 2 {
+				t.Errorf("New = %v, want the error to name both modules", err)
+			}
+		})
+	}
+}
+
+// newTestTree returns a root module importing the users and orders modules,
+// leaving out those whose config is nil.
+func newTestTree(root, users, orders *ModuleConfig) Module {
+	if root == nil {
+		root = &ModuleConfig{}
+	}
+	if users != nil {
+		root.Imports = append(root.Imports, &usersModule{testModule{users}})
+	}
+	if orders != nil {
+		root.Imports = append(root.Imports, &ordersModule{testModule{orders}})
+	}
+	return &rootModule{testModule{root}}
+}
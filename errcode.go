@@ -0,0 +1,136 @@
+package godi
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// ErrorCode is an application error declared in the error code registry, so clients
+// handle it by its stable code rather than by its message, and every route renders it
+// the same way.
+//
+// Modules declare the codes they return in their config, and return them as errors,
+// alone or wrapping their cause:
+//
+//	var ErrOutOfStock = &godi.ErrorCode{
+//		Code:    "orders.out_of_stock",
+//		Status:  http.StatusConflict,
+//		Message: "The product is out of stock.",
+//		DocsURL: "https://docs.example.com/errors#orders.out_of_stock",
+//	}
+//
+//	ErrorCodes: []*godi.ErrorCode{ErrOutOfStock},
+//
+//	return nil, ErrOutOfStock.Wrap(err)
+//
+// Errors carrying an ErrorCode are rendered with its status when returned by guards,
+// interceptors and HandleJSON handlers, and the built-in ErrorRenderers render its code
+// and message, which must therefore be safe to show to users.
+type ErrorCode struct {
+	// Code identifies the error, e.g. "orders.out_of_stock".
+	Code string `json:"code"`
+
+	// Number is an optional numeric identifier of the error, for clients using numeric codes.
+	Number int `json:"number,omitempty"`
+
+	// Status is the HTTP status code the error is rendered with.
+	Status int `json:"status"`
+
+	// Message is the user-facing description of the error.
+	Message string `json:"message"`
+
+	// DocsURL links to the documentation of the error.
+	DocsURL string `json:"docsUrl,omitempty"`
+}
+
+func (e *ErrorCode) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// Wrap returns an error carrying the code and wrapping err, so both errors.Is(err, e)
+// and the cause's own identity hold.
+func (e *ErrorCode) Wrap(err error) error {
+	if err == nil {
+		return e
+	}
+	return &codedError{code: e, err: err}
+}
+
+// codedError is an error returned by ErrorCode.Wrap.
+type codedError struct {
+	code *ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.code.Error() + ": " + e.err.Error()
+}
+
+func (e *codedError) Unwrap() []error {
+	return []error{e.code, e.err}
+}
+
+// errorCodeOf returns the ErrorCode in err's chain, if any.
+func errorCodeOf(err error) (*ErrorCode, bool) {
+	var ec *ErrorCode
+	ok := errors.As(err, &ec)
+	return ec, ok
+}
+
+// ErrorCodes returns the error codes declared by the modules of the application,
+// sorted by code, so they can be exported for the teams building its clients.
+func (a *App) ErrorCodes() []ErrorCode {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	codes := make([]ErrorCode, 0, len(a.errorCodes))
+	for _, ec := range a.errorCodes {
+		codes = append(codes, *ec)
+	}
+	slices.SortFunc(codes, func(a, b ErrorCode) int {
+		return cmp.Compare(a.Code, b.Code)
+	})
+	return codes
+}
+
+func (a *App) handleErrorCodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(a.ErrorCodes())
+}
+
+// _registerErrorCodes registers the error codes declared by the module, reporting
+// codes and numbers declared with different definitions by different modules.
+func (m *module) _registerErrorCodes() error {
+	m.app.mu.Lock()
+	defer m.app.mu.Unlock()
+
+	if m.app.errorCodes == nil {
+		m.app.errorCodes = map[string]*ErrorCode{}
+	}
+
+	var errs []error
+	for _, ec := range m.Config().ErrorCodes {
+		if existing, ok := m.app.errorCodes[ec.Code]; ok {
+			if *existing != *ec {
+				errs = append(errs, fmt.Errorf("error code %q is already declared with another definition", ec.Code))
+			}
+			continue
+		}
+
+		if ec.Number != 0 {
+			for _, existing := range m.app.errorCodes {
+				if existing.Number == ec.Number {
+					errs = append(errs, fmt.Errorf("error code %q has the number %d of error code %q", ec.Code, ec.Number, existing.Code))
+				}
+			}
+		}
+		m.app.errorCodes[ec.Code] = ec
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package godi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/dig"
+)
+
+var (
+	// ErrForbidden is rendered when a guard rejects a request.
+	ErrForbidden = errors.New("request rejected by guard")
+
+	// ErrUnsupportedMediaType is rendered when a request body's media type is not consumed by the route.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+	// ErrNotAcceptable is rendered when a request does not accept any media type produced by the route.
+	ErrNotAcceptable = errors.New("not acceptable")
+
+	// ErrResponded is returned by guards and interceptors that responded to the request
+	// themselves, e.g. with a bot challenge, to stop the pipeline without rendering an error.
+	ErrResponded = errors.New("request responded to")
+)
+
+// StatusError is an error rendered with its status code when returned by a guard or an
+// interceptor, rather than with 500, e.g. to reject requests with 401 or 429:
+//
+//	return false, &godi.StatusError{Status: http.StatusTooManyRequests, Err: ErrQuotaExceeded}
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// errorStatus returns the status code of the StatusError or ErrorCode in err's chain, or fallback.
+func errorStatus(err error, fallback int) int {
+	var se *StatusError
+	if errors.As(err, &se) && se.Status >= http.StatusBadRequest {
+		return se.Status
+	}
+	if ec, ok := errorCodeOf(err); ok && ec.Status >= http.StatusBadRequest {
+		return ec.Status
+	}
+	return fallback
+}
+
+// ErrorRenderer renders the error responses of the routes it applies to, e.g. when a
+// guard rejects a request or an interceptor fails.
+//
+// Routes use the ErrorRenderer provided in their module's scope, so a module providing
+// one overrides the renderer of its controllers and of the modules it imports, e.g. an
+// HTML error page module and a JSON API module in the same app. Routes render errors
+// as plain text if no ErrorRenderer is provided.
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Supply[godi.ErrorRenderer](godi.JSONErrorRenderer)}
+type ErrorRenderer interface {
+	RenderError(w http.ResponseWriter, r *http.Request, status int, err error)
+}
+
+// ErrorRendererFunc is a function implementing ErrorRenderer.
+type ErrorRendererFunc func(w http.ResponseWriter, r *http.Request, status int, err error)
+
+func (fn ErrorRendererFunc) RenderError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	fn(w, r, status, err)
+}
+
+var (
+	// TextErrorRenderer renders errors as their plain text status, e.g. "Forbidden".
+	TextErrorRenderer ErrorRenderer = ErrorRendererFunc(renderTextError)
+
+	// JSONErrorRenderer renders errors as {"status": 403, "error": "Forbidden"}, with the
+	// code, number, message and docsUrl of the ErrorCode of errors carrying one, and the
+	// failed rules of ValidationErrors as "errors".
+	JSONErrorRenderer ErrorRenderer = ErrorRendererFunc(renderJSONError)
+)
+
+// The error is not written to the response of the built-in renderers, as it may
+// reveal internal details, except for the user-facing message of its ErrorCode.
+func renderTextError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if ec, ok := errorCodeOf(err); ok {
+		http.Error(w, ec.Message, status)
+		return
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+func renderJSONError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	body := map[string]any{"status": status, "error": http.StatusText(status)}
+	if ec, ok := errorCodeOf(err); ok {
+		body["code"] = ec.Code
+		body["message"] = ec.Message
+		if ec.Number != 0 {
+			body["number"] = ec.Number
+		}
+		if ec.DocsURL != "" {
+			body["docsUrl"] = ec.DocsURL
+		}
+	}
+	var ve ValidationErrors
+	if errors.As(err, &ve) {
+		body["errors"] = ve
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// RenderError renders an error response with the ErrorRenderer of the route handling
+// the request, so handlers respond with errors consistent with the rest of their module.
+func RenderError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	renderer := ErrorRenderer(TextErrorRenderer)
+	if state := requestStateFrom(r.Context()); state != nil && state.renderer != nil {
+		renderer = state.renderer
+	}
+	renderer.RenderError(w, r, status, err)
+}
+
+// errorRendererInput is used for injecting the optional ErrorRenderer of a module scope.
+type errorRendererInput struct {
+	dig.In
+	Renderer ErrorRenderer `optional:"true"`
+}
+
+// resolveErrorRenderer returns the ErrorRenderer provided in the module's scope,
+// or the TextErrorRenderer if none is provided.
+func (m *module) resolveErrorRenderer() (ErrorRenderer, error) {
+	renderer := TextErrorRenderer
+
+	err := m.invoke(func(input errorRendererInput) {
+		if input.Renderer != nil {
+			renderer = input.Renderer
+		}
+	})
+	return renderer, err
+}
@@ -0,0 +1,99 @@
+package godi
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// EventDispatcher publishes the domain events recorded while handling a request,
+// e.g. on an in-process event bus or a message broker.
+type EventDispatcher interface {
+	DispatchEvents(ctx context.Context, events []any) error
+}
+
+// EventDispatcherFunc is a function implementing EventDispatcher.
+type EventDispatcherFunc func(ctx context.Context, events []any) error
+
+// DispatchEvents implements the EventDispatcher interface.
+func (fn EventDispatcherFunc) DispatchEvents(ctx context.Context, events []any) error {
+	return fn(ctx, events)
+}
+
+// RecordEvent records a domain event raised while handling the request of ctx, e.g.
+// by an entity or service, to be dispatched once the request completes successfully:
+//
+//	func (s *OrderService) Place(ctx context.Context, order *Order) error {
+//		...
+//		godi.RecordEvent(ctx, OrderPlaced{ID: order.ID})
+//		return nil
+//	}
+//
+// RecordEvent reports whether the event was recorded, which it is not
+// outside of a route's request.
+func RecordEvent(ctx context.Context, event any) bool {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return false
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.events = append(state.events, event)
+	return true
+}
+
+// TakeEvents removes and returns the domain events recorded for the request of ctx,
+// e.g. to dispatch them as soon as the transaction they were raised in commits,
+// or to discard them when it rolls back.
+func TakeEvents(ctx context.Context) []any {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return nil
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	events := state.events
+	state.events = nil
+	return events
+}
+
+// EventsDispatcher is an Interceptor dispatching the domain events recorded with
+// RecordEvent once the handlers it wraps complete successfully. The events of
+// requests responded to with an error status code are discarded, so events are
+// only published for changes that took effect.
+//
+// The events are dispatched after the response is written, and dispatch errors
+// are logged with the request's logger.
+type EventsDispatcher struct {
+	dispatcher EventDispatcher
+}
+
+// DispatchEvents returns an EventsDispatcher dispatching the events with d.
+func DispatchEvents(d EventDispatcher) *EventsDispatcher {
+	return &EventsDispatcher{dispatcher: d}
+}
+
+// Intercept implements the Interceptor interface.
+func (e *EventsDispatcher) Intercept(ictx InterceptorContext, next http.Handler) error {
+	var (
+		r = ictx.Http.R
+		w = NewStatusRecorder(ictx.Http.W)
+	)
+
+	next.ServeHTTP(w, r)
+
+	events := TakeEvents(r.Context())
+	if len(events) == 0 || w.Status() >= http.StatusBadRequest {
+		return nil
+	}
+
+	err := e.dispatcher.DispatchEvents(context.WithoutCancel(r.Context()), slices.Clip(events))
+	if err != nil {
+		LoggerFrom(r.Context()).Error("error dispatching domain events", "events", len(events), "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package godi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+)
+
+// File is content served by ServeFile.
+type File struct {
+	// Name is the file name, used to detect the content type from its extension
+	// and as the file name of downloads.
+	Name string
+
+	// Content is the file content. Its size is determined by seeking to its end.
+	Content io.ReadSeeker
+
+	// ModTime is the modification time used for Last-Modified and If-Modified-Since.
+	// The zero time disables them.
+	ModTime time.Time
+
+	// ETag is the entity tag of the content, e.g. a content hash. If empty, a tag is
+	// derived from the size and modification time when the latter is known.
+	ETag string
+
+	// Download makes browsers save the file instead of displaying it.
+	Download bool
+}
+
+// ServeFile responds with the file, handling Range, If-Range and conditional
+// requests, so large downloads like videos and exports can be resumed and
+// streamed partially.
+//
+// The content type is detected from the file name's extension, falling back to
+// sniffing the content, unless the Content-Type header is already set.
+func ServeFile(w http.ResponseWriter, r *http.Request, f File) {
+	if f.ETag == "" && !f.ModTime.IsZero() {
+		size, err := f.Content.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = f.Content.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			RenderError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		f.ETag = fmt.Sprintf(`"%x-%x"`, f.ModTime.UnixNano(), size)
+	}
+
+	if f.ETag != "" && w.Header().Get("ETag") == "" {
+		w.Header().Set("ETag", f.ETag)
+	}
+
+	if f.Download {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(f.Name)}))
+	}
+
+	http.ServeContent(w, r, f.Name, f.ModTime, f.Content)
+}
+
+// ServeFS responds with the named file of the file system as ServeFile does.
+//
+// Errors opening the file are returned so the handler can choose the response,
+// e.g. a 404 for errors matching fs.ErrNotExist. Directories are reported as not existing.
+func ServeFS(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, download bool) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		return fmt.Errorf("file (%s) does not support seeking: %w", name, errors.ErrUnsupported)
+	}
+
+	ServeFile(w, r, File{
+		Name:     info.Name(),
+		Content:  content,
+		ModTime:  info.ModTime(),
+		Download: download,
+	})
+	return nil
+}
@@ -0,0 +1,71 @@
+package godi
+
+import (
+	"fmt"
+	"slices"
+)
+
+// WithGuards registers guards applied to every route of the application, ahead of
+// the guards of its module, controller and route, e.g. to authenticate every request.
+func WithGuards(guards ...Guard) Option {
+	return func(o *options) {
+		o.guards = append(o.guards, guards...)
+	}
+}
+
+// WithGuardsCtors registers constructors of guards applied to every route of the
+// application, built once in the root module's scope after the guards set with WithGuards.
+func WithGuardsCtors(ctors ...GuardConstructor) Option {
+	return func(o *options) {
+		o.guardsCtors = append(o.guardsCtors, ctors...)
+	}
+}
+
+// WithInterceptors registers interceptors wrapping every route of the application,
+// outside of the interceptors of its module, controller and route, so cross-cutting
+// concerns like response envelopes apply uniformly:
+//
+//	app, err := godi.New(&app.Module{}, godi.WithInterceptors(godi.Envelope(nil)))
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithInterceptorsCtors registers constructors of interceptors wrapping every route of
+// the application, built once in the root module's scope after the interceptors set
+// with WithInterceptors.
+func WithInterceptorsCtors(ctors ...InterceptorConstructor) Option {
+	return func(o *options) {
+		o.interceptorsCtors = append(o.interceptorsCtors, ctors...)
+	}
+}
+
+// _registerGlobals builds the guards and interceptors applied to every route,
+// once every provider of the module tree is registered.
+func (a *App) _registerGlobals() error {
+	if i := slices.Index(a.opts.guards, nil); i >= 0 {
+		return fmt.Errorf("error registering global guards: guard at index %d is nil", i)
+	}
+	if i := slices.Index(a.opts.interceptors, nil); i >= 0 {
+		return fmt.Errorf("error registering global interceptors: interceptor at index %d is nil", i)
+	}
+
+	var err error
+
+	a.guards, err = buildGuards(a.module, a.opts.guards, a.opts.guardsCtors)
+	if err != nil {
+		return fmt.Errorf("error registering global guards: %w", err)
+	}
+
+	a.interceptors, err = buildInterceptors(a.module, a.opts.interceptors, a.opts.interceptorsCtors)
+	if err != nil {
+		return fmt.Errorf("error registering global interceptors: %w", err)
+	}
+
+	if a.opts.requestTimeout > 0 {
+		a.interceptors = slices.Insert(a.interceptors, 0, &interceptor{Interceptor: Timeout(a.opts.requestTimeout)})
+	}
+
+	return nil
+}
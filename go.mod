@@ -6,3 +6,8 @@ require (
 	github.com/joho/godotenv v1.5.1
 	go.uber.org/dig v1.18.0
 )
+
+require (
+	golang.org/x/crypto v0.41.0
+	golang.org/x/sys v0.35.0 // indirect
+)
@@ -1,7 +1,14 @@
 package godi
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	mrand "math/rand/v2"
 	"net/http"
+	"sync"
+	"time"
 
 	"go.uber.org/dig"
 )
@@ -9,28 +16,198 @@ import (
 // App represents the main application
 type App struct {
 	*HttpServer
+	opts      *options
 	module    *module
 	container *dig.Container
+
+	mu          sync.Mutex
+	report      StartupReport
+	instances   []Instance
+	providers   map[providerKey][]registration
+	provided    map[providerKey]bool
+	routes      map[string]*controller
+	routeNames  map[string]string
+	hostRouters map[string]*hostRouter
+	buildInfo   BuildInfo
+	errorCodes  map[string]*ErrorCode
+
+	guards       []*guard       // guards applied to every route.
+	interceptors []*interceptor // interceptors wrapping every route.
+	maintenance  Maintenance
+	shedder      *LoadShedder // sheds low-priority routes under overload, if enabled.
+
+	lazy   lazyModules
+	lazyMu sync.Mutex // serializes the initialization of lazy modules.
 }
 
 // New initializes a new instance of App, configuring the root module and dependencies.
-func New(module Module) (*App, error) {
-	c := dig.New()
-	s := newHttpServer(http.NewServeMux())
+//
+// The App itself can be injected into constructors, e.g. to inspect its module tree,
+// as can the BuildInfo of the running binary.
+//
+// Constructors may accept a context.Context to receive the startup context, which is
+// canceled once the application is created or the startup timeout elapses.
+//
+// Invalid configs and failing registrations are reported for the whole module tree
+// in a single joined error, rather than stopping at the first failing module.
+// Controllers are only built once every provider is registered successfully.
+func New(module Module, opts ...Option) (*App, error) {
+	if module == nil {
+		return nil, errors.New("root module is nil")
+	}
+
+	start := time.Now()
+	o := newOptions(opts)
+	app := &App{
+		opts:       o,
+		container:  dig.New(),
+		buildInfo:  ReadBuildInfo(),
+		HttpServer: newHttpServer(http.NewServeMux(), o),
+	}
+	if o.loadShedding != nil {
+		app.shedder = newLoadShedder(*o.loadShedding, o.logger)
+	}
+
+	err := app.validateModules(module)
+	if err != nil {
+		return nil, err
+	}
+
+	// record every type provided in the module tree
+	// so overridden fallback providers can be skipped
+	app.provided = map[providerKey]bool{}
+	app.collectProvided(module, map[string]bool{})
+
+	ctx, cancel := app.opts.startupContext()
+	defer cancel()
+
+	err = app.container.Provide(func() *HttpServer { return app.HttpServer })
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.container.Provide(func() *App { return app })
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.container.Provide(func() context.Context { return ctx })
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.container.Provide(func() Profile { return o.profile })
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.container.Provide(func() BuildInfo { return app.buildInfo })
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.container.Provide(func() IDGenerator { return o.ids })
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.container.Provide(func() mrand.Source { return sourceOrDefault(o.rand) })
+	if err != nil {
+		return nil, err
+	}
+
+	app.module, err = newModule(module, app.container.Scope(GetToken(module)), app, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.module.linkOptionalImports()
+	if err != nil {
+		return nil, err
+	}
 
-	err := c.Provide(func() *HttpServer { return s })
+	err = app._registerGlobals()
 	if err != nil {
 		return nil, err
 	}
 
-	m, err := newModule(module, c.Scope(GetToken(module)))
+	err = app.module.init()
 	if err != nil {
 		return nil, err
 	}
 
-	return &App{
-		module:     m,
-		container:  c,
-		HttpServer: s,
-	}, nil
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("error initializing app: %w", err)
+	}
+
+	err = app.registerEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	if app.hasPendingLazy() {
+		app.server.Handler = app.track(app.lazyHandler(app.mux))
+	}
+
+	app.report.Total = time.Since(start)
+	if app.opts.logStartup {
+		log.Print(app.StartupReport())
+	}
+
+	return app, nil
+}
+
+// registerEndpoints registers the endpoints enabled by the app's options.
+func (a *App) registerEndpoints() error {
+	type endpoint struct {
+		pattern string
+		handler http.HandlerFunc
+	}
+
+	var endpoints []endpoint
+
+	if a.opts.introspection {
+		endpoints = append(endpoints,
+			endpoint{"GET " + defaultIntrospectionPath, a.handleModuleTree},
+			endpoint{"GET " + defaultIntrospectionPath + "/schemas", a.handleSchemas},
+			endpoint{"GET " + defaultIntrospectionPath + "/errors", a.handleErrorCodes},
+		)
+	}
+
+	if a.opts.readinessPath != "" {
+		endpoints = append(endpoints, endpoint{"GET " + a.opts.readinessPath, a.handleReadiness})
+	}
+
+	if a.opts.versionPath != "" {
+		endpoints = append(endpoints, endpoint{"GET " + a.opts.versionPath, a.handleVersion})
+	}
+
+	for _, e := range endpoints {
+		err := handle(a.mux, e.pattern, e.handler)
+		if err != nil {
+			return fmt.Errorf("error registering endpoint (%s): %w", e.pattern, err)
+		}
+	}
+	return nil
+}
+
+// registerRoute records the pattern a controller registers a route handler for,
+// reporting an error if another route already uses the same pattern.
+func (a *App) registerRoute(pattern string, c *controller) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.routes == nil {
+		a.routes = map[string]*controller{}
+	}
+
+	if existing, ok := a.routes[pattern]; ok {
+		return fmt.Errorf(
+			"conflicting route pattern (%s): registered by controller (%T) in module (%T) and controller (%T) in module (%T)",
+			pattern, existing.Controller, existing.module.Module, c.Controller, c.module.Module,
+		)
+	}
+
+	a.routes[pattern] = c
+	return nil
 }
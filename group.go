@@ -19,13 +19,6 @@ const (
 	groupInterceptors group = "interceptors"
 )
 
-// guardGroupInput is used for injecting the collection of Guard instances
-// grouped under `groupGuards` in a particular.
-type guardGroupInput struct {
-	dig.In
-	Guards []Guard `group:"guards"`
-}
-
 // controllerGroupInput is used for injecting the collection of Controller instances
 // grouped under `groupControllers` in a particular.
 type controllerGroupInput struct {
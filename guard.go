@@ -1,6 +1,11 @@
 package godi
 
-import "net/http"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 // Guard is an interface that determines whether a request should be handled by
 // a route handler or rejected based on specific criteria or metadata present at runtime.
@@ -22,6 +27,15 @@ type GuardContext struct {
 
 	// ControllerCfg contains metadata and configuration for the controller.
 	ControllerCfg ControllerConfig
+
+	// req holds the request passed to later guards and the handler,
+	// which guards replace to enrich its context.
+	req *guardRequest
+}
+
+// guardRequest holds the request shared by the guards of a route.
+type guardRequest struct {
+	r *http.Request
 }
 
 // GuardContextHttp holds HTTP request and response information for GuardContext.
@@ -30,23 +44,23 @@ type GuardContextHttp struct {
 	W http.ResponseWriter
 }
 
-func newGuardCtx(c controller, r route, w http.ResponseWriter, req *http.Request) GuardContext {
-	return GuardContext{
-		RouteCfg:      *r.RouteConfig,
-		ControllerCfg: *c.Config(),
-		Http: GuardContextHttp{
-			R: req,
-			W: w,
-		},
-	}
+// newGuardCtx returns the guard context of a request to the route.
+func (r *route) newGuardCtx(w http.ResponseWriter, state *requestState) GuardContext {
+	gCtx := r.guardCtx
+	gCtx.Http = GuardContextHttp{R: state.guards.r, W: w}
+	gCtx.req = &state.guards
+	return gCtx
 }
 
 // GuardConstructor is a function that takes any number of dependencies
-// as its parameters and returns an arbitrary number of values that meets the `Guard` interface
+// as its parameters and returns a value that meets the `Guard` interface
 // and may optionally return an error to indicate that it failed to build the value.
 //
 // Any arguments that the constructor has are treated as its dependencies. The dependencies are instantiated
 // in an unspecified order along with any dependencies that they might have.
+//
+// Each constructor is invoked once for the controller or route that lists it, so a route's
+// guards only apply to that route, and controller guards apply to each of its routes once.
 type GuardConstructor constructor
 
 // guard is a wrapper for managing an instance of a Guard.
@@ -59,3 +73,96 @@ func newGuard(g Guard) (*guard, error) {
 		Guard: g,
 	}, nil
 }
+
+// buildGuards wraps the given guards and builds the given constructors
+// in the module scope, in the order they are declared.
+func buildGuards(m *module, guards []Guard, ctors []GuardConstructor) ([]*guard, error) {
+	var built []*guard
+
+	for _, grd := range guards {
+		g, err := newGuard(grd)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, g)
+	}
+
+	for _, ctor := range ctors {
+		value, err := m.build(ctor)
+		if err != nil {
+			return nil, fmt.Errorf("error building guard (%s): %w", funcName(ctor), err)
+		}
+
+		grd, ok := value.(Guard)
+		if !ok {
+			return nil, fmt.Errorf("constructor (%s) does not return a Guard", funcName(ctor))
+		}
+
+		g, err := newGuard(grd)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, g)
+	}
+
+	return built, nil
+}
+
+// Context returns the request's context.
+func (g GuardContext) Context() context.Context {
+	return g.Http.R.Context()
+}
+
+// SetContext replaces the request's context, e.g. with one carrying a tenant or a
+// request-scoped logger derived from Context. Later guards, interceptors and the
+// handler receive the request with the new context.
+func (g GuardContext) SetContext(ctx context.Context) {
+	if g.req != nil {
+		g.req.r = g.req.r.WithContext(ctx)
+	}
+}
+
+// Header returns the first value of the named request header.
+func (g GuardContext) Header(name string) string {
+	return g.Http.R.Header.Get(name)
+}
+
+// Query returns the first value of the named query parameter.
+func (g GuardContext) Query(name string) string {
+	return g.Http.R.URL.Query().Get(name)
+}
+
+// PathParam returns the value of the named wildcard in the route pattern,
+// e.g. "id" for the pattern "/users/{id}".
+func (g GuardContext) PathParam(name string) string {
+	return g.Http.R.PathValue(name)
+}
+
+// BearerToken returns the token of a bearer Authorization header, or an empty string
+// if the request has no such header.
+func (g GuardContext) BearerToken() string {
+	scheme, token, ok := strings.Cut(g.Header("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// ClientIP returns the IP address of the client that sent the request, as returned by
+// the package-level ClientIP.
+func (g GuardContext) ClientIP() string {
+	return ClientIP(g.Http.R)
+}
+
+// Principal returns the principal attached to the request by an earlier guard,
+// or nil if none has been attached.
+func (g GuardContext) Principal() any {
+	return principalFrom(g.Http.R.Context())
+}
+
+// SetPrincipal attaches the authenticated principal, e.g. the user or token claims,
+// to the request so that later guards, interceptors and the handler can retrieve it
+// with PrincipalFrom. Attaching a principal again replaces the previous one.
+func (g GuardContext) SetPrincipal(principal any) {
+	setPrincipal(g.Http.R.Context(), principal)
+}
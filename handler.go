@@ -0,0 +1,114 @@
+package godi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// TypedHandler is implemented by handlers that declare the types of their request
+// and response bodies, which App.Schemas exports as JSON Schemas.
+// A nil type means the handler has no body in that direction.
+type TypedHandler interface {
+	http.Handler
+	RequestType() reflect.Type
+	ResponseType() reflect.Type
+}
+
+// HandleJSON returns a handler decoding JSON request bodies into Req and encoding
+// the Resp returned by fn as JSON. Handlers that take no request body use struct{}
+// as Req.
+//
+// Bodies are decoded and encoded with the BodyCodec set with WithBodyCodecs of the
+// request's Content-Type and the media type its Accept header prefers, if any, e.g.
+// so Req and Resp being protocol buffer messages are also served as protobuf.
+//
+// Malformed request bodies are rejected with 400, and bodies exceeding the maximum size
+// set with WithMaxBodySize with 413 and ErrBodyTooLarge. Request bodies implementing Validator
+// are validated before fn is called, and rejected with 422 if invalid, with the messages
+// of their ValidationErrors translated by TranslateValidation. Errors returned by fn are
+// responded to with the status of their StatusError or ErrorCode, or 500, both
+// rendered by the route's ErrorRenderer.
+//
+//	Handler: godi.HandleJSON(func(r *http.Request, req CreateUser) (*User, error) {
+//		return c.users.Create(r.Context(), req)
+//	})
+func HandleJSON[Req, Resp any](fn func(r *http.Request, req Req) (Resp, error)) TypedHandler {
+	return &jsonHandler[Req, Resp]{fn: fn}
+}
+
+// jsonHandler is the TypedHandler returned by HandleJSON.
+type jsonHandler[Req, Resp any] struct {
+	fn func(r *http.Request, req Req) (Resp, error)
+}
+
+func (h *jsonHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Req
+
+	if h.RequestType() != nil && hasBody(r) {
+		limitBody(w, r)
+
+		var err error
+		if codec := requestCodec(r); codec != nil {
+			err = decodeBody(codec, r.Body, &req)
+		} else {
+			err = json.NewDecoder(r.Body).Decode(&req)
+		}
+		if err != nil {
+			status, err := bodyErrorStatus(err)
+			RenderError(w, r, status, err)
+			return
+		}
+	}
+
+	err := validateRequest(&req)
+	if err != nil {
+		var errs ValidationErrors
+		if errors.As(err, &errs) {
+			err = TranslateValidation(r, errs)
+		} else if !errors.Is(err, ErrValidation) {
+			err = fmt.Errorf("%w: %w", ErrValidation, err)
+		}
+		RenderError(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	resp, err := h.fn(r, req)
+	if err != nil {
+		RenderError(w, r, errorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	if codec := responseCodec(r); codec != nil {
+		data, err := codec.Marshal(resp)
+		if err != nil {
+			RenderError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", codec.MediaType())
+		_, _ = w.Write(data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *jsonHandler[Req, Resp]) RequestType() reflect.Type {
+	return bodyType[Req]()
+}
+
+func (h *jsonHandler[Req, Resp]) ResponseType() reflect.Type {
+	return bodyType[Resp]()
+}
+
+// bodyType returns the type of T, or nil for struct{} which stands for no body.
+func bodyType[T any]() reflect.Type {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Struct && t.NumField() == 0 {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,160 @@
+package godi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// hostRouter dispatches the requests matching a ServeMux pattern to the routes
+// whose host template, e.g. "{tenant}.example.com", matches the request's host.
+//
+// ServeMux does not support wildcards in hosts, so routes with host templates are
+// registered under their method and path, and share a hostRouter.
+//
+// Routes may be added while requests are served, e.g. by lazy modules.
+type hostRouter struct {
+	mu     sync.RWMutex
+	routes []hostRoute
+}
+
+// hostRoute is a route handler with the labels of its host template.
+type hostRoute struct {
+	labels  []string
+	handler http.Handler
+}
+
+func (h *hostRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		host = req.Host
+	}
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(host, ".")), ".")
+
+	h.mu.RLock()
+	routes := h.routes
+	h.mu.RUnlock()
+
+	for _, route := range routes {
+		values, ok := matchHost(route.labels, labels)
+		if !ok {
+			continue
+		}
+
+		for name, value := range values {
+			req.SetPathValue(name, value)
+		}
+		route.handler.ServeHTTP(w, req)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// add adds the route to the router.
+func (h *hostRouter) add(route hostRoute) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// routes is copied, so the slices read by requests being served are never written
+	h.routes = append(slices.Clip(h.routes), route)
+}
+
+// handleRoute registers the route handler for the method, host and path.
+//
+// Hosts with wildcards are matched by a hostRouter, which exposes the captured
+// labels as path values, e.g. r.PathValue("tenant").
+func (a *App) handleRoute(method, host, path string, handler http.Handler) error {
+	if !strings.Contains(host, "{") {
+		return handle(a.mux, strings.TrimSpace(method+" "+host+path), handler)
+	}
+
+	labels, err := parseHostTemplate(host)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pattern := strings.TrimSpace(method + " " + path)
+	router, ok := a.hostRouters[pattern]
+	if !ok {
+		router = &hostRouter{}
+
+		err = handle(a.mux, pattern, router)
+		if err != nil {
+			return err
+		}
+
+		if a.hostRouters == nil {
+			a.hostRouters = map[string]*hostRouter{}
+		}
+		a.hostRouters[pattern] = router
+	}
+
+	router.add(hostRoute{labels: labels, handler: handler})
+	return nil
+}
+
+// parseHostTemplate returns the lowercased labels of a host template, where
+// wildcard labels like "{tenant}" match a single label of the request's host.
+func parseHostTemplate(host string) ([]string, error) {
+	labels := strings.Split(strings.ToLower(host), ".")
+
+	for _, label := range labels {
+		name, isWildcard := wildcardName(label)
+		switch {
+		case label == "":
+			return nil, fmt.Errorf("host %q has an empty label", host)
+		case isWildcard && !isIdentifier(name):
+			return nil, fmt.Errorf("host %q has an invalid wildcard %q", host, label)
+		case !isWildcard && strings.ContainsAny(label, "{}"):
+			return nil, fmt.Errorf("host %q has a wildcard that is not a whole label", host)
+		}
+	}
+
+	return labels, nil
+}
+
+// matchHost matches the labels of a host against the labels of a host template,
+// returning the values of its wildcards.
+func matchHost(template, labels []string) (map[string]string, bool) {
+	if len(template) != len(labels) {
+		return nil, false
+	}
+
+	values := map[string]string{}
+	for i, label := range template {
+		if name, ok := wildcardName(label); ok {
+			values[name] = labels[i]
+			continue
+		}
+		if label != labels[i] {
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// wildcardName returns the name of a wildcard label like "{tenant}".
+func wildcardName(label string) (string, bool) {
+	if len(label) > 2 && label[0] == '{' && label[len(label)-1] == '}' {
+		return label[1 : len(label)-1], true
+	}
+	return "", false
+}
+
+// isIdentifier reports whether s is a valid wildcard name.
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		isLetter := c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+		if !isLetter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,157 @@
+package godi
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	mrand "math/rand/v2"
+	"sync"
+	"time"
+)
+
+// IDGenerator generates unique IDs, such as the IDs of requests.
+//
+// The application's generator is provided to every module, so IDs generated by
+// modules can be made reproducible in tests with [godi.WithIDGenerator].
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc adapts a function to the IDGenerator interface.
+type IDGeneratorFunc func() string
+
+// NewID implements the IDGenerator interface.
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// UUIDGenerator generates random (version 4) UUIDs, e.g. "f47ac10b-58cc-4372-a567-0e02b2c3d479".
+type UUIDGenerator struct {
+	// Source is the source of randomness of the UUIDs. Defaults to crypto/rand.
+	// It must be safe for concurrent use, like the sources returned by LockedSource.
+	Source mrand.Source
+}
+
+// NewID implements the IDGenerator interface.
+func (g UUIDGenerator) NewID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], sourceOrDefault(g.Source).Uint64())
+	binary.BigEndian.PutUint64(b[8:], sourceOrDefault(g.Source).Uint64())
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	hex.Encode(s[9:13], b[4:6])
+	hex.Encode(s[14:18], b[6:8])
+	hex.Encode(s[19:23], b[8:10])
+	hex.Encode(s[24:], b[10:])
+	s[8], s[13], s[18], s[23] = '-', '-', '-', '-'
+
+	return string(s[:])
+}
+
+// crockford is the Crockford base32 alphabet ULIDs are encoded with.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates ULIDs, e.g. "01ARZ3NDEKTSV4RRFFQ69G5FAV", which sort
+// by their creation time.
+type ULIDGenerator struct {
+	// Source is the source of randomness of the ULIDs. Defaults to crypto/rand.
+	// It must be safe for concurrent use, like the sources returned by LockedSource.
+	Source mrand.Source
+
+	// Now returns the time the ULIDs are created at. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewID implements the IDGenerator interface.
+func (g ULIDGenerator) NewID() string {
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+
+	var (
+		ms   = uint64(now().UnixMilli())
+		hi   = sourceOrDefault(g.Source).Uint64() & 0xffff // 16 bits
+		lo   = sourceOrDefault(g.Source).Uint64()          // 64 bits
+		s    [26]byte
+		bits = [3]uint64{ms, hi, lo}
+	)
+
+	// 48 bits of timestamp in 10 characters, then 80 bits of randomness in 16 characters
+	for i := 9; i >= 0; i-- {
+		s[i] = crockford[bits[0]&0x1f]
+		bits[0] >>= 5
+	}
+	for i := 25; i >= 10; i-- {
+		s[i] = crockford[bits[2]&0x1f]
+		bits[2] = (bits[2] >> 5) | ((bits[1] & 0x1f) << 59)
+		bits[1] >>= 5
+	}
+
+	return string(s[:])
+}
+
+// LockedSource returns a source of randomness safe for concurrent use backed
+// by src, e.g. to share a seeded source between requests:
+//
+//	godi.WithIDGenerator(godi.ULIDGenerator{Source: godi.LockedSource(rand.NewPCG(1, 2))})
+func LockedSource(src mrand.Source) mrand.Source {
+	if _, ok := src.(*lockedSource); ok {
+		return src
+	}
+	return &lockedSource{src: src}
+}
+
+type lockedSource struct {
+	mu  sync.Mutex
+	src mrand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+// cryptoSource is a source of randomness reading from crypto/rand.
+type cryptoSource struct{}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func sourceOrDefault(src mrand.Source) mrand.Source {
+	if src == nil {
+		return cryptoSource{}
+	}
+	return src
+}
+
+// WithIDGenerator sets the generator of the IDs of requests not carrying one in
+// their RequestIDHeader, which is also provided to every module. Defaults to a
+// UUIDGenerator using the source set with WithRandSource.
+func WithIDGenerator(g IDGenerator) Option {
+	return func(o *options) {
+		o.ids = g
+	}
+}
+
+// WithRandSource sets the source of randomness provided to every module as a
+// math/rand/v2 Source, e.g. a seeded source making random values reproducible
+// in tests. The source is wrapped with LockedSource, and defaults to crypto/rand.
+//
+//	app, err := godi.New(&app.Module{}, godi.WithRandSource(rand.NewPCG(1, 2)))
+func WithRandSource(src mrand.Source) Option {
+	return func(o *options) {
+		o.rand = nil
+		if src != nil {
+			o.rand = LockedSource(src)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package godi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// injectTag is the struct tag used to mark fields for injection.
+const injectTag = "godi"
+
+// Struct returns a ProviderConstructor that builds a *T whose exported fields
+// tagged `godi:"inject"` are populated from the container, for types that prefer
+// field injection over long constructor parameter lists.
+//
+// The tag accepts the options "optional", to leave the field zero-valued when no
+// provider exists, "name=<name>", to inject a named value, and "group=<name>", to
+// inject every value contributed to a group into a slice field.
+//
+// Example:
+//
+//	type UserService struct {
+//		DB     *sql.DB      `godi:"inject"`
+//		Cache  *Cache       `godi:"inject,optional"`
+//		Logger *slog.Logger `godi:"inject,name=audit"`
+//	}
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Struct[UserService]()}
+func Struct[T any]() ProviderConstructor {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return func() (*T, error) {
+			return nil, fmt.Errorf("cannot inject fields of %v: not a struct", t)
+		}
+	}
+
+	var (
+		indexes []int
+		fields  = []reflect.StructField{{Name: "In", Type: inType, Anonymous: true}}
+	)
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, err := parseInjectTag(f)
+		if err != nil {
+			return func() (*T, error) {
+				return nil, fmt.Errorf("cannot inject field %v.%s: %w", t, f.Name, err)
+			}
+		}
+		if tag == nil {
+			continue
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Field%d", len(indexes)),
+			Type: f.Type,
+			Tag:  *tag,
+		})
+		indexes = append(indexes, i)
+	}
+
+	fnType := reflect.FuncOf(
+		[]reflect.Type{reflect.StructOf(fields)},
+		[]reflect.Type{reflect.PointerTo(t)},
+		false,
+	)
+
+	return reflect.MakeFunc(
+		fnType,
+		func(args []reflect.Value) []reflect.Value {
+			v := reflect.New(t)
+			for j, i := range indexes {
+				v.Elem().Field(i).Set(args[0].Field(j + 1))
+			}
+			return []reflect.Value{v}
+		},
+	).Interface()
+}
+
+// parseInjectTag converts a field's `godi` tag into the equivalent dig parameter tag.
+// It returns nil if the field is not marked for injection.
+func parseInjectTag(f reflect.StructField) (*reflect.StructTag, error) {
+	value, ok := f.Tag.Lookup(injectTag)
+	if !ok {
+		return nil, nil
+	}
+
+	opts := strings.Split(value, ",")
+	if opts[0] != "inject" {
+		return nil, fmt.Errorf("unknown tag value %q", opts[0])
+	}
+	if !f.IsExported() {
+		return nil, fmt.Errorf("field is unexported")
+	}
+
+	var tags []string
+	for _, opt := range opts[1:] {
+		name, isName := strings.CutPrefix(opt, "name=")
+		group, isGroup := strings.CutPrefix(opt, "group=")
+
+		switch {
+		case opt == "optional":
+			tags = append(tags, `optional:"true"`)
+		case isName:
+			tags = append(tags, fmt.Sprintf("name:%q", name))
+		case isGroup:
+			tags = append(tags, fmt.Sprintf("group:%q", group))
+		default:
+			return nil, fmt.Errorf("unknown tag option %q", opt)
+		}
+	}
+
+	tag := reflect.StructTag(strings.Join(tags, " "))
+	return &tag, nil
+}
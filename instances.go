@@ -0,0 +1,91 @@
+package godi
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Instance describes a value built by the container, to help find providers
+// that are instantiated eagerly or more than once.
+type Instance struct {
+	// Types lists the types of the values the constructor produced.
+	Types []reflect.Type
+
+	// Constructor is the constructor's function name in the format <package>.<function>.
+	Constructor string
+
+	// Module is the token of the module the constructor was registered in.
+	Module string
+
+	// Created is the time the constructor returned.
+	Created time.Time
+
+	// Duration is the time the constructor took to run.
+	Duration time.Duration
+}
+
+// String formats the instance as its types followed by its module and creation time.
+func (i Instance) String() string {
+	return fmt.Sprintf("%v (%s, %s) created at %s in %s", i.Types, i.Constructor, i.Module, i.Created.Format(time.RFC3339Nano), i.Duration)
+}
+
+// Instances returns the values built by the container so far, in the order they were created.
+//
+// Providers are built when a constructor or controller first depends on them, so providers
+// missing from the list were never used, while types listed more than once were built by
+// several modules.
+func (a *App) Instances() []Instance {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.instances)
+}
+
+// recordInstance records the values built by a constructor that succeeded.
+func (a *App) recordInstance(m *module, ctor constructor, name string, d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.instances = append(a.instances, Instance{
+		Types:       resultTypes(ctor),
+		Constructor: name,
+		Module:      GetToken(m.Module),
+		Created:     time.Now(),
+		Duration:    d,
+	})
+}
+
+// resultTypes returns the types of the values a constructor produces,
+// including the fields of its result structs.
+func resultTypes(ctor constructor) []reflect.Type {
+	if a, ok := ctor.(annotated); ok {
+		return resultTypes(a.ctor)
+	}
+
+	t := reflect.TypeOf(ctor)
+	if t == nil || t.Kind() != reflect.Func {
+		return nil
+	}
+
+	var types []reflect.Type
+	for i := range t.NumOut() {
+		out := t.Out(i)
+		switch {
+		case out == errorType:
+			continue
+		case isOutStruct(out):
+			for j := range out.NumField() {
+				f := out.Field(j)
+				if (f.Anonymous && f.Type == outType) || (!f.IsExported()) {
+					continue
+				}
+				types = append(types, f.Type)
+			}
+		default:
+			types = append(types, out)
+		}
+	}
+
+	return types
+}
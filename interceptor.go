@@ -0,0 +1,122 @@
+package godi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Interceptor is an interface that wraps the execution of a route handler,
+// allowing requests and responses to be transformed before and after the handler runs.
+//
+// An interceptor invokes the next handler to continue the pipeline, usually with a
+// wrapped response writer when the response needs to be transformed.
+type Interceptor interface {
+	Intercept(ictx InterceptorContext, next http.Handler) error
+}
+
+// InterceptorContext provides the contextual information that an interceptor needs
+// to transform a request or its response.
+type InterceptorContext struct {
+	// Http contains the request and response information.
+	Http InterceptorContextHttp
+
+	// RouteCfg contains metadata and configuration specific to the route.
+	RouteCfg RouteConfig
+
+	// ControllerCfg contains metadata and configuration for the controller.
+	ControllerCfg ControllerConfig
+}
+
+// InterceptorContextHttp holds HTTP request and response information for InterceptorContext.
+type InterceptorContextHttp struct {
+	R *http.Request
+	W http.ResponseWriter
+}
+
+// Principal returns the principal attached to the request by a guard,
+// or nil if none has been attached.
+func (i InterceptorContext) Principal() any {
+	return principalFrom(i.Http.R.Context())
+}
+
+// InterceptorConstructor is a function that takes any number of dependencies
+// as its parameters and returns a value that meets the `Interceptor` interface
+// and may optionally return an error to indicate that it failed to build the value.
+//
+// Each constructor is invoked once for the controller or route that lists it,
+// so interceptors are never shared between routes.
+type InterceptorConstructor constructor
+
+// interceptor is a wrapper for managing an instance of an Interceptor.
+type interceptor struct {
+	Interceptor
+}
+
+func newInterceptor(i Interceptor) (*interceptor, error) {
+	return &interceptor{
+		Interceptor: i,
+	}, nil
+}
+
+// buildInterceptors wraps the given interceptors and builds the given constructors
+// in the module scope, in the order they are declared.
+func buildInterceptors(m *module, interceptors []Interceptor, ctors []InterceptorConstructor) ([]*interceptor, error) {
+	var built []*interceptor
+
+	for _, ic := range interceptors {
+		i, err := newInterceptor(ic)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, i)
+	}
+
+	for _, ctor := range ctors {
+		value, err := m.build(ctor)
+		if err != nil {
+			return nil, fmt.Errorf("error building interceptor (%s): %w", funcName(ctor), err)
+		}
+
+		ic, ok := value.(Interceptor)
+		if !ok {
+			return nil, fmt.Errorf("constructor (%s) does not return an Interceptor", funcName(ctor))
+		}
+
+		i, err := newInterceptor(ic)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, i)
+	}
+
+	return built, nil
+}
+
+// chainInterceptors wraps handler with the interceptors so that the first
+// interceptor is the outermost one and the handler runs last.
+func chainInterceptors(c *controller, r *route, interceptors []*interceptor, handler http.Handler) http.Handler {
+	for _, ic := range slices.Backward(interceptors) {
+		next := handler
+		handler = http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				iCtx := r.interceptorCtx
+				iCtx.Http = InterceptorContextHttp{R: req, W: w}
+
+				start := time.Now()
+				err := ic.Intercept(iCtx, next)
+				elapsed := time.Since(start)
+
+				c.module.app.observeLayer(req, LayerInterceptor, ic.Interceptor, elapsed)
+				c.module.app.debugRequest(req, "interceptor %T: err=%v in %s including next handlers", ic.Interceptor, err, elapsed)
+
+				if err != nil && !errors.Is(err, ErrResponded) {
+					c.errorRenderer.RenderError(w, req, errorStatus(err, http.StatusInternalServerError), err)
+				}
+			},
+		)
+	}
+	return handler
+}
@@ -0,0 +1,120 @@
+package godi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// lazyModules holds the lazy modules whose controllers are built on first use.
+type lazyModules struct {
+	pending []*lazyModule
+}
+
+// lazyModule is a lazy module waiting to be initialized, or whose initialization failed.
+type lazyModule struct {
+	module *module
+	paths  []string // the path prefixes of the module's routes.
+	err    error    // the error of the module's failed initialization, if any.
+}
+
+// matches reports whether the path is under one of the module's path prefixes.
+func (l *lazyModule) matches(path string) bool {
+	return slices.ContainsFunc(l.paths, func(prefix string) bool {
+		prefix = strings.TrimSuffix(prefix, "/")
+		return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	})
+}
+
+// deferInit defers the initialization of the lazy module until a request
+// under one of its LazyPaths is received.
+func (a *App) deferInit(m *module) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.lazy.pending = append(a.lazy.pending, &lazyModule{module: m, paths: m.Config().LazyPaths})
+	a.debugf("defer module %s: lazy", m.path())
+}
+
+// initLazy initializes the lazy module, reporting the error of its first
+// initialization on later calls.
+//
+// Its constructors receive a new startup context, bounded by the startup timeout,
+// as the one of the application's creation is canceled once New returns.
+func (a *App) initLazy(l *lazyModule) error {
+	a.lazyMu.Lock()
+	defer a.lazyMu.Unlock()
+
+	if l.err != nil || !a.isPendingLazy(l) {
+		return l.err
+	}
+
+	ctx, cancel := a.opts.startupContext()
+	defer cancel()
+
+	err := l.module.scope.Decorate(func(context.Context) context.Context { return ctx })
+	if err == nil {
+		err = l.module.initNow()
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		l.err = fmt.Errorf("error initializing lazy module (%T): %w", l.module.Module, err)
+		log.Print(a.opts.redactor.RedactString(l.err.Error()))
+		return l.err
+	}
+
+	a.mu.Lock()
+	a.lazy.pending = slices.DeleteFunc(a.lazy.pending, func(p *lazyModule) bool { return p == l })
+	a.mu.Unlock()
+	return nil
+}
+
+// isPendingLazy reports whether the lazy module is waiting to be initialized.
+func (a *App) isPendingLazy(l *lazyModule) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Contains(a.lazy.pending, l)
+}
+
+// hasPendingLazy reports whether lazy modules are waiting to be initialized.
+func (a *App) hasPendingLazy() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.lazy.pending) > 0
+}
+
+// pendingLazyFor returns the lazy modules waiting to be initialized, or that
+// failed to, whose path prefixes the path is under.
+func (a *App) pendingLazyFor(path string) []*lazyModule {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var matched []*lazyModule
+	for _, l := range a.lazy.pending {
+		if l.matches(path) {
+			matched = append(matched, l)
+		}
+	}
+	return matched
+}
+
+// lazyHandler wraps the mux to initialize the pending lazy modules whose path prefixes
+// a request is under, and dispatch it once their routes are registered.
+func (a *App) lazyHandler(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			for _, l := range a.pendingLazyFor(r.URL.Path) {
+				if err := a.initLazy(l); err != nil {
+					RenderError(w, r, http.StatusInternalServerError, err)
+					return
+				}
+			}
+			mux.ServeHTTP(w, r)
+		},
+	)
+}
@@ -0,0 +1,60 @@
+package godi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+)
+
+// StartHook is a function run once the server's listener is bound, before requests
+// are served. It receives the address the server listens on.
+type StartHook func(ctx context.Context, addr net.Addr) error
+
+// OnStart registers a hook to run when the server starts listening, e.g. to register
+// the application with service discovery. Hooks run in the order they were registered,
+// and Listen fails without serving requests if a hook fails.
+func (s *HttpServer) OnStart(hook StartHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.startHooks = append(s.startHooks, hook)
+}
+
+// runStartHooks runs the start hooks, stopping at the first failing hook.
+func (s *HttpServer) runStartHooks(ctx context.Context, addr net.Addr) error {
+	s.mu.Lock()
+	hooks := slices.Clone(s.startHooks)
+	s.mu.Unlock()
+
+	for _, hook := range hooks {
+		err := hook(ctx, addr)
+		if err != nil {
+			return fmt.Errorf("error running start hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// Starter is implemented by modules that run code once the server starts listening.
+// Its Start method is registered as a start hook when the module is initialized.
+type Starter interface {
+	Start(ctx context.Context, addr net.Addr) error
+}
+
+// Stopper is implemented by modules that run code as soon as the server shuts down.
+// Its Stop method is registered as a pre-drain shutdown hook when the module is initialized.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// _registerLifecycle registers the module's start and stop hooks.
+func (m *module) _registerLifecycle() error {
+	if starter, ok := m.Module.(Starter); ok {
+		m.app.OnStart(starter.Start)
+	}
+	if stopper, ok := m.Module.(Stopper); ok {
+		m.app.OnShutdown(stopper.Stop, PreDrain)
+	}
+	return nil
+}
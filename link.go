@@ -0,0 +1,177 @@
+package godi
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Link is a web link sent in a Link header, as defined by RFC 8288.
+type Link struct {
+	// URL is the target of the link.
+	URL string
+
+	// Rel is the relation type of the link, e.g. "next", "prev" or "preload".
+	Rel string
+
+	// Params are the link's extra target attributes, e.g. "as" and "type" for preloads.
+	Params map[string]string
+}
+
+// Preload returns a link asking the client to preload the resource,
+// e.g. Preload("/static/app.css", "style").
+func Preload(target, as string) Link {
+	return Link{URL: target, Rel: "preload", Params: map[string]string{"as": as}}
+}
+
+// String formats the link as a Link header value, quoting its relation type and
+// parameters as quoted strings.
+func (l Link) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%s>", l.URL)
+	if l.Rel != "" {
+		fmt.Fprintf(&b, "; rel=\"%s\"", quoteEscaper.Replace(l.Rel))
+	}
+	for _, k := range slices.Sorted(maps.Keys(l.Params)) {
+		fmt.Fprintf(&b, "; %s=\"%s\"", k, quoteEscaper.Replace(l.Params[k]))
+	}
+	return b.String()
+}
+
+// quoteEscaper escapes the characters of quoted strings that must be escaped.
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// Validate reports an error if the link cannot be sent in a Link header: if its
+// URL contains a '>', or it contains control characters, which would let
+// its values inject other links or headers.
+func (l Link) Validate() error {
+	if strings.ContainsRune(l.URL, '>') || hasControlChars(l.URL) {
+		return fmt.Errorf("invalid link URL (%q)", l.URL)
+	}
+	if hasControlChars(l.Rel) {
+		return fmt.Errorf("invalid link relation type (%q)", l.Rel)
+	}
+	for k, v := range l.Params {
+		if k == "" || strings.ContainsAny(k, " ;,=\"") || hasControlChars(k) || hasControlChars(v) {
+			return fmt.Errorf("invalid link parameter (%q=%q)", k, v)
+		}
+	}
+	return nil
+}
+
+// hasControlChars reports whether s contains control characters other than tabs.
+func hasControlChars(s string) bool {
+	return strings.ContainsFunc(s, func(r rune) bool {
+		return (r < ' ' && r != '\t') || r == 0x7f
+	})
+}
+
+// AddLinks adds the links to the response's Link header. Links that are not
+// valid, as reported by Link.Validate, are omitted.
+func AddLinks(w http.ResponseWriter, links ...Link) {
+	for _, l := range links {
+		if l.Validate() != nil {
+			continue
+		}
+		w.Header().Add("Link", l.String())
+	}
+}
+
+// EarlyHints sends a 103 Early Hints informational response with the links, so
+// clients can start fetching resources, like stylesheets, while the handler is
+// still preparing the final response. The links are also kept for the final response.
+func EarlyHints(w http.ResponseWriter, links ...Link) {
+	AddLinks(w, links...)
+	w.WriteHeader(http.StatusEarlyHints)
+}
+
+// QueryLink returns a link to the requested URL with the given query parameters
+// replaced, e.g. for rel="next" and rel="prev" pagination links.
+func QueryLink(r *http.Request, rel string, set url.Values) Link {
+	u := *r.URL
+	query := u.Query()
+	for k, v := range set {
+		query[k] = v
+	}
+	u.RawQuery = query.Encode()
+
+	return Link{URL: u.RequestURI(), Rel: rel}
+}
+
+// URL builds the path of the route registered with the given name, substituting its
+// wildcards with the given key-value pairs, e.g.
+//
+//	app.URL("user", "id", "42") // "/users/42" for the route pattern "/users/{id}"
+func (a *App) URL(name string, params ...string) (string, error) {
+	a.mu.Lock()
+	pattern, ok := a.routeNames[name]
+	a.mu.Unlock()
+
+	if !ok {
+		return "", fmt.Errorf("no route named %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("odd number of parameters for route %q", name)
+	}
+
+	values := map[string]string{}
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+
+		wildcard := strings.Trim(segment, "{}")
+		if wildcard == "$" {
+			segments[i] = ""
+			continue
+		}
+
+		key, multi := strings.CutSuffix(wildcard, "...")
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("missing parameter %q for route %q", key, name)
+		}
+
+		if !multi {
+			segments[i] = url.PathEscape(value)
+			continue
+		}
+
+		parts := strings.Split(value, "/")
+		for j, part := range parts {
+			parts[j] = url.PathEscape(part)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// registerRouteName records the path pattern of a named route,
+// reporting an error if another route already uses the name.
+func (a *App) registerRouteName(name, pattern string, c *controller) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.routeNames == nil {
+		a.routeNames = map[string]string{}
+	}
+
+	if existing, ok := a.routeNames[name]; ok {
+		return fmt.Errorf(
+			"duplicate route name (%s): used by (%s) and (%s) of controller (%T) in module (%T)",
+			name, existing, pattern, c.Controller, c.module.Module,
+		)
+	}
+
+	a.routeNames[name] = pattern
+	return nil
+}
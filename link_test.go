@@ -0,0 +1,90 @@
+package godi
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestLinkString(t *testing.T) {
+	tests := []struct {
+		name string
+		link Link
+		want string
+	}{
+		{"url only", Link{URL: "/users"}, `</users>`},
+		{"rel", Link{URL: "/users?page=2", Rel: "next"}, `</users?page=2>; rel="next"`},
+		{"preload", Preload("/static/app.css", "style"), `</static/app.css>; rel="preload"; as="style"`},
+		{
+			"sorted params",
+			Link{URL: "/font.woff2", Rel: "preload", Params: map[string]string{"type": "font/woff2", "as": "font"}},
+			`</font.woff2>; rel="preload"; as="font"; type="font/woff2"`,
+		},
+		{
+			"quote and backslash",
+			Link{URL: "/a", Rel: `x"y`, Params: map[string]string{"title": `say "hi" \o/`}},
+			`</a>; rel="x\"y"; title="say \"hi\" \\o/"`,
+		},
+		{
+			"non-ascii",
+			Link{URL: "/a", Rel: "alternate", Params: map[string]string{"title": "café ☕"}},
+			`</a>; rel="alternate"; title="café ☕"`,
+		},
+		{"tab", Link{URL: "/a", Params: map[string]string{"title": "a\tb"}}, "</a>; title=\"a\tb\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.String(); got != tt.want {
+				t.Errorf("String = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		link Link
+		ok   bool
+	}{
+		{"valid", Link{URL: "/users?page=2", Rel: "next", Params: map[string]string{"title": `"quoted" \ café`}}, true},
+		{"tab", Link{URL: "/a", Params: map[string]string{"title": "a\tb"}}, true},
+		{"closing bracket in url", Link{URL: "/a>; rel=\"evil\"; x=<", Rel: "next"}, false},
+		{"newline in url", Link{URL: "/a\r\nSet-Cookie: x=1"}, false},
+		{"newline in rel", Link{URL: "/a", Rel: "next\nX: y"}, false},
+		{"nul in param", Link{URL: "/a", Params: map[string]string{"title": "a\x00b"}}, false},
+		{"del in param", Link{URL: "/a", Params: map[string]string{"title": "a\x7fb"}}, false},
+		{"empty param key", Link{URL: "/a", Params: map[string]string{"": "x"}}, false},
+		{"space in param key", Link{URL: "/a", Params: map[string]string{"a b": "x"}}, false},
+		{"semicolon in param key", Link{URL: "/a", Params: map[string]string{"a;rel": "x"}}, false},
+		{"comma in param key", Link{URL: "/a", Params: map[string]string{"a,b": "x"}}, false},
+		{"equals in param key", Link{URL: "/a", Params: map[string]string{"a=b": "x"}}, false},
+		{"quote in param key", Link{URL: "/a", Params: map[string]string{`a"`: "x"}}, false},
+		{"newline in param key", Link{URL: "/a", Params: map[string]string{"a\n": "x"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.link.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestAddLinks(t *testing.T) {
+	w := httptest.NewRecorder()
+	AddLinks(w,
+		Link{URL: "/users?page=1", Rel: "prev"},
+		Link{URL: "/a>, </evil", Rel: "next"},
+		Link{URL: "/a", Rel: "next\r\nSet-Cookie: x=1"},
+		Link{URL: "/users?page=3", Rel: "next"},
+	)
+
+	want := []string{`</users?page=1>; rel="prev"`, `</users?page=3>; rel="next"`}
+	if got := w.Header().Values("Link"); !slices.Equal(got, want) {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,243 @@
+package godi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Query parameters of list requests.
+const (
+	// SortParam holds the comma-separated fields to sort by, descending if prefixed
+	// with "-", e.g. "-created_at,name".
+	SortParam = "sort"
+
+	// FieldsParam holds the comma-separated fields to return, e.g. "id,name".
+	FieldsParam = "fields"
+
+	// FilterParam prefixes the parameters filtering by a field, e.g. "filter[status]=active"
+	// or "filter[age][gte]=18".
+	FilterParam = "filter"
+)
+
+// ErrInvalidListQuery is rendered with 400 when the sort, fields or filter parameters
+// of a request are malformed or not allowed.
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// FilterOp is the comparison operator of a filter.
+type FilterOp string
+
+const (
+	OpEq  FilterOp = "eq"
+	OpNe  FilterOp = "ne"
+	OpGt  FilterOp = "gt"
+	OpGte FilterOp = "gte"
+	OpLt  FilterOp = "lt"
+	OpLte FilterOp = "lte"
+	OpIn  FilterOp = "in" // the value is a comma-separated list.
+)
+
+// SortField is a field to sort by.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Filter is a condition on a field, e.g. "age gte 18".
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value string
+}
+
+// Values returns the comma-separated values of an OpIn filter, or its single value.
+func (f Filter) Values() []string {
+	if f.Op != OpIn {
+		return []string{f.Value}
+	}
+	return strings.Split(f.Value, ",")
+}
+
+// ListQuery is the sorting, field selection and filtering requested with the
+// sort, fields and filter query parameters, restricted to the fields allowed
+// by the route's ListQuerySpec.
+type ListQuery struct {
+	// Sort lists the fields to sort by, in order of precedence.
+	Sort []SortField
+
+	// Fields lists the fields to return, or is empty to return every field.
+	Fields []string
+
+	// Filters lists the conditions the items must all satisfy,
+	// sorted by field for reproducible queries.
+	Filters []Filter
+}
+
+// ListQuerySpec lists the fields a list endpoint allows sorting, selecting and filtering by.
+// Parameters referring to other fields are rejected, so the fields can be safely mapped
+// to database columns.
+type ListQuerySpec struct {
+	// Sortable lists the fields the items can be sorted by.
+	Sortable []string
+
+	// Selectable lists the fields that can be requested with the fields parameter.
+	Selectable []string
+
+	// Filterable maps the fields the items can be filtered by to their allowed operators.
+	// Only OpEq is allowed for fields without operators.
+	Filterable map[string][]FilterOp
+
+	// DefaultSort is the sort applied to requests without a sort parameter, e.g. "-created_at".
+	DefaultSort string
+}
+
+// ListQueryParser is an Interceptor parsing the sort, fields and filter query parameters
+// of list endpoints into a ListQuery, which handlers retrieve with ListQueryFrom:
+//
+//	Interceptors: []godi.Interceptor{godi.ParseListQuery(godi.ListQuerySpec{
+//		Sortable:    []string{"name", "created_at"},
+//		Filterable:  map[string][]godi.FilterOp{"status": {godi.OpEq, godi.OpIn}},
+//		DefaultSort: "-created_at",
+//	})},
+//
+// Requests with malformed parameters, or referring to fields or operators the spec does
+// not allow, are rejected with 400 and ErrInvalidListQuery.
+type ListQueryParser struct {
+	spec ListQuerySpec
+}
+
+// ParseListQuery returns a ListQueryParser restricting list queries to the spec.
+func ParseListQuery(spec ListQuerySpec) *ListQueryParser {
+	return &ListQueryParser{spec: spec}
+}
+
+// Intercept implements the Interceptor interface.
+func (p *ListQueryParser) Intercept(ictx InterceptorContext, next http.Handler) error {
+	var (
+		w = ictx.Http.W
+		r = ictx.Http.R
+	)
+
+	query, err := p.parse(r.URL.Query())
+	if err != nil {
+		RenderError(w, r, http.StatusBadRequest, fmt.Errorf("%w: %w", ErrInvalidListQuery, err))
+		return nil
+	}
+
+	if state := requestStateFrom(r.Context()); state != nil {
+		state.query = &query
+	}
+
+	next.ServeHTTP(w, r)
+	return nil
+}
+
+func (p *ListQueryParser) parse(values url.Values) (ListQuery, error) {
+	var (
+		query ListQuery
+		errs  []error
+	)
+
+	sort := p.spec.DefaultSort
+	if values.Has(SortParam) {
+		sort = values.Get(SortParam)
+	}
+
+	for _, field := range splitList(sort) {
+		sf := SortField{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		switch {
+		case !slices.Contains(p.spec.Sortable, sf.Field):
+			errs = append(errs, fmt.Errorf("cannot sort by field (%s)", sf.Field))
+		case slices.ContainsFunc(query.Sort, func(s SortField) bool { return s.Field == sf.Field }):
+			errs = append(errs, fmt.Errorf("duplicate sort field (%s)", sf.Field))
+		default:
+			query.Sort = append(query.Sort, sf)
+		}
+	}
+
+	for _, field := range splitList(values.Get(FieldsParam)) {
+		switch {
+		case !slices.Contains(p.spec.Selectable, field):
+			errs = append(errs, fmt.Errorf("cannot select field (%s)", field))
+		case !slices.Contains(query.Fields, field):
+			query.Fields = append(query.Fields, field)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		if !strings.HasPrefix(key, FilterParam+"[") {
+			continue
+		}
+
+		field, op, err := parseFilterKey(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		ops, ok := p.spec.Filterable[field]
+		if !ok {
+			errs = append(errs, fmt.Errorf("cannot filter by field (%s)", field))
+			continue
+		}
+		if len(ops) == 0 {
+			ops = []FilterOp{OpEq}
+		}
+		if !slices.Contains(ops, op) {
+			errs = append(errs, fmt.Errorf("cannot filter field (%s) with operator (%s)", field, op))
+			continue
+		}
+
+		for _, value := range values[key] {
+			query.Filters = append(query.Filters, Filter{Field: field, Op: op, Value: value})
+		}
+	}
+
+	return query, errors.Join(errs...)
+}
+
+// parseFilterKey parses a filter parameter name of the form
+// "filter[field]" or "filter[field][op]".
+func parseFilterKey(key string) (string, FilterOp, error) {
+	rest := strings.TrimPrefix(key, FilterParam+"[")
+
+	field, rest, ok := strings.Cut(rest, "]")
+	if !ok || field == "" {
+		return "", "", fmt.Errorf("malformed filter (%s)", key)
+	}
+	if rest == "" {
+		return field, OpEq, nil
+	}
+
+	op, ok := strings.CutPrefix(rest, "[")
+	if !ok || !strings.HasSuffix(op, "]") || len(op) < 2 {
+		return "", "", fmt.Errorf("malformed filter (%s)", key)
+	}
+	return field, FilterOp(strings.TrimSuffix(op, "]")), nil
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ListQueryFrom returns the list query of the request handled with ctx,
+// parsed by a ListQueryParser wrapping its route.
+func ListQueryFrom(ctx context.Context) (ListQuery, bool) {
+	state := requestStateFrom(ctx)
+	if state == nil || state.query == nil {
+		return ListQuery{}, false
+	}
+	return *state.query, true
+}
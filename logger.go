@@ -0,0 +1,124 @@
+package godi
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// RequestIDHeader is the header carrying the ID of a request. The ID sent by the
+// caller is kept, so it correlates logs across services, and one is generated otherwise.
+const RequestIDHeader = "X-Request-Id"
+
+// requestLog holds the correlation fields of a request handled by a route.
+type requestLog struct {
+	base    *slog.Logger
+	id      string
+	traceID string
+	route   string
+}
+
+// newRequestLog returns the correlation fields of the request, generating its ID
+// with ids if it has none, and echoing it in the response's RequestIDHeader.
+func newRequestLog(w http.ResponseWriter, r *http.Request, base *slog.Logger, ids IDGenerator) requestLog {
+	id := r.Header.Get(RequestIDHeader)
+	if id == "" || len(id) > 128 {
+		id = ids.NewID()
+	}
+	w.Header().Set(RequestIDHeader, id)
+
+	return requestLog{
+		base:    base,
+		id:      id,
+		traceID: traceIDFrom(r.Header.Get("Traceparent")),
+		route:   r.Pattern,
+	}
+}
+
+// RequestIDFrom returns the ID of the request handled with ctx,
+// or an empty string if ctx does not belong to a route's request.
+func RequestIDFrom(ctx context.Context) string {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return ""
+	}
+	return state.log.id
+}
+
+// Logger returns the application's logger set with WithLogger, or slog.Default()
+// if none was, for modules logging outside of requests, e.g. from background workers.
+func (a *App) Logger() *slog.Logger {
+	if a.opts.logger == nil {
+		return slog.Default()
+	}
+	return a.opts.logger
+}
+
+// LoggerFrom returns the logger of the request handled with ctx, populated with its
+// request ID, trace ID, route pattern and the principal attached by a guard.
+//
+//	func (c *OrderController) handleCreate(w http.ResponseWriter, r *http.Request) {
+//		logger := godi.LoggerFrom(r.Context())
+//		logger.Info("creating order")
+//		...
+//	}
+//
+// The principal is only logged when it implements slog.LogValuer or fmt.Stringer,
+// so credentials held by principals are not written to logs. LoggerFrom returns the
+// application's logger if ctx does not belong to a route's request.
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return slog.Default()
+	}
+
+	l := state.log
+
+	base := l.base
+	if base == nil {
+		base = slog.Default()
+	}
+
+	args := []any{slog.String("request_id", l.id)}
+	if l.traceID != "" {
+		args = append(args, slog.String("trace_id", l.traceID))
+	}
+	if l.route != "" {
+		args = append(args, slog.String("route", l.route))
+	}
+
+	switch p := principalFrom(ctx).(type) {
+	case slog.LogValuer:
+		args = append(args, slog.Any("principal", p))
+	case fmt.Stringer:
+		args = append(args, slog.String("principal", p.String()))
+	}
+
+	return base.With(args...)
+}
+
+// WithLogger sets the logger request loggers returned by LoggerFrom derive from.
+// Defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// traceIDFrom returns the trace ID of a W3C traceparent header, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func traceIDFrom(traceparent string) string {
+	_, rest, ok := strings.Cut(traceparent, "-")
+	if !ok || len(rest) < 33 || rest[32] != '-' {
+		return ""
+	}
+
+	traceID := rest[:32]
+	if _, err := hex.DecodeString(traceID); err != nil || traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+	return traceID
+}
@@ -0,0 +1,80 @@
+package godi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Notifier broadcasts changes to long-poll requests waiting on it, e.g. to push
+// configuration updates to clients polling a config-distribution endpoint.
+//
+// Each change increments the notifier's version. Clients send the last version
+// they have seen and wait until a newer one is available.
+//
+//	version, changed := c.notifier.Wait(r.Context(), since, 30*time.Second)
+//	if !changed {
+//		w.WriteHeader(http.StatusNotModified)
+//		return
+//	}
+//
+// A Notifier is usually provided to the modules that share it, e.g. with
+// godi.Supply(godi.NewNotifier()).
+type Notifier struct {
+	mu      sync.Mutex
+	version uint64
+	changed chan struct{}
+}
+
+// NewNotifier returns a Notifier at version 0.
+func NewNotifier() *Notifier {
+	return &Notifier{changed: make(chan struct{})}
+}
+
+// Notify records a change, waking every request waiting on the notifier.
+// It returns the new version.
+func (n *Notifier) Notify() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.version++
+	close(n.changed)
+	n.changed = make(chan struct{})
+
+	return n.version
+}
+
+// Version returns the current version.
+func (n *Notifier) Version() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.version
+}
+
+// Wait blocks until the version is newer than since, the timeout elapses or ctx is
+// done, e.g. because the client disconnected. It returns the current version and
+// whether it is newer than since. A zero timeout waits until ctx is done.
+func (n *Notifier) Wait(ctx context.Context, since uint64, timeout time.Duration) (uint64, bool) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for {
+		n.mu.Lock()
+		version, changed := n.version, n.changed
+		n.mu.Unlock()
+
+		if version > since {
+			return version, true
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return version, false
+		}
+	}
+}
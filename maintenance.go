@@ -0,0 +1,112 @@
+package godi
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MaintenanceExemptKey is the metadata key exempting a route, or every route of a controller,
+// from maintenance mode when set to "true", e.g. health probes and admin endpoints.
+const MaintenanceExemptKey = "maintenanceExempt"
+
+// ErrMaintenance is rendered with 503 for the requests received in maintenance mode.
+var ErrMaintenance = errors.New("service under maintenance")
+
+// Maintenance is the application's maintenance switch, returned by App.Maintenance.
+// While it is enabled, requests to routes that are not exempt are responded to with
+// 503 and a Retry-After header, before their guards run, e.g. during migrations:
+//
+//	app.Maintenance().Enable(10 * time.Minute)
+//	defer app.Maintenance().Disable()
+//
+// Routes are exempt with their MaintenanceExemptKey metadata or with WithMaintenanceExempt.
+type Maintenance struct {
+	mu         sync.RWMutex
+	enabled    bool
+	since      time.Time
+	retryAfter time.Duration
+}
+
+// MaintenanceStatus is the state of the maintenance switch.
+type MaintenanceStatus struct {
+	Enabled    bool
+	Since      time.Time     // when maintenance mode was enabled.
+	RetryAfter time.Duration // the delay clients are advised to retry after.
+}
+
+// Enable enables maintenance mode, advising clients to retry after retryAfter.
+// The Retry-After header is omitted if retryAfter is zero.
+func (m *Maintenance) Enable(retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled {
+		m.since = time.Now()
+	}
+	m.enabled = true
+	m.retryAfter = retryAfter
+}
+
+// Disable disables maintenance mode.
+func (m *Maintenance) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = false
+	m.since = time.Time{}
+	m.retryAfter = 0
+}
+
+// Status returns the state of the maintenance switch.
+func (m *Maintenance) Status() MaintenanceStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return MaintenanceStatus{Enabled: m.enabled, Since: m.since, RetryAfter: m.retryAfter}
+}
+
+// reject responds to the request with 503 if maintenance mode is enabled,
+// reporting whether it did.
+func (m *Maintenance) reject(w http.ResponseWriter, r *http.Request, renderer ErrorRenderer) bool {
+	status := m.Status()
+	if !status.Enabled {
+		return false
+	}
+
+	if status.RetryAfter > 0 {
+		secs := int64(math.Ceil(status.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+	}
+
+	renderer.RenderError(w, r, http.StatusServiceUnavailable, ErrMaintenance)
+	return true
+}
+
+// Maintenance returns the application's maintenance switch, which can be toggled at runtime,
+// e.g. by the admin module.
+func (a *App) Maintenance() *Maintenance {
+	return &a.maintenance
+}
+
+// WithMaintenanceExempt exempts the routes matching any of the selectors from maintenance mode,
+// in addition to the routes exempt with their MaintenanceExemptKey metadata.
+func WithMaintenanceExempt(selectors ...RouteSelector) Option {
+	return func(o *options) {
+		o.maintenanceExempt = append(o.maintenanceExempt, selectors...)
+	}
+}
+
+// isMaintenanceExempt reports whether the route described by info stays live in maintenance mode.
+func (a *App) isMaintenanceExempt(info *RouteInfo) bool {
+	if v, _ := MetadataValue(MaintenanceExemptKey, info.Metadata, info.ControllerMetadata); v == "true" {
+		return true
+	}
+	return slices.ContainsFunc(a.opts.maintenanceExempt, func(sel RouteSelector) bool {
+		return sel.Match(info)
+	})
+}
@@ -1,7 +1,10 @@
 package godi
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"time"
 
 	"go.uber.org/dig"
 )
@@ -28,12 +31,24 @@ type (
 		// Providers from these modules will be available within this module.
 		Imports []Module
 
+		// ImportsOptional lists modules whose exports are available within this module
+		// if the application imports them elsewhere, without importing them, e.g. to
+		// integrate with metrics or tracing modules in reusable library modules.
+		//
+		// The module shares the instances built by the imported module, and is wired
+		// without them otherwise, so dependencies on their exports must be optional,
+		// e.g. with `godi:"inject,optional"` fields.
+		ImportsOptional []Module
+
 		// Exports lists providers from this module that should be accessible to
 		// other modules that import this module.
 		Exports []Provider
 
 		// ExportsCtors lists constructors for providers that should be accessible
 		// in other modules importing this module.
+		//
+		// Each constructor must also be listed in ProvidersCtors. Importing modules
+		// share the instance built by this module rather than building their own.
 		ExportsCtors []ProviderConstructor
 
 		// Providers lists the providers within the module that are shared across
@@ -51,55 +66,179 @@ type (
 		// ControllersCtors lists constructors for controllers in this module that
 		// will be instantiated by the Godi injector.
 		ControllersCtors []ControllerConstructor
+
+		// Guards lists guards applied to every route of the module's controllers,
+		// after global guards and before controller guards. They do not apply to
+		// the controllers of imported modules.
+		Guards []Guard
+
+		// GuardsCtors lists constructors for module-wide guards, built once in the
+		// module's scope after the guards listed in Guards.
+		GuardsCtors []GuardConstructor
+
+		// Interceptors lists interceptors wrapping every route of the module's
+		// controllers, inside global interceptors and outside controller interceptors.
+		Interceptors []Interceptor
+
+		// InterceptorsCtors lists constructors for module-wide interceptors, built once
+		// in the module's scope after the interceptors listed in Interceptors.
+		InterceptorsCtors []InterceptorConstructor
+
+		// Profiles restricts the profiles the module is imported in, e.g. a module
+		// seeding fixtures in dev and test only. The module is imported in every
+		// profile if empty.
+		Profiles []Profile
+
+		// Lazy defers building the module's controllers, and the providers they
+		// depend on, until a request under one of its LazyPaths is received,
+		// reducing the startup cost of rarely used modules, like admin or batch
+		// modules. The request is then dispatched to the module's routes.
+		//
+		// Lazy modules and the modules they import are missing from the module
+		// tree until they are initialized, and their start and stop hooks are
+		// registered when the application is created. Their constructors receive
+		// a startup context created when they are initialized.
+		Lazy bool
+
+		// LazyPaths lists the path prefixes of the routes of a lazy module, e.g.
+		// "/admin", whose requests initialize it. Lazy modules must list at least one.
+		LazyPaths []string
+
+		// ErrorCodes declares the error codes returned by the module in the
+		// application's error code registry, listed by App.ErrorCodes.
+		ErrorCodes []*ErrorCode
 	}
 )
 
 // module is a wrapper for managing an instance of a Module.
 type module struct {
 	Module
-	scope   scope
-	parent  *module
-	imports []*module
+	app          *App
+	scope        scope
+	parent       *module
+	imports      []*module
+	controllers  []*controller
+	guards       []*guard       // guards applied to the routes of the module's controllers.
+	interceptors []*interceptor // interceptors wrapping the routes of the module's controllers.
+	elapsed      time.Duration  // time spent registering providers and building controllers.
+
+	lifecycleRegistered bool // whether the start and stop hooks were registered ahead of a lazy init.
 }
 
-func newModule(m Module, s scope) (*module, error) {
+// newModule creates the module and the modules it imports, registering their providers.
+//
+// Controllers are not built until init is called, so that every provider in the
+// module tree is registered before any constructor runs.
+func newModule(m Module, s scope, app *App, parent *module) (*module, error) {
 	var (
-		err error
-		mod = &module{
+		err   error
+		start = time.Now()
+		mod   = &module{
+			app:    app,
 			scope:  s,
 			Module: m,
 		}
 	)
 
+	err = mod.assignParent(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mod.Config().validate()
+	if err != nil {
+		return nil, wrapErrors(err, "invalid config for module (%T)", m)
+	}
+
+	var errs []error
+
 	err = mod._registerProviders()
 	if err != nil {
-		return nil, fmt.Errorf("error registering providers: %w", err)
+		errs = append(errs, wrapErrors(err, "error registering providers"))
 	}
 
-	err = mod._registerControllers()
+	err = mod._registerErrorCodes()
 	if err != nil {
-		return nil, fmt.Errorf("error registering controllers: %w", err)
+		errs = append(errs, wrapErrors(err, "error registering error codes"))
 	}
 
-	// recursively create imported modules
+	mod.elapsed = time.Since(start)
+
+	// recursively create imported modules, reporting the errors
+	// of every module in the tree rather than only the first one
 	for _, imported := range mod.Config().Imports {
-		importedMod, err := newModule(imported, mod.newChildScope(imported))
-		if err != nil {
-			return nil, fmt.Errorf("error building module (%T): %w", imported, err)
+		if iCfg := imported.Config(); iCfg != nil && !app.opts.profileActive(iCfg.Profiles) {
+			app.debugf("skip module %s in %s: not active in profile %q", GetToken(imported), mod.path(), app.opts.profile)
+			continue
 		}
 
-		err = importedMod.assignParent(mod)
+		importedMod, err := newModule(imported, mod.newChildScope(imported), mod.app, mod)
 		if err != nil {
-			return nil, err
+			errs = append(errs, wrapErrors(err, "error building module (%T)", imported))
+			continue
 		}
 
 		err = importedMod._registerExportedProviders()
 		if err != nil {
-			return nil, fmt.Errorf("error registering exports: %w", err)
+			errs = append(errs, wrapErrors(err, "error registering exports"))
+		}
+	}
+
+	return mod, errors.Join(errs...)
+}
+
+// init builds the controllers of the module and the modules it imports,
+// starting with the imported modules.
+func (m *module) init() error {
+	if m.Config().Lazy && m.parent != nil {
+		m.app.deferInit(m)
+		return m.registerLifecycleTree()
+	}
+	return m.initNow()
+}
+
+// initNow builds the controllers of the module and the modules it imports,
+// starting with the imported modules.
+func (m *module) initNow() error {
+	var errs []error
+	for _, imported := range m.imports {
+		err := imported.init()
+		if err != nil {
+			errs = append(errs, wrapErrors(err, "error initializing module (%T)", imported.Module))
+		}
+	}
+
+	start := time.Now()
+	err := m._registerControllers()
+	if err != nil {
+		errs = append(errs, wrapErrors(err, "error registering controllers"))
+	}
+
+	if !m.lifecycleRegistered {
+		err = m._registerLifecycle()
+		if err != nil {
+			errs = append(errs, wrapErrors(err, "error registering lifecycle hooks"))
+		}
+	}
+
+	m.elapsed += time.Since(start)
+	m.app.recordModule(m, m.elapsed)
+
+	return errors.Join(errs...)
+}
+
+// registerLifecycleTree registers the start and stop hooks of the lazy module and
+// the modules it imports, so they run even if the module is initialized later.
+func (m *module) registerLifecycleTree() error {
+	for _, imported := range m.imports {
+		err := imported.registerLifecycleTree()
+		if err != nil {
+			return err
 		}
 	}
 
-	return mod, nil
+	m.lifecycleRegistered = true
+	return m._registerLifecycle()
 }
 
 // assignParent assigns the module's parent and append itself to the parent import list
@@ -112,18 +251,38 @@ func (m *module) assignParent(parent *module) error {
 }
 
 func (m *module) _registerProviders() error {
-	mCfg := m.Config()
+	var (
+		errs []error
+		mCfg = m.Config()
+	)
+
 	for _, pvdCtor := range mCfg.ProvidersCtors {
+		if m.app.isOverridden(pvdCtor) {
+			m.app.debugf("skip fallback %s in %s: overridden by another provider", funcName(pvdCtor), m.path())
+			continue
+		}
+
+		if m.app.isExcluded(pvdCtor) {
+			m.app.debugf("skip %s in %s: not active in profile %q", funcName(pvdCtor), m.path(), m.app.opts.profile)
+			continue
+		}
+
 		isGlobExport := (mCfg.IsGlobal && m.isExportedProvider(pvdCtor))
 
+		err := m.app.registerProvider(registration{ctor: pvdCtor, owner: m, scope: m, global: isGlobExport})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
 		// a global module's exported providers
 		// should be made available to all available scopes
-		err := m.scope.Provide(pvdCtor, dig.Export(isGlobExport))
+		err = m.provideProvider(pvdCtor, dig.Export(isGlobExport))
 		if err != nil {
-			return fmt.Errorf("error providing provider (%T): %w", pvdCtor, err)
+			errs = append(errs, fmt.Errorf("error providing provider (%s): %w", funcName(pvdCtor), err))
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // _registerControllers registers controllers in the group named "controllers" in the module scope
@@ -136,27 +295,71 @@ func (m *module) _registerControllers() error {
 		}
 	)
 
+	var err error
+
+	m.guards, err = buildGuards(m, mCfg.Guards, mCfg.GuardsCtors)
+	if err != nil {
+		return fmt.Errorf("error registering guards: %w", err)
+	}
+
+	m.interceptors, err = buildInterceptors(m, mCfg.Interceptors, mCfg.InterceptorsCtors)
+	if err != nil {
+		return fmt.Errorf("error registering interceptors: %w", err)
+	}
+
+	var errs []error
 	for _, ctrlCtor := range mCfg.ControllersCtors {
-		err := m.scope.Provide(ctrlCtor, opts...)
+		err := m.provide(ctrlCtor, opts...)
 		if err != nil {
-			return fmt.Errorf("error providing controller (%T): %w", ctrlCtor, err)
+			errs = append(errs, fmt.Errorf("error providing controller (%T): %w", ctrlCtor, err))
 		}
 	}
 
-	return m.scope.Invoke(
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return m.invoke(
 		func(input controllerGroupInput) error {
 			for _, controller := range input.Controllers {
-				_, err := newController(controller, m)
+				if m.ancestorOwns(controller) {
+					continue
+				}
+
+				ctrl, err := newController(controller, m)
 				if err != nil {
-					return err
+					errs = append(errs, err)
+					continue
 				}
+				m.controllers = append(m.controllers, ctrl)
 			}
-			return nil
+			return errors.Join(errs...)
 		},
 	)
 }
 
-// _registerExportedProviders registers the current module's exports in it's parent scope
+// ancestorOwns reports whether the controller was built by an ancestor of the module,
+// since the scope of a lazy module initialized after its ancestors inherits their
+// controllers group.
+func (m *module) ancestorOwns(c Controller) bool {
+	if !reflect.TypeOf(c).Comparable() {
+		return false
+	}
+
+	for p := m.parent; p != nil; p = p.parent {
+		for _, ctrl := range p.controllers {
+			if ctrl.Controller == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// _registerExportedProviders registers the current module's exports in it's parent scope.
+//
+// Exported values are forwarded from the module's own scope rather than constructed
+// again, so the module and its importers share a single instance.
 func (m *module) _registerExportedProviders() error {
 	mCfg := m.Config()
 	// a global module's exports would be
@@ -167,22 +370,275 @@ func (m *module) _registerExportedProviders() error {
 	}
 
 	for _, pvdCtor := range mCfg.ExportsCtors {
-		err := m.parent.scope.Provide(pvdCtor)
+		// an overridden fallback or a provider of another profile isn't
+		// provided in the module so there is nothing to forward to importing modules
+		if m.app.isOverridden(pvdCtor) || m.app.isOverridden(m.providerOf(pvdCtor)) {
+			continue
+		}
+		if m.app.isExcluded(pvdCtor) || m.app.isExcluded(m.providerOf(pvdCtor)) {
+			continue
+		}
+
+		err := m.app.registerProvider(registration{ctor: pvdCtor, owner: m, scope: m.parent, export: true})
 		if err != nil {
-			return fmt.Errorf("error providing export (%T): %w", pvdCtor, err)
+			return err
+		}
+
+		for _, k := range resultKeys(pvdCtor) {
+			err = m.parent.register(m.forwarder(k), dig.Name(k.name))
+			if err != nil {
+				return fmt.Errorf("error providing export (%s): %w", funcName(pvdCtor), err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// linkOptionalImports forwards the exports of the modules the module and its imports
+// optionally import, from the modules of the same type imported in the application.
+func (m *module) linkOptionalImports() error {
+	var errs []error
+	for _, imported := range m.imports {
+		errs = append(errs, imported.linkOptionalImports())
+	}
+
+	for _, optional := range m.Config().ImportsOptional {
+		source := m.app.module.find(GetToken(optional))
+		if source == nil {
+			m.app.debugf("skip optional import %s in %s: not imported by the application", GetToken(optional), m.path())
+			continue
+		}
+
+		// global exports are available to every module already,
+		// and the exports of imported modules to their importers.
+		if source.Config().IsGlobal || source.parent == m {
+			continue
+		}
+
+		err := source.forwardExports(m)
+		if err != nil {
+			errs = append(errs, wrapErrors(err, "error linking optional import (%T) in module (%T)", optional, m.Module))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// forwardExports provides the module's exports in the importer's scope, forwarded
+// from the module's own scope.
+func (m *module) forwardExports(importer *module) error {
+	for _, pvdCtor := range m.Config().ExportsCtors {
+		if m.app.isOverridden(pvdCtor) || m.app.isOverridden(m.providerOf(pvdCtor)) {
+			continue
+		}
+		if m.app.isExcluded(pvdCtor) || m.app.isExcluded(m.providerOf(pvdCtor)) {
+			continue
+		}
+
+		for _, k := range resultKeys(pvdCtor) {
+			err := importer.register(m.forwarder(k), dig.Name(k.name))
+			if err != nil {
+				return fmt.Errorf("error providing export (%s): %w", funcName(pvdCtor), err)
+			}
+		}
+	}
+	return nil
+}
+
+// find returns the first module of the tree with the token, searching depth-first.
+func (m *module) find(token string) *module {
+	if GetToken(m.Module) == token {
+		return m
+	}
+	for _, imported := range m.imports {
+		if found := imported.find(token); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// forwarder returns a constructor that resolves the value identified by k from
+// the module's scope, so it can be provided in other scopes without being built twice.
+func (m *module) forwarder(k providerKey) any {
+	return reflect.MakeFunc(
+		reflect.FuncOf(nil, []reflect.Type{k.t, errorType}, false),
+		func([]reflect.Value) []reflect.Value {
+			value, err := m.resolve(k)
+			if err != nil {
+				return []reflect.Value{value, reflect.ValueOf(&err).Elem()}
+			}
+			return []reflect.Value{value, reflect.Zero(errorType)}
+		},
+	).Interface()
+}
+
+// resolve retrieves the value identified by k from the module's scope.
+func (m *module) resolve(k providerKey) (reflect.Value, error) {
+	value := reflect.Zero(k.t)
+	fn := reflect.MakeFunc(
+		reflect.FuncOf([]reflect.Type{k.in()}, nil, false),
+		func(args []reflect.Value) []reflect.Value {
+			value = args[0].Field(1)
+			return nil
+		},
+	)
+
+	err := m.invoke(fn.Interface())
+	return value, err
+}
+
+// provide registers a constructor in the module scope, recording its run time
+// in the app's startup report.
+func (m *module) provide(ctor constructor, opts ...dig.ProvideOption) error {
+	return m.register(ctor, append(opts, m.app.constructorCallback(m, ctor))...)
+}
+
+// provideProvider registers a provider constructor in the module scope
+// along with the interface bindings it is annotated with.
+func (m *module) provideProvider(ctor ProviderConstructor, opts ...dig.ProvideOption) error {
+	a := annotate(ctor)
+	if a.err != nil {
+		return a.err
+	}
+
+	if a.name != "" {
+		if a.group != "" {
+			return fmt.Errorf("%s cannot be both named and grouped", funcName(a.ctor))
+		}
+		opts = append(opts, dig.Name(a.name))
+	}
+
+	// group values are contributed app-wide so any module can consume them,
+	// and are bound to interfaces in place of their own type.
+	if a.group != "" {
+		ifaces := make([]any, len(a.as))
+		for i, iface := range a.as {
+			ifaces[i] = reflect.New(iface).Interface()
+		}
+		return m.provide(a.ctor, dig.Group(a.group), dig.As(ifaces...), dig.Export(true))
+	}
+
+	binders, err := a.binders()
+	if err != nil {
+		return err
+	}
+
+	err = m.provide(a.ctor, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, binder := range binders {
+		err = m.register(binder, opts...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// register provides a constructor in the module scope,
+// tracing the registration when debug mode is enabled.
+func (m *module) register(ctor any, opts ...dig.ProvideOption) error {
+	info := dig.ProvideInfo{}
+	err := m.scope.Provide(ctor, append(opts, dig.FillProvideInfo(&info))...)
+	m.app.debugProvide(m, ctor, info, err)
+	return err
+}
+
+// invoke runs the function in the module scope,
+// tracing the invocation when debug mode is enabled.
+func (m *module) invoke(fn any) error {
+	info := dig.InvokeInfo{}
+	err := m.scope.Invoke(fn, dig.FillInvokeInfo(&info))
+	m.app.debugInvoke(m, fn, info, err)
+	return err
+}
+
+// build invokes the constructor in the module scope and returns the value it produces.
+//
+// Unlike provided constructors, the result is not shared through the container,
+// so every call builds a new value owned by the caller.
+func (m *module) build(ctor constructor) (any, error) {
+	fn := reflect.ValueOf(ctor)
+	if fn.Kind() != reflect.Func {
+		return nil, fmt.Errorf("constructor (%T) is not a function", ctor)
+	}
+
+	var (
+		t       = fn.Type()
+		ins     = make([]reflect.Type, t.NumIn())
+		value   any
+		elapsed time.Duration
+	)
+
+	for i := range ins {
+		ins[i] = t.In(i)
+	}
+
+	invoker := reflect.MakeFunc(
+		reflect.FuncOf(ins, []reflect.Type{errorType}, false),
+		func(args []reflect.Value) []reflect.Value {
+			start := time.Now()
+			results := fn.Call(args)
+			elapsed = time.Since(start)
+
+			err := reflect.Zero(errorType)
+			for _, result := range results {
+				switch {
+				case result.Type() == errorType:
+					err = result
+				case value == nil:
+					value = result.Interface()
+				}
+			}
+			return []reflect.Value{err}
+		},
+	)
+
+	err := m.invoke(invoker.Interface())
+	m.app.recordConstructor(m, ctor, funcName(ctor), elapsed, err)
+	return value, err
+}
+
+// path returns the module's position in the module tree, e.g. "*app.Module/*auth.Module".
+func (m *module) path() string {
+	if m.parent == nil {
+		return GetToken(m.Module)
+	}
+	return m.parent.path() + "/" + GetToken(m.Module)
+}
+
 func (m *module) newChildScope(mod Module) scope {
 	return m.scope.Scope(GetToken(mod))
 }
 
+// isAncestorOf reports whether the module's scope is the same as, or an ancestor of, the other's scope.
+func (m *module) isAncestorOf(other *module) bool {
+	for ; other != nil; other = other.parent {
+		if other == m {
+			return true
+		}
+	}
+	return false
+}
+
+// providerOf returns the entry of ProvidersCtors that matches the exported constructor.
+func (m *module) providerOf(export ProviderConstructor) ProviderConstructor {
+	for _, pvdCtor := range m.Config().ProvidersCtors {
+		if funcName(pvdCtor) == funcName(export) {
+			return pvdCtor
+		}
+	}
+	return nil
+}
+
 func (m *module) isExportedProvider(provider ProviderConstructor) bool {
 	for _, export := range m.Config().ExportsCtors {
-		if GetToken(export) == GetToken(provider) {
+		if funcName(export) == funcName(provider) {
 			return true
 		}
 	}
@@ -0,0 +1,104 @@
+package godi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// counter is a provided value. Its field gives it a size, so that its instances
+// have distinct addresses.
+type counter struct {
+	n int
+}
+
+func TestExportedProviders(t *testing.T) {
+	var (
+		builds     int
+		newCounter = func() *counter { builds++; return &counter{} }
+		shared     = &counter{}
+	)
+
+	tests := []struct {
+		name  string
+		users *ModuleConfig
+	}{
+		{
+			"constructor",
+			&ModuleConfig{ProvidersCtors: []ProviderConstructor{newCounter}, ExportsCtors: []ProviderConstructor{newCounter}},
+		},
+		{
+			// Supply returns a distinct closure on every call
+			"supplied value",
+			&ModuleConfig{ProvidersCtors: []ProviderConstructor{Supply(shared)}, ExportsCtors: []ProviderConstructor{Supply(shared)}},
+		},
+		{
+			"named value",
+			&ModuleConfig{
+				ProvidersCtors: []ProviderConstructor{Named[*counter]("hits").Provide(newCounter)},
+				ExportsCtors:   []ProviderConstructor{Named[*counter]("hits").Provide(newCounter)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builds = 0
+
+			app, err := New(newTestTree(nil, tt.users, nil))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			k := providerKey{t: reflect.TypeFor[*counter]()}
+			if a, ok := tt.users.ExportsCtors[0].(annotated); ok {
+				k.name = a.name
+			}
+
+			inRoot, err := app.module.resolve(k)
+			if err != nil {
+				t.Fatalf("resolving the export in the importing module: %v", err)
+			}
+			inUsers, err := app.module.imports[0].resolve(k)
+			if err != nil {
+				t.Fatalf("resolving the export in the exporting module: %v", err)
+			}
+
+			if inRoot.Interface() != inUsers.Interface() {
+				t.Error("the importing module got another instance than the exporting module")
+			}
+			if builds > 1 {
+				t.Errorf("the exported constructor ran %d times, want once", builds)
+			}
+		})
+	}
+}
+
+func TestExportsNotProvided(t *testing.T) {
+	newCounter := func() *counter { return &counter{} }
+
+	_, err := New(newTestTree(nil, &ModuleConfig{ExportsCtors: []ProviderConstructor{newCounter}}, nil))
+	if err == nil || !strings.Contains(err.Error(), "is not listed in ProvidersCtors") {
+		t.Errorf("New = %v, want an error for the export missing from ProvidersCtors", err)
+	}
+}
+
+func TestPrivateProviders(t *testing.T) {
+	newCounter := func() *counter { return &counter{} }
+
+	app, err := New(newTestTree(nil, &ModuleConfig{ProvidersCtors: []ProviderConstructor{newCounter}}, &ModuleConfig{}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	k := providerKey{t: reflect.TypeFor[*counter]()}
+	if _, err := app.module.imports[0].resolve(k); err != nil {
+		t.Errorf("resolving the provider in its module: %v", err)
+	}
+	if _, err := app.module.resolve(k); err == nil {
+		t.Error("the unexported provider is visible to the importing module")
+	}
+	if _, err := app.module.imports[1].resolve(k); err == nil {
+		t.Error("the unexported provider is visible to a sibling module")
+	}
+}
@@ -0,0 +1,72 @@
+package godi
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// acceptsContentType reports whether the request body's content type is one of
+// the media types the route consumes. Requests without a body are always accepted.
+func (r *route) acceptsContentType(req *http.Request) bool {
+	if len(r.Consumes) == 0 || !hasBody(req) {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, consumed := range r.Consumes {
+		if matchMediaType(consumed, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// producesAccepted reports whether one of the media types the route produces is
+// acceptable according to the request's Accept header.
+func (r *route) producesAccepted(req *http.Request) bool {
+	accept := req.Header.Values("Accept")
+	if len(r.Produces) == 0 || len(accept) == 0 {
+		return true
+	}
+
+	for _, value := range accept {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+
+			for _, produced := range r.Produces {
+				if matchMediaType(mediaType, produced) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// matchMediaType reports whether the media type matches the pattern,
+// which may use wildcards like "*/*" or "text/*".
+func matchMediaType(pattern, mediaType string) bool {
+	pattern, _, _ = mime.ParseMediaType(pattern)
+
+	pType, pSub, _ := strings.Cut(pattern, "/")
+	mType, mSub, _ := strings.Cut(mediaType, "/")
+
+	return (pType == "*" || pType == mType) && (pSub == "*" || pSub == mSub)
+}
+
+// hasBody reports whether the request carries a body.
+func hasBody(req *http.Request) bool {
+	return req.ContentLength > 0 || (req.ContentLength < 0 && req.Body != nil && req.Body != http.NoBody)
+}
@@ -0,0 +1,56 @@
+package godi
+
+import (
+	"net/http"
+	"time"
+)
+
+// Layer is a stage of the request pipeline of a route.
+type Layer string
+
+const (
+	// LayerGuard is the run of a guard.
+	LayerGuard Layer = "guard"
+
+	// LayerInterceptor is the run of an interceptor, including the
+	// interceptors and handler it wraps.
+	LayerInterceptor Layer = "interceptor"
+
+	// LayerHandler is the run of the route handler.
+	LayerHandler Layer = "handler"
+)
+
+// LayerObserver is a function called after each layer of a route's request pipeline
+// runs, with the type or function name of the guard, interceptor or handler and its duration.
+// It is called concurrently by the requests being served.
+type LayerObserver func(r *http.Request, layer Layer, name string, d time.Duration)
+
+// WithLayerObserver sets the function observing the duration of each layer of the
+// request pipeline, e.g. to find the guards or interceptors slowing requests down.
+func WithLayerObserver(fn LayerObserver) Option {
+	return func(o *options) {
+		o.layerObserver = fn
+	}
+}
+
+// observeLayer reports the duration of a layer to the layer observer, if any.
+func (a *App) observeLayer(r *http.Request, layer Layer, v any, d time.Duration) {
+	if a.opts.layerObserver != nil {
+		a.opts.layerObserver(r, layer, funcName(v), d)
+	}
+}
+
+// observeHandler wraps the route handler to report its duration
+// to the layer observer, if any.
+func (a *App) observeHandler(handler http.Handler) http.Handler {
+	if a.opts.layerObserver == nil {
+		return handler
+	}
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			handler.ServeHTTP(w, r)
+			a.observeLayer(r, LayerHandler, handler, time.Since(start))
+		},
+	)
+}
@@ -0,0 +1,172 @@
+package godi
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"log/slog"
+	mrand "math/rand/v2"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// Option configures the App created by New.
+type Option func(*options)
+
+// options holds the settings applied to an App through Option values.
+type options struct {
+	// ctx is the parent of the context handed to constructors during startup.
+	ctx context.Context
+
+	// startupTimeout bounds how long the module tree may take to initialize.
+	// A zero value means initialization is not bounded.
+	startupTimeout time.Duration
+
+	// logStartup reports whether the startup report is logged once the app is created.
+	logStartup bool
+
+	// debug is the logger that DI resolution traces are written to.
+	// A nil logger disables debug mode.
+	debug *log.Logger
+
+	// bodyCodecs encode and decode the bodies of typed handlers in media types other than JSON.
+	bodyCodecs []BodyCodec
+
+	// validationTranslators translate the messages of ValidationErrors.
+	validationTranslators []ValidationTranslator
+
+	// introspection reports whether the module tree endpoint is mounted.
+	introspection bool
+
+	// shutdownTimeout bounds each phase of the server's graceful shutdown.
+	shutdownTimeout time.Duration
+
+	// baseContext and connContext set the contexts of the server's connections and requests.
+	baseContext func(net.Listener) context.Context
+	connContext func(ctx context.Context, c net.Conn) context.Context
+
+	// idleTimeout bounds how long idle keep-alive connections are kept open.
+	idleTimeout time.Duration
+
+	// tlsConfig serves requests over TLS if set, e.g. with client certificates.
+	tlsConfig *tls.Config
+
+	// listenRetry configures how binding an address already in use is retried.
+	listenRetry listenRetry
+
+	// readinessPath is the path the readiness endpoint is mounted at, if any.
+	readinessPath string
+
+	// versionPath is the path the version endpoint is mounted at, if any.
+	versionPath string
+
+	// profile is the environment the application runs in.
+	profile Profile
+
+	// logger is the logger request loggers derive from. A nil logger uses slog.Default().
+	logger *slog.Logger
+
+	// layerObserver observes the duration of each layer of the request pipeline.
+	layerObserver LayerObserver
+
+	// redactor masks secrets in debug traces, logged errors and diagnostic endpoints.
+	redactor *Redactor
+
+	// ids generates the IDs of requests, and rand is the source of randomness
+	// provided to modules. A nil source reads from crypto/rand.
+	ids  IDGenerator
+	rand mrand.Source
+
+	// maxBodySize bounds the request bodies decoded by typed handlers, in bytes.
+	// A zero value means bodies are not bounded.
+	maxBodySize int64
+
+	// requestTimeout bounds the time every route may take to respond.
+	// A zero value means requests are not bounded.
+	requestTimeout time.Duration
+
+	// loadShedding configures the shedding of low-priority routes under overload.
+	// A nil value disables load shedding.
+	loadShedding *LoadShedding
+
+	// trustedProxies are the proxies whose X-Forwarded-For header ClientIP trusts.
+	trustedProxies []netip.Prefix
+
+	// maintenanceExempt selects the routes staying live in maintenance mode.
+	maintenanceExempt []RouteSelector
+
+	// guards and interceptors apply to every route of the application.
+	guards            []Guard
+	guardsCtors       []GuardConstructor
+	interceptors      []Interceptor
+	interceptorsCtors []InterceptorConstructor
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		ctx:         context.Background(),
+		redactor:    defaultRedactor,
+		maxBodySize: defaultMaxBodySize,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.ids == nil {
+		o.ids = UUIDGenerator{Source: o.rand}
+	}
+
+	return o
+}
+
+// startupContext returns the context injected into constructors while the
+// module tree is being built, bounded by the configured startup timeout.
+func (o *options) startupContext() (context.Context, context.CancelFunc) {
+	if o.startupTimeout > 0 {
+		return context.WithTimeout(o.ctx, o.startupTimeout)
+	}
+	return context.WithCancel(o.ctx)
+}
+
+// WithContext sets the parent context of the startup context.
+//
+// Canceling ctx while the application is being created aborts any constructor
+// that depends on the injected context.Context.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		if ctx != nil {
+			o.ctx = ctx
+		}
+	}
+}
+
+// WithStartupTimeout bounds the time the module tree may take to initialize.
+//
+// Constructors that accept a context.Context receive a context that is canceled
+// once the timeout elapses, so blocking work like dialing a database or running
+// migrations can give up cleanly. New fails if the timeout is exceeded.
+func WithStartupTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.startupTimeout = d
+	}
+}
+
+// WithStartupLog logs the StartupReport once the application is created,
+// helping diagnose slow boot times in large module trees.
+func WithStartupLog() Option {
+	return func(o *options) {
+		o.logStartup = true
+	}
+}
+
+// WithRedactor sets the Redactor used to mask secrets in debug traces, the startup
+// report and diagnostic endpoints. Defaults to a Redactor using DefaultSecretPattern.
+func WithRedactor(r *Redactor) Option {
+	return func(o *options) {
+		if r != nil {
+			o.redactor = r
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package godi
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Query parameters of paginated requests.
+const (
+	// PageParam holds the 1-based number of the requested page, for offset pagination.
+	PageParam = "page"
+
+	// LimitParam holds the maximum number of items of the requested page.
+	LimitParam = "limit"
+
+	// CursorParam holds the opaque cursor of the requested page, for cursor pagination.
+	CursorParam = "cursor"
+)
+
+// ErrInvalidPageRequest is rendered with 400 when the pagination parameters of a request are malformed.
+var ErrInvalidPageRequest = errors.New("invalid page request")
+
+// PageRequest is the page of items requested with the page, limit and cursor query parameters.
+type PageRequest struct {
+	// Page is the 1-based number of the requested page. It is 1 for cursor pagination.
+	Page int
+
+	// Limit is the maximum number of items of the page.
+	Limit int
+
+	// Cursor is the opaque cursor of the requested page, or empty for the first page
+	// and for offset pagination.
+	Cursor string
+}
+
+// Offset returns the number of items preceding the requested page, for offset pagination.
+func (p PageRequest) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// Paginator is an Interceptor parsing the page, limit and cursor query parameters of
+// list endpoints into a PageRequest, which handlers retrieve with PageRequestFrom:
+//
+//	Interceptors: []godi.Interceptor{godi.Paginate(20, 100)},
+//	Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		page, _ := godi.PageRequestFrom(r.Context())
+//		users, total, err := c.users.List(r.Context(), page.Offset(), page.Limit)
+//		...
+//		godi.WritePage(w, r, users, godi.PageMeta{Total: total})
+//	}),
+//
+// Requests with malformed parameters, or with both a page and a cursor, are rejected
+// with 400 and ErrInvalidPageRequest.
+type Paginator struct {
+	defaultLimit int
+	maxLimit     int
+}
+
+// Paginate returns a Paginator using defaultLimit for requests without a limit and
+// capping limits at maxLimit. The default limit is 20 if zero, and limits are not
+// capped if maxLimit is zero.
+func Paginate(defaultLimit, maxLimit int) *Paginator {
+	return &Paginator{defaultLimit: cmp.Or(defaultLimit, 20), maxLimit: maxLimit}
+}
+
+// Intercept implements the Interceptor interface.
+func (p *Paginator) Intercept(ictx InterceptorContext, next http.Handler) error {
+	var (
+		w = ictx.Http.W
+		r = ictx.Http.R
+	)
+
+	page, err := p.parse(r.URL.Query())
+	if err != nil {
+		RenderError(w, r, http.StatusBadRequest, fmt.Errorf("%w: %w", ErrInvalidPageRequest, err))
+		return nil
+	}
+
+	if state := requestStateFrom(r.Context()); state != nil {
+		state.page = &page
+	}
+
+	next.ServeHTTP(w, r)
+	return nil
+}
+
+func (p *Paginator) parse(query url.Values) (PageRequest, error) {
+	page := PageRequest{
+		Page:   1,
+		Limit:  p.defaultLimit,
+		Cursor: query.Get(CursorParam),
+	}
+
+	if v := query.Get(PageParam); v != "" {
+		if page.Cursor != "" {
+			return page, errors.New("page and cursor are mutually exclusive")
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return page, fmt.Errorf("page (%s) is not a positive integer", v)
+		}
+		page.Page = n
+	}
+
+	if v := query.Get(LimitParam); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return page, fmt.Errorf("limit (%s) is not a positive integer", v)
+		}
+		page.Limit = n
+	}
+
+	if p.maxLimit > 0 {
+		page.Limit = min(page.Limit, p.maxLimit)
+	}
+
+	return page, nil
+}
+
+// PageRequestFrom returns the page requested by the request handled with ctx,
+// parsed by a Paginator wrapping its route.
+func PageRequestFrom(ctx context.Context) (PageRequest, bool) {
+	state := requestStateFrom(ctx)
+	if state == nil || state.page == nil {
+		return PageRequest{}, false
+	}
+	return *state.page, true
+}
+
+// PageMeta is the pagination metadata of the envelope written by WritePage and Paginated.
+type PageMeta struct {
+	// Page and Limit are the number and size of the page, set from the request's PageRequest.
+	Page  int `json:"page,omitempty"`
+	Limit int `json:"limit,omitempty"`
+
+	// Total is the number of items of every page, for offset pagination. It is omitted if zero,
+	// e.g. when counting the items is too expensive.
+	Total int `json:"total,omitempty"`
+
+	// NextCursor is the cursor of the next page, for cursor pagination. It is omitted on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// WritePage responds with a page of items in the envelope written by the Envelope
+// interceptor, with its metadata filled from the request's PageRequest, and adds Link
+// headers to the first, previous, next and last pages, e.g. for the second page:
+//
+//	{"data": [...], "meta": {"page": 2, "limit": 20, "total": 135}}
+//	Link: </users?limit=20&page=1>; rel="first"
+//	Link: </users?limit=20&page=1>; rel="prev"
+//	Link: </users?limit=20&page=3>; rel="next"
+//	Link: </users?limit=20&page=7>; rel="last"
+//
+// The next page is linked with its cursor for cursor pagination. For offset pagination,
+// it is linked while the total is not reached, or while pages are full if it is unknown.
+func WritePage[T any](w http.ResponseWriter, r *http.Request, items []T, meta PageMeta) error {
+	if page, ok := PageRequestFrom(r.Context()); ok {
+		meta.Limit = page.Limit
+		if page.Cursor == "" && meta.NextCursor == "" {
+			meta.Page = page.Page
+		}
+	}
+
+	AddLinks(w, pageLinks(r, meta, len(items))...)
+	return writePage(w, items, meta)
+}
+
+// pageLinks returns the links to the pages surrounding the page described by meta.
+func pageLinks(r *http.Request, meta PageMeta, n int) []Link {
+	if meta.NextCursor != "" {
+		return []Link{QueryLink(r, "next", url.Values{CursorParam: {meta.NextCursor}})}
+	}
+	if meta.Page < 1 || meta.Limit < 1 {
+		return nil
+	}
+
+	link := func(rel string, page int) Link {
+		return QueryLink(r, rel, url.Values{
+			PageParam:  {strconv.Itoa(page)},
+			LimitParam: {strconv.Itoa(meta.Limit)},
+		})
+	}
+
+	last := 0
+	if meta.Total > 0 {
+		last = (meta.Total + meta.Limit - 1) / meta.Limit
+	}
+
+	links := []Link{link("first", 1)}
+	if meta.Page > 1 {
+		links = append(links, link("prev", meta.Page-1))
+	}
+	if (last > 0 && meta.Page < last) || (last == 0 && n == meta.Limit) {
+		links = append(links, link("next", meta.Page+1))
+	}
+	if last > 0 {
+		links = append(links, link("last", last))
+	}
+	return links
+}
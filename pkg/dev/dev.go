@@ -0,0 +1,269 @@
+// Package dev provides a development runner that rebuilds and restarts a godi
+// application whenever its sources change, shortening the edit-run loop.
+//
+// The runner holds the application's listening socket and hands it to every
+// restarted process through godi.ListenerFDEnv, so requests sent while the
+// application reloads wait for the new process instead of being refused.
+//
+//	err := dev.Run(ctx, dev.Config{
+//		Package: "./cmd/api",
+//		Addr:    "localhost:5000",
+//	})
+//
+// The runner is also available as a command:
+//
+//	go run github.com/huboh/godi/cmd/godi-dev -addr localhost:5000 ./cmd/api
+package dev
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Config configures the dev runner.
+type Config struct {
+	// Root is the directory watched for changes. Defaults to the working directory.
+	Root string
+
+	// Package is the main package built and run. Defaults to ".".
+	Package string
+
+	// Args are the arguments the application is run with.
+	Args []string
+
+	// Addr is the address the runner listens on and hands to the application,
+	// which must start its server with Listen. If empty, the application
+	// listens by itself and connections are refused while it restarts.
+	Addr string
+
+	// Exts are the extensions of the files that trigger a rebuild.
+	// Defaults to Go sources, templates, HTML and env files.
+	Exts []string
+
+	// Exclude lists directory names that are not watched, in addition to
+	// hidden directories. Defaults to vendor, bin, node_modules and testdata.
+	Exclude []string
+
+	// Interval is how often the sources are checked for changes. Defaults to 500ms.
+	Interval time.Duration
+
+	// Logger receives the runner's progress. Defaults to a logger on stderr.
+	Logger *log.Logger
+
+	// Stdout and Stderr receive the application's and the compiler's output.
+	// Default to the runner's stdout and stderr.
+	Stdout, Stderr io.Writer
+}
+
+func (c *Config) withDefaults() {
+	c.Root = cmp.Or(c.Root, ".")
+	c.Package = cmp.Or(c.Package, ".")
+	c.Interval = cmp.Or(c.Interval, 500*time.Millisecond)
+
+	if len(c.Exts) == 0 {
+		c.Exts = []string{".go", ".tmpl", ".html", ".env", ".yml", ".yaml", ".json"}
+	}
+	for i, ext := range c.Exts {
+		if !strings.HasPrefix(ext, ".") {
+			c.Exts[i] = "." + ext
+		}
+	}
+	if len(c.Exclude) == 0 {
+		c.Exclude = []string{"vendor", "bin", "node_modules", "testdata"}
+	}
+	if c.Logger == nil {
+		c.Logger = log.New(os.Stderr, "[godi-dev] ", log.LstdFlags)
+	}
+	if c.Stdout == nil {
+		c.Stdout = os.Stdout
+	}
+	if c.Stderr == nil {
+		c.Stderr = os.Stderr
+	}
+}
+
+// Run builds and runs the application, rebuilding and restarting it whenever
+// the watched sources change, until ctx is canceled.
+//
+// A failing build keeps the previous process running, so a typo doesn't take
+// the application down.
+func Run(ctx context.Context, cfg Config) error {
+	cfg.withDefaults()
+
+	listener, err := cfg.listen()
+	if err != nil {
+		return err
+	}
+	if listener != nil {
+		defer listener.Close()
+	}
+
+	tmp, err := os.MkdirTemp("", "godi-dev")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	var (
+		bin     = filepath.Join(tmp, "app")
+		proc    *exec.Cmd
+		last, _ = cfg.snapshot()
+		ticker  = time.NewTicker(cfg.Interval)
+	)
+	defer ticker.Stop()
+
+	restart := func() {
+		if err := cfg.build(ctx, bin); err != nil {
+			cfg.Logger.Printf("build failed: %v", err)
+			return
+		}
+
+		cfg.stop(proc)
+		proc, err = cfg.start(bin, listener)
+		if err != nil {
+			cfg.Logger.Printf("error starting application: %v", err)
+		}
+	}
+
+	restart()
+	for {
+		select {
+		case <-ctx.Done():
+			cfg.stop(proc)
+			return nil
+
+		case <-ticker.C:
+			current, err := cfg.snapshot()
+			if err != nil {
+				cfg.Logger.Printf("error watching sources: %v", err)
+				continue
+			}
+			if current != last {
+				last = current
+				cfg.Logger.Print("change detected, rebuilding")
+				restart()
+			}
+		}
+	}
+}
+
+// listen opens the listener handed to the application, if an address is set.
+func (c *Config) listen() (*os.File, error) {
+	if c.Addr == "" {
+		return nil, nil
+	}
+
+	ln, err := net.Listen("tcp", c.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on (%s): %w", c.Addr, err)
+	}
+	defer ln.Close()
+
+	tcp, ok := ln.(*net.TCPListener)
+	if !ok {
+		return nil, fmt.Errorf("listener on (%s) is not a TCP listener", c.Addr)
+	}
+
+	c.Logger.Printf("listening on (%s)", ln.Addr())
+	return tcp.File()
+}
+
+// snapshot returns a fingerprint of the watched files' names, sizes and modification times.
+func (c *Config) snapshot() (string, error) {
+	var b strings.Builder
+
+	err := filepath.WalkDir(c.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if path != c.Root && (strings.HasPrefix(name, ".") || slices.Contains(c.Exclude, name)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !slices.Contains(c.Exts, filepath.Ext(path)) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&b, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+
+	return b.String(), err
+}
+
+func (c *Config) build(ctx context.Context, bin string) error {
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", bin, c.Package)
+	cmd.Dir = c.Root
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	return cmd.Run()
+}
+
+func (c *Config) start(bin string, listener *os.File) (*exec.Cmd, error) {
+	cmd := exec.Command(bin, c.Args...)
+	cmd.Dir = c.Root
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	cmd.Env = os.Environ()
+
+	if listener != nil {
+		// the first extra file is file descriptor 3 in the child
+		cmd.ExtraFiles = []*os.File{listener}
+		cmd.Env = append(cmd.Env, godi.ListenerFDEnv+"=3")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c.Logger.Printf("started application (pid %d)", cmd.Process.Pid)
+	return cmd, nil
+}
+
+// stop gracefully stops the process, killing it if it doesn't exit in time.
+func (c *Config) stop(cmd *exec.Cmd) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case err := <-done:
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			c.Logger.Printf("error stopping application: %v", err)
+		}
+
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
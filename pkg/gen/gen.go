@@ -0,0 +1,214 @@
+// Package gen scaffolds godi modules, controllers, services and guards following
+// the recommended project layout, where each feature module is a package of its own:
+//
+//	modules/
+//		users/
+//			module.go      the module config
+//			controller.go  the HTTP controller
+//			service.go     the business logic
+//
+// Generated modules are imported into the root module, and generated controllers
+// and services are registered in their module's config, so they are wired as soon
+// as they are created:
+//
+//	g := gen.Generator{Dir: "internal/modules", Root: "internal/app/module.go"}
+//	files, err := g.Module("users")
+//
+// The generator is also available as a command:
+//
+//	go run github.com/huboh/godi/cmd/godi-gen module -dir internal/modules -root internal/app/module.go users
+//
+// [Generator.Stubs] generates stubs of a module's exported providers for its tests.
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// validName matches the names of generated modules, controllers, services and guards.
+var validName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// Generator creates godi components in a project.
+type Generator struct {
+	// Dir is the directory holding the module packages, e.g. "internal/modules".
+	Dir string
+
+	// Root is the file declaring the root module that generated modules are
+	// imported into. Generated modules are not wired if empty.
+	Root string
+
+	// Force overwrites existing files instead of failing.
+	Force bool
+}
+
+// data is the data the templates are executed with.
+type data struct {
+	Package string // package name of the module, e.g. "users".
+	Name    string // exported name of the component, e.g. "Users".
+	Path    string // route pattern of controllers, e.g. "/users".
+	Service string // service type the controller depends on, if any.
+}
+
+func newData(module, name string) data {
+	return data{
+		Package: packageName(module),
+		Name:    exportedName(name),
+		Path:    "/" + strings.ToLower(strings.ReplaceAll(name, "_", "-")),
+	}
+}
+
+// Module creates a module package with a controller and a service, importing
+// it into the root module. It returns the paths of the created files.
+func (g Generator) Module(name string) ([]string, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+
+	var (
+		d     = newData(name, name)
+		dir   = filepath.Join(g.Dir, d.Package)
+		files = map[string]*template.Template{
+			"module.go":     moduleTmpl,
+			"controller.go": controllerTmpl,
+			"service.go":    serviceTmpl,
+		}
+		created []string
+	)
+
+	// the module's main components are named after the package, e.g. users.Service
+	d.Name = ""
+	d.Service = "Service"
+	for _, file := range []string{"module.go", "controller.go", "service.go"} {
+		path := filepath.Join(dir, file)
+		if err := g.write(path, files[file], d); err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+
+	if g.Root == "" {
+		return created, nil
+	}
+
+	importPath, err := importPathOf(dir)
+	if err != nil {
+		return created, fmt.Errorf("error resolving import path of (%s): %w", dir, err)
+	}
+
+	err = addImport(g.Root, "Imports", "&"+d.Package+".Module{}", importPath)
+	if err != nil {
+		return created, fmt.Errorf("error importing module into (%s): %w", g.Root, err)
+	}
+
+	return created, nil
+}
+
+// Controller creates a controller in the module and registers it in the module's config.
+func (g Generator) Controller(module, name string) (string, error) {
+	return g.component(module, name, "controller", controllerTmpl, "ControllersCtors")
+}
+
+// Service creates a service in the module and registers it in the module's config.
+func (g Generator) Service(module, name string) (string, error) {
+	return g.component(module, name, "service", serviceTmpl, "ProvidersCtors")
+}
+
+// Guard creates a guard in the module. Guards are not registered, as they are
+// attached to the controllers and routes they protect.
+func (g Generator) Guard(module, name string) (string, error) {
+	return g.component(module, name, "guard", guardTmpl, "")
+}
+
+func (g Generator) component(module, name, kind string, tmpl *template.Template, field string) (string, error) {
+	if err := checkName(module); err != nil {
+		return "", err
+	}
+	if err := checkName(name); err != nil {
+		return "", err
+	}
+
+	var (
+		d    = newData(module, name)
+		dir  = filepath.Join(g.Dir, d.Package)
+		path = filepath.Join(dir, strings.ToLower(name)+"_"+kind+".go")
+	)
+
+	if err := g.write(path, tmpl, d); err != nil {
+		return "", err
+	}
+
+	if field == "" {
+		return path, nil
+	}
+
+	ctor := "New" + d.Name + exportedName(kind)
+	err := addElement(filepath.Join(dir, "module.go"), field, ctor)
+	if err != nil {
+		return path, fmt.Errorf("error registering %s in module (%s): %w", kind, d.Package, err)
+	}
+
+	return path, nil
+}
+
+// write executes the template and writes the formatted source to path.
+func (g Generator) write(path string, tmpl *template.Template, d data) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("error formatting (%s): %w", path, err)
+	}
+
+	return g.writeSource(path, src)
+}
+
+// writeSource writes the source to path, failing if the file exists unless Force is set.
+func (g Generator) writeSource(path string, src []byte) error {
+	if !g.Force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("file (%s) already exists", path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0o644)
+}
+
+func checkName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid name %q: must start with a letter and contain only letters, digits, '_' and '-'", name)
+	}
+	return nil
+}
+
+// packageName returns the package name for a module name, e.g. "user-profiles" is "userprofiles".
+func packageName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
+
+// exportedName returns the exported Go identifier for a name, e.g. "user-profiles" is "UserProfiles".
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' }) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
@@ -0,0 +1,281 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"maps"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/huboh/godi"
+)
+
+// Stubs creates a stubs_test.go file in the module's package declaring a stub
+// for each provider the module exports, and returns its path. See StubsSource.
+//
+// Stubs are generated from the module's values, so they are created by a program
+// or test importing the module rather than by the godi-gen command:
+//
+//	//go:generate go run ./internal/stubgen
+//
+//	func main() {
+//		g := gen.Generator{Dir: "internal/modules", Force: true}
+//		if _, err := g.Stubs("users", &users.Module{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func (g Generator) Stubs(module string, m godi.Module) (string, error) {
+	if err := checkName(module); err != nil {
+		return "", err
+	}
+
+	src, err := StubsSource(m)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(g.Dir, packageName(module), "stubs_test.go")
+	return path, g.writeSource(path, src)
+}
+
+// StubsSource returns the source of a file in the module's package declaring a stub
+// for each provider the module exports. Stubs have a function field per method,
+// e.g. SendFunc for Send, and return zero values for the methods left unset.
+//
+// Exported interfaces are stubbed directly. Exported concrete types are wrapped
+// in an interface named after them, e.g. ServiceInterface for *Service, which
+// consumers depend on, e.g. through godi.As, so tests can replace the provider
+// with the stub.
+func StubsSource(m godi.Module) ([]byte, error) {
+	mt := reflect.TypeOf(m)
+	for mt.Kind() == reflect.Pointer {
+		mt = mt.Elem()
+	}
+
+	s := &stubWriter{
+		pkgPath: mt.PkgPath(),
+		imports: map[string]string{},
+	}
+
+	for _, t := range godi.ExportedTypes(m) {
+		if err := s.writeStub(t); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by godi-gen. DO NOT EDIT.\n\npackage %s\n\n", packageName(path.Base(s.pkgPath)))
+	if len(s.imports) > 0 {
+		buf.WriteString("import (\n")
+		for _, p := range slices.Sorted(maps.Keys(s.imports)) {
+			if name := s.imports[p]; name != path.Base(p) {
+				fmt.Fprintf(&buf, "\t%s %q\n", name, p)
+			} else {
+				fmt.Fprintf(&buf, "\t%q\n", p)
+			}
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.Write(s.body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting stubs: %w", err)
+	}
+	return src, nil
+}
+
+// stubWriter writes the stubs of a package, recording the packages they import.
+type stubWriter struct {
+	pkgPath string
+	imports map[string]string // import path to name
+	body    bytes.Buffer
+}
+
+// writeStub writes the stub of t, and the interface it implements if t is concrete.
+// Unnamed types and types without methods are skipped.
+func (s *stubWriter) writeStub(t reflect.Type) error {
+	named := t
+	if named.Kind() == reflect.Pointer {
+		named = named.Elem()
+	}
+	if named.Name() == "" || t.NumMethod() == 0 {
+		return nil
+	}
+	if strings.Contains(named.Name(), "[") {
+		return fmt.Errorf("cannot stub generic type (%s)", t)
+	}
+
+	var (
+		name    = named.Name()
+		methods []stubMethod
+	)
+
+	for i := range t.NumMethod() {
+		method := t.Method(i)
+		sig := method.Type
+		if t.Kind() != reflect.Interface {
+			sig = dropReceiver(sig)
+		}
+		methods = append(methods, stubMethod{name: method.Name, sig: sig})
+	}
+
+	iface := s.typeString(t)
+	if t.Kind() != reflect.Interface {
+		iface = name + "Interface"
+
+		fmt.Fprintf(&s.body, "// %s is the interface of %s, implemented by %sStub.\n", iface, s.typeString(t), name)
+		fmt.Fprintf(&s.body, "type %s interface {\n", iface)
+		for _, m := range methods {
+			fmt.Fprintf(&s.body, "\t%s%s\n", m.name, s.signature(m.sig, false))
+		}
+		s.body.WriteString("}\n\n")
+
+		if t.Kind() == reflect.Pointer {
+			fmt.Fprintf(&s.body, "var _ %s = (%s)(nil)\n\n", iface, s.typeString(t))
+		}
+	}
+
+	fmt.Fprintf(&s.body, "// %sStub is a stub of %s whose methods call the matching function fields.\n", name, s.typeString(t))
+	fmt.Fprintf(&s.body, "type %sStub struct {\n", name)
+	for _, m := range methods {
+		fmt.Fprintf(&s.body, "\t%sFunc func%s\n", m.name, s.signature(m.sig, false))
+	}
+	s.body.WriteString("}\n\n")
+	fmt.Fprintf(&s.body, "var _ %s = (*%sStub)(nil)\n\n", iface, name)
+
+	for _, m := range methods {
+		call := m.name + "Func(" + callArgs(m.sig) + ")"
+
+		fmt.Fprintf(&s.body, "func (s *%sStub) %s%s {\n", name, m.name, s.signature(m.sig, true))
+		fmt.Fprintf(&s.body, "\tif s.%sFunc != nil {\n", m.name)
+		if m.sig.NumOut() > 0 {
+			fmt.Fprintf(&s.body, "\t\treturn s.%s\n\t}\n\treturn\n", call)
+		} else {
+			fmt.Fprintf(&s.body, "\t\ts.%s\n\t}\n", call)
+		}
+		s.body.WriteString("}\n\n")
+	}
+
+	return nil
+}
+
+// stubMethod is a method of a stubbed type.
+type stubMethod struct {
+	name string
+	sig  reflect.Type
+}
+
+// signature returns the parameters and results of the function type sig, with
+// named parameters and results if named is set.
+func (s *stubWriter) signature(sig reflect.Type, named bool) string {
+	var in, out []string
+	for i := range sig.NumIn() {
+		param := s.typeString(sig.In(i))
+		if sig.IsVariadic() && i == sig.NumIn()-1 {
+			param = "..." + s.typeString(sig.In(i).Elem())
+		}
+		if named {
+			param = "p" + strconv.Itoa(i) + " " + param
+		}
+		in = append(in, param)
+	}
+	for i := range sig.NumOut() {
+		result := s.typeString(sig.Out(i))
+		if named {
+			result = "r" + strconv.Itoa(i) + " " + result
+		}
+		out = append(out, result)
+	}
+
+	switch {
+	case len(out) == 0:
+		return "(" + strings.Join(in, ", ") + ")"
+	case len(out) == 1 && !named:
+		return "(" + strings.Join(in, ", ") + ") " + out[0]
+	default:
+		return "(" + strings.Join(in, ", ") + ") (" + strings.Join(out, ", ") + ")"
+	}
+}
+
+// typeString returns the Go syntax of t, importing the packages it refers to.
+func (s *stubWriter) typeString(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || t.PkgPath() == s.pkgPath {
+			return t.Name()
+		}
+		return s.importName(t.PkgPath()) + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + s.typeString(t.Elem())
+	case reflect.Slice:
+		return "[]" + s.typeString(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + s.typeString(t.Elem())
+	case reflect.Map:
+		return "map[" + s.typeString(t.Key()) + "]" + s.typeString(t.Elem())
+	case reflect.Chan:
+		prefix := map[reflect.ChanDir]string{reflect.RecvDir: "<-chan ", reflect.SendDir: "chan<- ", reflect.BothDir: "chan "}[t.ChanDir()]
+		return prefix + s.typeString(t.Elem())
+	case reflect.Func:
+		return "func" + s.signature(t, false)
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return "struct{}"
+		}
+	}
+	return t.String()
+}
+
+// importName returns the name the package is imported as, adding it to the imports.
+func (s *stubWriter) importName(pkgPath string) string {
+	if name, ok := s.imports[pkgPath]; ok {
+		return name
+	}
+
+	base := packageName(strings.Split(path.Base(pkgPath), ".")[0])
+	name := base
+	for i := 2; slices.Contains(slices.Collect(maps.Values(s.imports)), name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	s.imports[pkgPath] = name
+	return name
+}
+
+// dropReceiver returns the type of a method without its receiver parameter.
+func dropReceiver(sig reflect.Type) reflect.Type {
+	in := make([]reflect.Type, 0, sig.NumIn()-1)
+	for i := 1; i < sig.NumIn(); i++ {
+		in = append(in, sig.In(i))
+	}
+
+	out := make([]reflect.Type, 0, sig.NumOut())
+	for i := range sig.NumOut() {
+		out = append(out, sig.Out(i))
+	}
+	return reflect.FuncOf(in, out, sig.IsVariadic())
+}
+
+// callArgs returns the arguments forwarding the named parameters of sig.
+func callArgs(sig reflect.Type) string {
+	args := make([]string, sig.NumIn())
+	for i := range args {
+		args[i] = "p" + strconv.Itoa(i)
+	}
+	if sig.IsVariadic() {
+		args[len(args)-1] += "..."
+	}
+	return strings.Join(args, ", ")
+}
@@ -0,0 +1,85 @@
+package gen
+
+import "text/template"
+
+var moduleTmpl = template.Must(template.New("module").Parse(`package {{.Package}}
+
+import "github.com/huboh/godi"
+
+// Module provides the {{.Package}} feature.
+type Module struct{}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		ProvidersCtors:   []godi.ProviderConstructor{NewService},
+		ControllersCtors: []godi.ControllerConstructor{NewController},
+	}
+}
+`))
+
+var controllerTmpl = template.Must(template.New("controller").Parse(`package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/huboh/godi"
+)
+
+// {{.Name}}Controller handles the {{.Path}} endpoints.
+type {{.Name}}Controller struct {
+{{- if .Service}}
+	service *{{.Service}}
+{{- end}}
+}
+
+func New{{.Name}}Controller({{if .Service}}s *{{.Service}}{{end}}) *{{.Name}}Controller {
+	return &{{.Name}}Controller{
+{{- if .Service}}
+		service: s,
+{{- end}}
+	}
+}
+
+func (c *{{.Name}}Controller) Config() *godi.ControllerConfig {
+	return &godi.ControllerConfig{
+		Pattern: "{{.Path}}",
+		RoutesCfgs: []*godi.RouteConfig{
+			{
+				Method:  http.MethodGet,
+				Pattern: "/",
+				Handler: http.HandlerFunc(c.handleList),
+			},
+		},
+	}
+}
+
+func (c *{{.Name}}Controller) handleList(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+`))
+
+var serviceTmpl = template.Must(template.New("service").Parse(`package {{.Package}}
+
+// {{.Name}}Service implements the {{.Package}} business logic.
+type {{.Name}}Service struct{}
+
+func New{{.Name}}Service() *{{.Name}}Service {
+	return &{{.Name}}Service{}
+}
+`))
+
+var guardTmpl = template.Must(template.New("guard").Parse(`package {{.Package}}
+
+import "github.com/huboh/godi"
+
+// {{.Name}}Guard decides whether requests may reach the routes it protects.
+type {{.Name}}Guard struct{}
+
+func New{{.Name}}Guard() *{{.Name}}Guard {
+	return &{{.Name}}Guard{}
+}
+
+func (g *{{.Name}}Guard) Allow(gCtx godi.GuardContext) (bool, error) {
+	return true, nil
+}
+`))
@@ -0,0 +1,252 @@
+package gen
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// fieldTypes are the element types of the ModuleConfig fields components are registered in.
+var fieldTypes = map[string]string{
+	"Imports":          "godi.Module",
+	"ProvidersCtors":   "godi.ProviderConstructor",
+	"ControllersCtors": "godi.ControllerConstructor",
+}
+
+// edit is a text insertion at a byte offset of a source file.
+type edit struct {
+	offset int
+	text   string
+}
+
+// addElement appends expr to the field of the ModuleConfig returned by the
+// Config method declared in the file.
+func addElement(path, field, expr string) error {
+	return addImport(path, field, expr, "")
+}
+
+// addImport appends expr to the field of the ModuleConfig returned by the
+// Config method declared in the file, importing importPath if set.
+func addImport(path, field, expr, importPath string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	lit := findModuleConfig(file)
+	if lit == nil {
+		return errors.New("no Config method returning a godi.ModuleConfig literal found")
+	}
+
+	var edits []edit
+	if e, err := fieldEdit(fset, lit, field, expr); err != nil {
+		return err
+	} else if e != nil {
+		edits = append(edits, *e)
+	}
+
+	if importPath != "" && !hasImport(file, importPath) {
+		edits = append(edits, importEdits(fset, file, importPath)...)
+	}
+
+	// apply the edits from the end of the file so offsets stay valid
+	slices.SortFunc(edits, func(a, b edit) int { return b.offset - a.offset })
+	for _, e := range edits {
+		src = slices.Concat(src[:e.offset], []byte(e.text), src[e.offset:])
+	}
+
+	out, err := format.Source(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o644)
+}
+
+// findModuleConfig returns the godi.ModuleConfig literal in the file's Config method.
+func findModuleConfig(file *ast.File) *ast.CompositeLit {
+	var lit *ast.CompositeLit
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != "Config" || fn.Body == nil {
+			continue
+		}
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			cl, ok := n.(*ast.CompositeLit)
+			if !ok || lit != nil {
+				return lit == nil
+			}
+			if sel, ok := cl.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "ModuleConfig" {
+				lit = cl
+				return false
+			}
+			return true
+		})
+	}
+
+	return lit
+}
+
+// fieldEdit returns the insertion appending expr to the field of the literal,
+// adding the field if it is missing. Nothing is inserted if expr is already listed.
+func fieldEdit(fset *token.FileSet, lit *ast.CompositeLit, field, expr string) (*edit, error) {
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != field {
+			continue
+		}
+
+		list, ok := kv.Value.(*ast.CompositeLit)
+		if !ok {
+			return nil, fmt.Errorf("field %s is not a slice literal", field)
+		}
+
+		for _, e := range list.Elts {
+			if exprString(e) == expr {
+				return nil, nil
+			}
+		}
+
+		text := expr
+		if n := len(list.Elts); n > 0 {
+			last := list.Elts[n-1]
+			if fset.Position(last.End()).Line != fset.Position(list.Rbrace).Line {
+				// multi-line lists end with a comma already
+				return &edit{offset: offset(list.Rbrace), text: expr + ",\n"}, nil
+			}
+			text = ", " + expr
+		}
+		return &edit{offset: offset(list.Rbrace), text: text}, nil
+	}
+
+	typ, ok := fieldTypes[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %s", field)
+	}
+
+	text := fmt.Sprintf("\n%s: []%s{%s},\n", field, typ, expr)
+	if n := len(lit.Elts); n > 0 && fset.Position(lit.Elts[n-1].End()).Line == fset.Position(lit.Rbrace).Line {
+		text = "," + text
+	}
+	return &edit{offset: offset(lit.Rbrace), text: text}, nil
+}
+
+// importEdits returns the insertions importing the path.
+func importEdits(fset *token.FileSet, file *ast.File, importPath string) []edit {
+	var (
+		spec   = strconv.Quote(importPath)
+		offset = func(p token.Pos) int { return fset.Position(p).Offset }
+	)
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Rparen.IsValid() {
+			return []edit{{offset: offset(gen.Rparen), text: spec + "\n"}}
+		}
+
+		// turn the single import into a group
+		return []edit{
+			{offset: offset(gen.Specs[0].Pos()), text: "("},
+			{offset: offset(gen.Specs[0].End()), text: "\n" + spec + "\n)"},
+		}
+	}
+
+	return []edit{{offset: offset(file.Name.End()), text: "\n\nimport " + spec}}
+}
+
+func hasImport(file *ast.File, importPath string) bool {
+	for _, imp := range file.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == importPath {
+			return true
+		}
+	}
+	return false
+}
+
+// exprString returns the source of simple expressions used as config elements.
+func exprString(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.UnaryExpr:
+		return e.Op.String() + exprString(e.X)
+	case *ast.CompositeLit:
+		return exprString(e.Type) + "{}"
+	}
+	return ""
+}
+
+// importPathOf returns the import path of the package in dir, derived from the
+// module path declared in the nearest go.mod.
+func importPathOf(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		modPath, err := modulePath(filepath.Join(root, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return modPath, nil
+			}
+			return modPath + "/" + filepath.ToSlash(rel), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if filepath.Dir(root) == root {
+			return "", errors.New("no go.mod found")
+		}
+	}
+}
+
+// modulePath returns the module path declared in the go.mod file.
+func modulePath(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "module"); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no module directive in (%s)", path)
+}
@@ -0,0 +1,115 @@
+package goditest
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Benchmark drives requests returned by newRequest through the application's
+// handler in memory, without sockets, reporting the allocations and latency per
+// request along with the time spent per request in each guard, interceptor and handler,
+// e.g. "guard:*auth.Guard-ns/op".
+//
+// Concurrency is the number of goroutines sending requests per GOMAXPROCS, as in
+// testing.B.SetParallelism. The app is built with the options and a layer observer.
+//
+//	func BenchmarkGetUser(b *testing.B) {
+//		goditest.Benchmark(b, &app.Module{}, 4, func() *http.Request {
+//			return httptest.NewRequest(http.MethodGet, "/users/1", nil)
+//		})
+//	}
+//
+// Interceptor latencies include the interceptors and handler they wrap, and responses
+// with a server error status fail the benchmark.
+func Benchmark(b *testing.B, module godi.Module, concurrency int, newRequest func() *http.Request, opts ...godi.Option) {
+	b.Helper()
+
+	var layers sync.Map // layer name to *layerStats
+
+	observe := func(r *http.Request, layer godi.Layer, name string, d time.Duration) {
+		v, ok := layers.Load(string(layer) + ":" + name)
+		if !ok {
+			v, _ = layers.LoadOrStore(string(layer)+":"+name, &layerStats{})
+		}
+		v.(*layerStats).add(d)
+	}
+
+	app, err := godi.New(module, append(opts, godi.WithLayerObserver(observe))...)
+	if err != nil {
+		b.Fatalf("error creating app: %v", err)
+	}
+
+	var (
+		handler = app.Handler()
+		failed  atomic.Int64
+	)
+
+	b.ReportAllocs()
+	b.SetParallelism(max(concurrency, 1))
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		w := &discardWriter{header: http.Header{}}
+		for pb.Next() {
+			clear(w.header)
+			w.status = 0
+
+			handler.ServeHTTP(w, newRequest())
+			if w.status >= http.StatusInternalServerError {
+				failed.Add(1)
+			}
+		}
+	})
+
+	b.StopTimer()
+
+	if n := failed.Load(); n > 0 {
+		b.Errorf("%d requests failed with a server error", n)
+	}
+
+	stats := map[string]*layerStats{}
+	layers.Range(func(k, v any) bool {
+		stats[k.(string)] = v.(*layerStats)
+		return true
+	})
+	for _, name := range slices.Sorted(maps.Keys(stats)) {
+		b.ReportMetric(float64(stats[name].total.Load())/float64(b.N), name+"-ns/op")
+	}
+}
+
+// layerStats accumulates the time spent in a layer of the request pipeline.
+type layerStats struct {
+	total atomic.Int64
+}
+
+func (s *layerStats) add(d time.Duration) {
+	s.total.Add(int64(d))
+}
+
+// discardWriter is a ResponseWriter discarding the response body.
+type discardWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(p), nil
+}
@@ -0,0 +1,256 @@
+package goditest
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huboh/godi/pkg/modules/lock"
+)
+
+// ContainerRequest describes a Docker container started for a test.
+type ContainerRequest struct {
+	// Image is the image the container runs, e.g. "redis:7".
+	Image string
+
+	// Port is the container port published on the loopback interface, e.g. "6379/tcp".
+	Port string
+
+	// HostPort is the host port the container port is published on.
+	// Defaults to a port chosen by Docker.
+	HostPort string
+
+	// Env holds the environment variables set in the container.
+	Env map[string]string
+
+	// Cmd overrides the image's command, if set.
+	Cmd []string
+
+	// WaitLog is a line the container logs once it accepts connections. The container is
+	// considered ready once its port accepts connections if empty.
+	WaitLog string
+
+	// WaitOccurrences is the number of times WaitLog must be logged, e.g. 2 for Postgres,
+	// which restarts once it is initialized. Defaults to 1.
+	WaitOccurrences int
+
+	// Timeout bounds the time the container takes to be ready. Defaults to one minute.
+	Timeout time.Duration
+}
+
+// Container is a Docker container started for a test.
+type Container struct {
+	// ID is the ID of the container.
+	ID string
+
+	// Host and Port are the address the container port is published on.
+	Host string
+	Port string
+}
+
+// Addr returns the host and port the container port is published on.
+func (c *Container) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// StartContainer starts a container with the docker command and waits until it is ready,
+// removing it once the test and its cleanup functions, such as those shutting the test's
+// app down, complete.
+//
+// The test is skipped if docker is not installed, so tests depending on containers
+// still pass on machines without Docker.
+func StartContainer(t testing.TB, req ContainerRequest) *Container {
+	t.Helper()
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("goditest: docker is not available")
+	}
+
+	args := []string{"run", "--detach", "--publish", "127.0.0.1:" + req.HostPort + ":" + req.Port}
+	for k, v := range req.Env {
+		args = append(args, "--env", k+"="+v)
+	}
+	args = append(append(args, req.Image), req.Cmd...)
+
+	out, err := docker(args...)
+	if err != nil {
+		t.Fatalf("goditest: error starting container (%s): %v", req.Image, err)
+	}
+
+	c := &Container{ID: out}
+	t.Cleanup(func() {
+		_, err := docker("rm", "--force", "--volumes", c.ID)
+		if err != nil {
+			t.Errorf("goditest: error removing container (%s): %v", req.Image, err)
+		}
+	})
+
+	out, err = docker("port", c.ID, req.Port)
+	if err != nil {
+		t.Fatalf("goditest: error reading port of container (%s): %v", req.Image, err)
+	}
+
+	// the first line holds the IPv4 binding, e.g. "127.0.0.1:49153"
+	c.Host, c.Port, err = net.SplitHostPort(strings.TrimSpace(strings.SplitN(out, "\n", 2)[0]))
+	if err != nil {
+		t.Fatalf("goditest: invalid port of container (%s): %v", req.Image, err)
+	}
+
+	err = c.wait(req)
+	if err != nil {
+		t.Fatalf("goditest: container (%s) is not ready: %v", req.Image, err)
+	}
+
+	return c
+}
+
+// wait polls the container until it logs the request's WaitLog, or its port accepts connections.
+func (c *Container) wait(req ContainerRequest) error {
+	deadline := time.Now().Add(cmp.Or(req.Timeout, time.Minute))
+	for {
+		err := c.ready(req)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+func (c *Container) ready(req ContainerRequest) error {
+	if req.WaitLog == "" {
+		conn, err := net.DialTimeout("tcp", c.Addr(), time.Second)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	// the logs of the container are written to both stdout and stderr
+	out, err := exec.Command("docker", "logs", c.ID).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+
+	if n := strings.Count(string(out), req.WaitLog); n < cmp.Or(req.WaitOccurrences, 1) {
+		return fmt.Errorf("waiting for log (%s), seen %d times", req.WaitLog, n)
+	}
+	return nil
+}
+
+func docker(args ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("docker", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Postgres starts a Postgres container for the test, returning the DSN of its
+// "godi" database, e.g. to open the *sql.DB provided to the outbox module:
+//
+//	db, err := sql.Open("pgx", goditest.Postgres(t))
+func Postgres(t testing.TB) string {
+	t.Helper()
+
+	c := StartContainer(t, ContainerRequest{
+		Image: "postgres:16-alpine",
+		Port:  "5432/tcp",
+		Env: map[string]string{
+			"POSTGRES_USER":     "godi",
+			"POSTGRES_PASSWORD": "godi",
+			"POSTGRES_DB":       "godi",
+		},
+		WaitLog:         "database system is ready to accept connections",
+		WaitOccurrences: 2,
+	})
+
+	return "postgres://godi:godi@" + c.Addr() + "/godi?sslmode=disable"
+}
+
+// Redis starts a Redis container for the test, returning a locker connected to it
+// for the lock module:
+//
+//	Imports: []godi.Module{&lock.Module{Locker: goditest.Redis(t)}}
+func Redis(t testing.TB) *lock.Redis {
+	t.Helper()
+
+	c := StartContainer(t, ContainerRequest{
+		Image: "redis:7-alpine",
+		Port:  "6379/tcp",
+	})
+
+	r := &lock.Redis{Addr: c.Addr()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for {
+		err := r.Ping(ctx)
+		if err == nil {
+			return r
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("goditest: redis container is not ready: %v", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// Kafka starts a single-node Kafka container for the test, returning the address
+// of its broker.
+//
+// The broker advertises the host port it is published on, which is chosen before the
+// container starts, so clients outside the container can connect to it.
+func Kafka(t testing.TB) string {
+	t.Helper()
+
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("goditest: error choosing a port for kafka: %v", err)
+	}
+
+	c := StartContainer(t, ContainerRequest{
+		Image:    "apache/kafka:3.8.0",
+		Port:     "9092/tcp",
+		HostPort: port,
+		Env: map[string]string{
+			"KAFKA_NODE_ID":                          "1",
+			"KAFKA_PROCESS_ROLES":                    "broker,controller",
+			"KAFKA_LISTENERS":                        "PLAINTEXT://:9092,CONTROLLER://:9093",
+			"KAFKA_ADVERTISED_LISTENERS":             "PLAINTEXT://127.0.0.1:" + port,
+			"KAFKA_CONTROLLER_LISTENER_NAMES":        "CONTROLLER",
+			"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP":   "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+			"KAFKA_CONTROLLER_QUORUM_VOTERS":         "1@localhost:9093",
+			"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR": "1",
+		},
+		WaitLog: "Kafka Server started",
+	})
+
+	return c.Addr()
+}
+
+// freePort returns a loopback port that is free at the time of the call.
+func freePort() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer ln.Close()
+
+	_, port, err := net.SplitHostPort(ln.Addr().String())
+	return port, err
+}
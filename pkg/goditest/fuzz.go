@@ -0,0 +1,279 @@
+package goditest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/huboh/godi"
+)
+
+// FuzzRoutes sends malformed and boundary-value requests, generated from the schemas
+// returned by App.Schemas, to each typed route of the application, failing the test
+// for every request whose handling panics or is responded to with a server error.
+//
+// Each route receives its path wildcards replaced by boundary values, e.g. "-1", a
+// 4 KiB segment or an escaped NUL byte, and request bodies that are empty, malformed
+// JSON, of the wrong type, missing a required property, or with a property set to a
+// boundary value or a value of the wrong type. Handlers are expected to reject those
+// with a client error instead of failing on them.
+//
+//	func TestRoutesFuzz(t *testing.T) {
+//		app, err := godi.New(&app.Module{})
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		goditest.FuzzRoutes(t, app, func(r *http.Request) {
+//			r.Header.Set("Authorization", "Bearer "+testToken)
+//		})
+//	}
+//
+// prepare, if not nil, is called with every request before it is sent, e.g. to
+// authenticate it so requests reach handlers instead of being rejected by guards.
+// Routes restricted to a host are not reached, as their schemas have no host.
+func FuzzRoutes(t testing.TB, app *godi.App, prepare func(r *http.Request)) {
+	t.Helper()
+
+	handler := app.Handler()
+	for _, route := range app.Schemas() {
+		for _, c := range fuzzCases(route) {
+			for _, method := range routeMethods(route) {
+				r := httptest.NewRequest(method, c.path, bytes.NewReader(c.body))
+				if c.body != nil {
+					r.Header.Set("Content-Type", cmp.Or(c.contentType, "application/json"))
+				}
+				if prepare != nil {
+					prepare(r)
+				}
+
+				status, panicked := serveFuzzCase(handler, r)
+				switch {
+				case panicked != nil:
+					t.Errorf("%s %s: %s: handler panicked: %v", method, route.Path, c.name, panicked)
+				case status >= http.StatusInternalServerError:
+					t.Errorf("%s %s: %s: responded with %d", method, route.Path, c.name, status)
+				}
+			}
+		}
+	}
+}
+
+// serveFuzzCase serves the request, returning the response status or the value
+// handling it panicked with.
+func serveFuzzCase(handler http.Handler, r *http.Request) (status int, panicked any) {
+	defer func() {
+		panicked = recover()
+	}()
+
+	w := &discardWriter{header: http.Header{}}
+	handler.ServeHTTP(w, r)
+	return cmp.Or(w.status, http.StatusOK), nil
+}
+
+// fuzzCase is a request generated for a route.
+type fuzzCase struct {
+	name        string
+	path        string
+	body        []byte // nil for requests without a body.
+	contentType string // defaults to application/json for requests with a body.
+}
+
+// wildcardPattern matches the wildcards of route paths, e.g. "{id}" or "{path...}".
+var wildcardPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// boundaryWildcards are the values substituted for path wildcards.
+var boundaryWildcards = []string{
+	"0",
+	"-1",
+	"9223372036854775808",
+	"1e309",
+	"null",
+	"%00",
+	"%25",
+	"..",
+	"%E2%82%AC",
+	strings.Repeat("a", 4<<10),
+}
+
+// fuzzCases returns the requests generated for the route: one per boundary value of
+// its path wildcards, and one per malformed or boundary-value body if it takes one.
+func fuzzCases(route godi.RouteSchema) []fuzzCase {
+	var (
+		cases []fuzzCase
+		path  = wildcardPattern.ReplaceAllString(route.Path, "1")
+		valid []byte
+	)
+
+	if route.Request != nil {
+		valid = mustMarshal(exampleValue(route.Request, route.Request, 0))
+	}
+
+	if wildcardPattern.MatchString(route.Path) {
+		for _, v := range boundaryWildcards {
+			cases = append(cases, fuzzCase{
+				name: fmt.Sprintf("path %.16q", v),
+				path: wildcardPattern.ReplaceAllString(route.Path, v),
+				body: valid,
+			})
+		}
+	}
+
+	if route.Request == nil {
+		return append(cases, fuzzCase{name: "no body", path: path})
+	}
+
+	for _, b := range []struct{ name, body string }{
+		{"empty body", ""},
+		{"null body", "null"},
+		{"truncated body", strings.TrimSuffix(string(valid), "}")},
+		{"malformed body", "{\"\x00"},
+		{"array body", "[]"},
+		{"string body", `"x"`},
+		{"number body", "1e309"},
+		{"trailing data", string(valid) + "{}"},
+		{"deeply nested body", strings.Repeat("[", 10001) + strings.Repeat("]", 10001)},
+	} {
+		cases = append(cases, fuzzCase{name: b.name, path: path, body: []byte(b.body)})
+	}
+
+	cases = append(cases, fuzzCase{
+		name:        "unsupported content type",
+		path:        path,
+		body:        valid,
+		contentType: "application/x-godi-fuzz",
+	})
+
+	root := resolveSchema(route.Request, route.Request)
+	for _, name := range root.Required {
+		obj, ok := exampleValue(route.Request, root, 0).(map[string]any)
+		if !ok {
+			break
+		}
+		delete(obj, name)
+		cases = append(cases, fuzzCase{
+			name: fmt.Sprintf("missing %q", name),
+			path: path,
+			body: mustMarshal(obj),
+		})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(root.Properties)) {
+		for _, v := range boundaryValues(resolveSchema(route.Request, root.Properties[name])) {
+			obj, ok := exampleValue(route.Request, root, 0).(map[string]any)
+			if !ok {
+				break
+			}
+			obj[name] = v
+			cases = append(cases, fuzzCase{
+				name: fmt.Sprintf("property %q set to %.24s", name, mustMarshal(v)),
+				path: path,
+				body: mustMarshal(obj),
+			})
+		}
+	}
+
+	return cases
+}
+
+// boundaryValues returns the boundary values of the schema's type and values of
+// other types.
+func boundaryValues(s *godi.Schema) []any {
+	var (
+		long   = strings.Repeat("a", 64<<10)
+		values []any
+	)
+
+	switch s.Type {
+	case "integer":
+		values = []any{0, -1, json.Number("9223372036854775807"), json.Number("-9223372036854775808"), json.Number("18446744073709551616"), 1.5}
+	case "number":
+		values = []any{0, -1, json.Number("1.7976931348623157e308"), json.Number("-1.7976931348623157e308"), json.Number("5e-324")}
+	case "string":
+		values = []any{"", long, "\x00", "\u202e\uffff", "' OR 1=1 --", "not base64!", "9999-99-99T99:99:99Z"}
+	case "array":
+		values = []any{[]any{}, []any{nil}, make([]any, 10000)}
+	case "object":
+		values = []any{map[string]any{}, map[string]any{long: nil}}
+	}
+
+	// values of other types
+	return append(values, nil, true, json.Number("-1"), "x", []any{"x"}, map[string]any{"x": "x"})
+}
+
+// exampleValue returns a valid value of the schema, defined in root, with the
+// required properties of objects set. Recursive definitions end with null.
+func exampleValue(root, s *godi.Schema, depth int) any {
+	s = resolveSchema(root, s)
+	if depth > 8 {
+		return nil
+	}
+
+	switch s.Type {
+	case "boolean":
+		return true
+	case "integer", "number":
+		return 1
+	case "string":
+		switch {
+		case s.Format == "date-time":
+			return "2006-01-02T15:04:05Z"
+		case s.ContentEncoding == "base64":
+			return "eA=="
+		}
+		return "x"
+	case "array":
+		if s.Items == nil {
+			return []any{}
+		}
+		return []any{exampleValue(root, s.Items, depth+1)}
+	case "object":
+		obj := map[string]any{}
+		for _, name := range s.Required {
+			obj[name] = exampleValue(root, s.Properties[name], depth+1)
+		}
+		return obj
+	}
+	return nil
+}
+
+// resolveSchema returns the definition of root s refers to, or s if it is not a reference.
+func resolveSchema(root, s *godi.Schema) *godi.Schema {
+	if s == nil {
+		return &godi.Schema{}
+	}
+	if name, ok := strings.CutPrefix(s.Ref, "#/$defs/"); ok {
+		if def := root.Defs[name]; def != nil {
+			return def
+		}
+	}
+	return s
+}
+
+// routeMethods returns the methods the route is requested with.
+func routeMethods(route godi.RouteSchema) []string {
+	switch {
+	case len(route.Methods) > 0:
+		return route.Methods
+	case route.Method != "":
+		return []string{route.Method}
+	case route.Request != nil:
+		return []string{http.MethodPost}
+	}
+	return []string{http.MethodGet}
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("goditest: error encoding fuzz body: %v", err))
+	}
+	return data
+}
@@ -0,0 +1,180 @@
+// Package goditest provides helpers for testing godi applications, such as
+// snapshotting their route table, benchmarking their request pipeline and
+// fuzzing their routes with requests generated from their schemas.
+package goditest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/huboh/godi"
+)
+
+// UpdateEnv is the environment variable that, when set to a non-empty value, makes
+// SnapshotRoutes write the current route table to the golden file instead of comparing:
+//
+//	GODI_UPDATE_SNAPSHOTS=1 go test ./...
+const UpdateEnv = "GODI_UPDATE_SNAPSHOTS"
+
+// SnapshotRoutes compares the application's route table with the golden file
+// testdata/<test name>.routes.golden, failing the test if a route was added,
+// removed or changed, e.g. its methods, guards or metadata. It catches accidental
+// route changes, which are then reviewed through the golden file's diff.
+//
+//	func TestRoutes(t *testing.T) {
+//		app, err := godi.New(&app.Module{})
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		goditest.SnapshotRoutes(t, app)
+//	}
+//
+// The golden file is created on the first run and rewritten when UpdateEnv is set.
+func SnapshotRoutes(t testing.TB, app *godi.App) {
+	t.Helper()
+
+	var (
+		current = RouteTable(app)
+		name    = strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+		path    = filepath.Join("testdata", name+".routes.golden")
+	)
+
+	golden, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || os.Getenv(UpdateEnv) != "" {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(current), 0o644)
+		}
+		if err != nil {
+			t.Fatalf("error writing route snapshot (%s): %v", path, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("error reading route snapshot (%s): %v", path, err)
+	}
+
+	if diff := diffRoutes(string(golden), current); diff != "" {
+		t.Errorf("routes differ from snapshot (%s), run with %s=1 to update it:\n%s", path, UpdateEnv, diff)
+	}
+}
+
+// RouteTable returns the application's route table in the text format of
+// route snapshots, with a block of lines per route sorted by pattern.
+func RouteTable(app *godi.App) string {
+	var blocks []string
+	walkRoutes(app.ModuleTree(), func(module string, c godi.ControllerNode, r godi.RouteNode) {
+		var (
+			b       strings.Builder
+			methods = cmp.Or(strings.Join(r.Methods, ","), r.Method, "*")
+		)
+
+		fmt.Fprintf(&b, "%s %s%s\n", methods, r.Host, r.Path)
+		fmt.Fprintf(&b, "  module: %s\n", module)
+		fmt.Fprintf(&b, "  controller: %s\n", c.Controller)
+		if r.Name != "" {
+			fmt.Fprintf(&b, "  name: %s\n", r.Name)
+		}
+		if len(r.Guards) > 0 {
+			fmt.Fprintf(&b, "  guards: %s\n", strings.Join(r.Guards, ", "))
+		}
+		if len(r.Consumes) > 0 {
+			fmt.Fprintf(&b, "  consumes: %s\n", strings.Join(r.Consumes, ", "))
+		}
+		if len(r.Produces) > 0 {
+			fmt.Fprintf(&b, "  produces: %s\n", strings.Join(r.Produces, ", "))
+		}
+		if r.Metadata != "" {
+			fmt.Fprintf(&b, "  metadata: %s\n", r.Metadata)
+		}
+		blocks = append(blocks, b.String())
+	})
+
+	slices.Sort(blocks)
+	return strings.Join(blocks, "")
+}
+
+func walkRoutes(m godi.ModuleNode, fn func(module string, c godi.ControllerNode, r godi.RouteNode)) {
+	for _, c := range m.Controllers {
+		for _, r := range c.Routes {
+			fn(m.Module, c, r)
+		}
+	}
+	for _, imported := range m.Imports {
+		walkRoutes(imported, fn)
+	}
+}
+
+// diffRoutes returns the routes removed from, added to and changed in the
+// golden route table, or an empty string if the tables are equal.
+func diffRoutes(golden, current string) string {
+	if golden == current {
+		return ""
+	}
+
+	var (
+		b    strings.Builder
+		want = routeBlocks(golden)
+		got  = routeBlocks(current)
+	)
+
+	routes := slices.Collect(maps.Keys(want))
+	for route := range got {
+		if _, ok := want[route]; !ok {
+			routes = append(routes, route)
+		}
+	}
+	slices.Sort(routes)
+
+	for _, route := range routes {
+		w, inWant := want[route]
+		g, inGot := got[route]
+
+		switch {
+		case !inGot:
+			fmt.Fprintf(&b, "removed route:\n%s", prefixLines(w, "- "))
+		case !inWant:
+			fmt.Fprintf(&b, "added route:\n%s", prefixLines(g, "+ "))
+		case w != g:
+			fmt.Fprintf(&b, "changed route:\n%s%s", prefixLines(w, "- "), prefixLines(g, "+ "))
+		}
+	}
+
+	return cmp.Or(b.String(), "route tables differ in formatting\n")
+}
+
+// routeBlocks splits a route table into its route blocks, keyed by their first line.
+func routeBlocks(table string) map[string]string {
+	var (
+		blocks  = map[string]string{}
+		current string
+	)
+
+	for _, line := range strings.SplitAfter(table, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			current = strings.TrimSpace(line)
+		}
+		blocks[current] += line
+	}
+	return blocks
+}
+
+func prefixLines(s, prefix string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if line != "" {
+			b.WriteString(prefix + line)
+		}
+	}
+	return b.String()
+}
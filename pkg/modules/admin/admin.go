@@ -0,0 +1,325 @@
+// Package admin provides a godi module exposing operational endpoints: a config
+// dump with secret redaction, runtime log level changes, the route table, build
+// information and the maintenance mode switch.
+//
+// The endpoints expose sensitive information and must be protected with guards, which
+// the module requires unless AllowUnauthenticated is set:
+//
+//	var level = new(slog.LevelVar)
+//
+//	Imports: []godi.Module{
+//		&admin.Module{
+//			Prefix:    "/_admin",
+//			AppConfig: cfg,
+//			LogLevel:  level,
+//			Guards:    []godi.Guard{&AdminGuard{}},
+//		},
+//	}
+//
+// mounts the following endpoints:
+//
+//	GET /_admin/config       the config as JSON, with secrets redacted
+//	GET /_admin/log-level    the current log level
+//	PUT /_admin/log-level    changes the log level, e.g. {"level": "DEBUG"}
+//	GET /_admin/routes       the route table
+//	GET /_admin/build        the godi.BuildInfo of the binary
+//	GET /_admin/maintenance  the maintenance mode status
+//	PUT /_admin/maintenance  toggles maintenance mode, e.g. {"enabled": true, "retryAfter": "10m"}
+//
+// The admin endpoints stay live in maintenance mode, so it can be disabled.
+//
+// With Addr set, the endpoints are served on a dedicated listener started along with
+// the application's server, e.g. one bound to a private interface, instead of the
+// application's listener, which responds to them with 404.
+package admin
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// defaultPrefix is the path the endpoints are mounted under when no prefix is set.
+const defaultPrefix = "/admin"
+
+// Module mounts the admin endpoints.
+type Module struct {
+	// Prefix is the path the endpoints are mounted under. Defaults to "/admin".
+	Prefix string
+
+	// AppConfig is the application config served by the config endpoint.
+	// The endpoint is not mounted when it is nil.
+	AppConfig any
+
+	// SecretPattern matches the config keys whose values are redacted, along with
+	// fields tagged `redact:"true"`. Defaults to the application's redactor.
+	SecretPattern *regexp.Regexp
+
+	// LogLevel is the level changed by the log level endpoint.
+	// The endpoint is not mounted when it is nil.
+	LogLevel *slog.LevelVar
+
+	// Guards are applied to every admin endpoint.
+	Guards []godi.Guard
+
+	// GuardsCtors provides constructors for guards that require dependency injection.
+	GuardsCtors []godi.GuardConstructor
+
+	// AllowUnauthenticated mounts the endpoints without guards, e.g. when Addr is only
+	// reachable from the host. By default, a module without guards is invalid.
+	AllowUnauthenticated bool
+
+	// Addr is the address of a dedicated listener serving the endpoints, e.g. "localhost:9090".
+	// The endpoints are served on the application's listener when it is empty.
+	Addr string
+}
+
+// errNotFound is rendered with 404 for admin requests received on another listener than Addr.
+var errNotFound = errors.New("admin: not found")
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	if len(m.Guards) == 0 && len(m.GuardsCtors) == 0 && !m.AllowUnauthenticated {
+		return errors.New("admin: no guards configured, set AllowUnauthenticated to mount the endpoints without any")
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		ControllersCtors: []godi.ControllerConstructor{m.newController},
+	}
+}
+
+func (m *Module) newController(app *godi.App, server *godi.HttpServer, build godi.BuildInfo) *controller {
+	c := &controller{module: m, app: app, build: build}
+	if m.Addr != "" {
+		c.listener = &listenerGuard{}
+		server.OnStart(c.listen)
+	}
+	return c
+}
+
+// controller serves the admin endpoints.
+type controller struct {
+	app      *godi.App
+	module   *Module
+	build    godi.BuildInfo
+	listener *listenerGuard // restricts the endpoints to the dedicated listener, if any.
+}
+
+// listen starts the dedicated listener, serving the admin endpoints of the application's
+// handler until the application's server shuts down.
+func (c *controller) listen(ctx context.Context, _ net.Addr) error {
+	ln, err := net.Listen("tcp", c.module.Addr)
+	if err != nil {
+		return fmt.Errorf("admin: error listening on (%s): %w", c.module.Addr, err)
+	}
+	c.listener.addr.Store(ln.Addr().String())
+
+	var (
+		prefix  = strings.TrimSuffix(cmp.Or(c.module.Prefix, defaultPrefix), "/")
+		handler = c.app.Handler()
+		srv     = &http.Server{
+			ReadHeaderTimeout: 10 * time.Second,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+					http.NotFound(w, r)
+					return
+				}
+				handler.ServeHTTP(w, r)
+			}),
+		}
+	)
+
+	go func() {
+		err := srv.Serve(ln)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("admin: error serving the admin listener", "addr", c.module.Addr, "error", err)
+		}
+	}()
+
+	c.app.OnShutdown(srv.Shutdown, godi.PreDrain)
+	return nil
+}
+
+// listenerGuard is a godi.Guard rejecting the requests not received on the dedicated listener.
+type listenerGuard struct {
+	addr atomic.Value // the address of the dedicated listener, once it listens.
+}
+
+// Allow implements the godi.Guard interface.
+func (g *listenerGuard) Allow(gctx godi.GuardContext) (bool, error) {
+	local, _ := gctx.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if addr, _ := g.addr.Load().(string); local == nil || addr == "" || local.String() != addr {
+		return false, &godi.StatusError{Status: http.StatusNotFound, Err: errNotFound}
+	}
+	return true, nil
+}
+
+func (c *controller) Config() *godi.ControllerConfig {
+	routes := []*godi.RouteConfig{
+		{Method: http.MethodGet, Pattern: "/routes", Handler: http.HandlerFunc(c.handleRoutes)},
+		{Method: http.MethodGet, Pattern: "/build", Handler: http.HandlerFunc(c.handleBuild)},
+		{Method: http.MethodGet, Pattern: "/maintenance", Handler: http.HandlerFunc(c.handleGetMaintenance)},
+		{Method: http.MethodPut, Pattern: "/maintenance", Handler: http.HandlerFunc(c.handleSetMaintenance)},
+	}
+
+	if c.module.AppConfig != nil {
+		routes = append(routes, &godi.RouteConfig{
+			Method: http.MethodGet, Pattern: "/config", Handler: http.HandlerFunc(c.handleConfig),
+		})
+	}
+
+	if c.module.LogLevel != nil {
+		routes = append(routes,
+			&godi.RouteConfig{Method: http.MethodGet, Pattern: "/log-level", Handler: http.HandlerFunc(c.handleGetLogLevel)},
+			&godi.RouteConfig{Method: http.MethodPut, Pattern: "/log-level", Handler: http.HandlerFunc(c.handleSetLogLevel)},
+		)
+	}
+
+	guards := c.module.Guards
+	if c.listener != nil {
+		guards = append([]godi.Guard{c.listener}, guards...)
+	}
+
+	return &godi.ControllerConfig{
+		Pattern:     cmp.Or(c.module.Prefix, defaultPrefix),
+		Guards:      guards,
+		GuardsCtors: c.module.GuardsCtors,
+		Metadata:    map[string]string{godi.MaintenanceExemptKey: "true"},
+		RoutesCfgs:  routes,
+	}
+}
+
+// Route is a row of the route table.
+type Route struct {
+	Module     string `json:"module"`
+	Controller string `json:"controller"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+}
+
+func (c *controller) handleRoutes(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, routeTable(c.app.ModuleTree()))
+}
+
+func routeTable(node godi.ModuleNode) []Route {
+	routes := []Route{}
+	for _, ctrl := range node.Controllers {
+		for _, r := range ctrl.Routes {
+			routes = append(routes, Route{
+				Module:     node.Module,
+				Controller: ctrl.Controller,
+				Method:     cmp.Or(strings.Join(r.Methods, ","), r.Method),
+				Path:       r.Path,
+			})
+		}
+	}
+	for _, imported := range node.Imports {
+		routes = append(routes, routeTable(imported)...)
+	}
+	return routes
+}
+
+func (c *controller) handleBuild(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, c.build)
+}
+
+func (c *controller) handleConfig(w http.ResponseWriter, r *http.Request) {
+	redactor := c.app.Redactor()
+	if c.module.SecretPattern != nil {
+		redactor = godi.NewRedactor(c.module.SecretPattern)
+	}
+	writeJSON(w, http.StatusOK, redactor.Redact(c.module.AppConfig))
+}
+
+// logLevel is the body of the log level endpoints.
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+func (c *controller) handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, logLevel{Level: c.module.LogLevel.Level().String()})
+}
+
+func (c *controller) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var (
+		body  logLevel
+		level slog.Level
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err == nil {
+		err = level.UnmarshalText([]byte(body.Level))
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid log level: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	c.module.LogLevel.Set(level)
+	writeJSON(w, http.StatusOK, logLevel{Level: level.String()})
+}
+
+// maintenance is the body of the maintenance endpoints.
+type maintenance struct {
+	Enabled    bool       `json:"enabled"`
+	Since      *time.Time `json:"since,omitempty"`
+	RetryAfter string     `json:"retryAfter,omitempty"` // a duration, e.g. "10m".
+}
+
+func (c *controller) handleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, maintenanceBody(c.app.Maintenance().Status()))
+}
+
+func (c *controller) handleSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var (
+		body       maintenance
+		retryAfter time.Duration
+	)
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err == nil && body.RetryAfter != "" {
+		retryAfter, err = time.ParseDuration(body.RetryAfter)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid maintenance mode: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if body.Enabled {
+		c.app.Maintenance().Enable(retryAfter)
+	} else {
+		c.app.Maintenance().Disable()
+	}
+	writeJSON(w, http.StatusOK, maintenanceBody(c.app.Maintenance().Status()))
+}
+
+func maintenanceBody(status godi.MaintenanceStatus) maintenance {
+	body := maintenance{Enabled: status.Enabled}
+	if status.Enabled {
+		body.Since = &status.Since
+	}
+	if status.RetryAfter > 0 {
+		body.RetryAfter = status.RetryAfter.String()
+	}
+	return body
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
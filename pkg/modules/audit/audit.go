@@ -0,0 +1,267 @@
+// Package audit provides a godi module recording an audit record of each request handled
+// by the routes it intercepts, stored as events of the transactional outbox, so they are
+// relayed to the audit log by the outbox module.
+//
+// Records are written through the database transaction of the request when it has one,
+// so a record and the changes it describes commit or roll back together. The TxInterceptor
+// opens that transaction, which handlers retrieve with Tx:
+//
+//	Imports: []godi.Module{
+//		&outbox.Module{Publisher: auditLogPublisher},
+//		&audit.Module{Actor: func(r *http.Request) string { ... }},
+//	}
+//
+//	func (c *AccountController) Config() *godi.ControllerConfig {
+//		return &godi.ControllerConfig{
+//			InterceptorsCtors: []godi.InterceptorConstructor{audit.NewTxInterceptor},
+//			...
+//		}
+//	}
+//
+//	func (c *AccountController) handleClose(w http.ResponseWriter, r *http.Request) {
+//		tx, _ := audit.Tx(r.Context())
+//		_, err := tx.ExecContext(r.Context(), "UPDATE accounts SET closed = true WHERE id = $1", id)
+//		...
+//	}
+//
+// Routes whose transaction is opened by another interceptor, wrapping the audit one, use
+// the Interceptor with the module's TxFrom returning that transaction.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/huboh/godi"
+	"github.com/huboh/godi/pkg/modules/outbox"
+)
+
+// Record is the audit record of a request.
+type Record struct {
+	// Time is the time the request was received.
+	Time time.Time `json:"time"`
+
+	// Actor identifies who made the request, e.g. a user ID.
+	Actor string `json:"actor,omitempty"`
+
+	// Action is the name of the route handling the request, or its method and pattern,
+	// e.g. "DELETE /accounts/{id}".
+	Action string `json:"action"`
+
+	// Path is the path of the request.
+	Path string `json:"path"`
+
+	// Status is the status code of the response.
+	Status int `json:"status"`
+
+	// RequestID correlates the record with the request's logs.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// Module provides the Auditor recording requests to every module of the application.
+//
+// It depends on the outbox module and on the *sql.DB provided by a global module.
+type Module struct {
+	// Topic is the outbox topic records are added to. Defaults to "audit".
+	Topic string
+
+	// Actor identifies who made a request. Records have no actor if nil.
+	Actor func(r *http.Request) string
+
+	// TxFrom returns the database transaction of a request opened by another module's
+	// interceptor, if any, e.g. a unit of work interceptor. Records of requests
+	// without a transaction are written outside of any transaction.
+	TxFrom func(ctx context.Context) (outbox.Execer, bool)
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newAuditor},
+		ExportsCtors:   []godi.ProviderConstructor{m.newAuditor},
+	}
+}
+
+func (m *Module) newAuditor(db *sql.DB, ob *outbox.Outbox) *Auditor {
+	a := &Auditor{
+		db:     db,
+		outbox: ob,
+		topic:  m.Topic,
+		actor:  m.Actor,
+		txFrom: m.TxFrom,
+	}
+	if a.topic == "" {
+		a.topic = "audit"
+	}
+	return a
+}
+
+// Auditor writes audit records to the outbox.
+type Auditor struct {
+	db     *sql.DB
+	outbox *outbox.Outbox
+	topic  string
+	actor  func(r *http.Request) string
+	txFrom func(ctx context.Context) (outbox.Execer, bool)
+}
+
+// Write adds the record to the outbox using tx, which is usually the transaction
+// of the changes the record describes.
+func (a *Auditor) Write(ctx context.Context, tx outbox.Execer, rec Record) error {
+	err := a.outbox.Add(ctx, tx, a.topic, rec.Action, rec)
+	if err != nil {
+		return fmt.Errorf("audit: error writing record: %w", err)
+	}
+	return nil
+}
+
+// record returns the audit record of the request, responded to with the status.
+func (a *Auditor) record(ictx godi.InterceptorContext, start time.Time, status int) Record {
+	r := ictx.Http.R
+
+	rec := Record{
+		Time:      start.UTC(),
+		Path:      r.URL.Path,
+		Status:    status,
+		RequestID: godi.RequestIDFrom(r.Context()),
+	}
+	if info, ok := godi.RouteFrom(r.Context()); ok {
+		rec.Action = info.Name
+		if rec.Action == "" {
+			rec.Action = r.Method + " " + info.Pattern
+		}
+	}
+	if a.actor != nil {
+		rec.Actor = a.actor(r)
+	}
+	return rec
+}
+
+// exec returns the transaction of the request, or the database if it has none.
+func (a *Auditor) exec(ctx context.Context) outbox.Execer {
+	if tx, ok := Tx(ctx); ok {
+		return tx
+	}
+	if a.txFrom != nil {
+		if tx, ok := a.txFrom(ctx); ok {
+			return tx
+		}
+	}
+	return a.db
+}
+
+// Interceptor is a godi.Interceptor writing the audit record of each request it wraps
+// once its handler returns, through the request's transaction if it has one.
+type Interceptor struct {
+	auditor *Auditor
+}
+
+// NewInterceptor returns an interceptor writing audit records with the auditor.
+// It can be listed in the InterceptorsCtors of a controller or route.
+func NewInterceptor(a *Auditor) *Interceptor {
+	return &Interceptor{auditor: a}
+}
+
+// Intercept implements the godi.Interceptor interface.
+func (i *Interceptor) Intercept(ictx godi.InterceptorContext, next http.Handler) error {
+	var (
+		r     = ictx.Http.R
+		w     = godi.NewStatusRecorder(ictx.Http.W)
+		start = time.Now()
+	)
+
+	next.ServeHTTP(w, r)
+
+	ctx := context.WithoutCancel(r.Context())
+	err := i.auditor.Write(ctx, i.auditor.exec(ctx), i.auditor.record(ictx, start, w.Status()))
+	if err != nil {
+		godi.LoggerFrom(ctx).Error("error writing audit record", "error", err)
+	}
+	return nil
+}
+
+// txKey is the context key of the transaction opened by the TxInterceptor.
+type txKey struct{}
+
+// Tx returns the transaction opened for the request of ctx by the TxInterceptor.
+func Tx(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// TxInterceptor is a godi.Interceptor opening a database transaction for each request
+// it wraps, which handlers retrieve with Tx, and writing the request's audit record
+// through it before committing it.
+//
+// Transactions of requests responded to with an error status code are rolled back and
+// their audit record is written outside of them, so failed attempts are audited too.
+// The response is held back until the transaction commits, so clients are never told a
+// change succeeded when it was rolled back: requests whose transaction fails to commit
+// are responded to with 500 instead, and audited as such.
+type TxInterceptor struct {
+	auditor *Auditor
+}
+
+// NewTxInterceptor returns an interceptor opening request transactions and writing
+// audit records with the auditor. It can be listed in the InterceptorsCtors of a
+// controller or route.
+func NewTxInterceptor(a *Auditor) *TxInterceptor {
+	return &TxInterceptor{auditor: a}
+}
+
+// Intercept implements the godi.Interceptor interface.
+func (i *TxInterceptor) Intercept(ictx godi.InterceptorContext, next http.Handler) error {
+	var (
+		r     = ictx.Http.R
+		buf   = godi.NewResponseBuffer()
+		start = time.Now()
+	)
+
+	tx, err := i.auditor.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		return fmt.Errorf("audit: error beginning transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	next.ServeHTTP(buf, r.WithContext(context.WithValue(r.Context(), txKey{}, tx)))
+
+	var (
+		ctx = context.WithoutCancel(r.Context())
+		rec = i.auditor.record(ictx, start, buf.Status())
+		log = godi.LoggerFrom(ctx)
+	)
+
+	if rec.Status >= http.StatusBadRequest {
+		err = errors.Join(tx.Rollback(), i.auditor.Write(ctx, i.auditor.db, rec))
+		if err != nil {
+			log.Error("error writing audit record of failed request", "error", err)
+		}
+		return buf.Replay(ictx.Http.W)
+	}
+
+	err = i.auditor.Write(ctx, tx, rec)
+	if err == nil {
+		err = tx.Commit()
+		committed = err == nil
+	}
+	if err != nil {
+		err = fmt.Errorf("audit: error committing audited transaction: %w", err)
+
+		rec.Status = http.StatusInternalServerError
+		if werr := i.auditor.Write(ctx, i.auditor.db, rec); werr != nil {
+			log.Error("error writing audit record of failed request", "error", werr)
+		}
+		return err
+	}
+	return buf.Replay(ictx.Http.W)
+}
@@ -0,0 +1,277 @@
+// Package batch provides a godi module serving a batch endpoint, which accepts an array
+// of sub-requests and responds with their responses, so clients on high-latency networks,
+// like mobile apps, make one round-trip instead of many.
+//
+//	Imports: []godi.Module{
+//		&batch.Module{MaxRequests: 10},
+//	}
+//
+//	POST /batch
+//	[
+//		{"method": "GET", "path": "/users/me"},
+//		{"method": "POST", "path": "/orders", "body": {"productId": "p1"}}
+//	]
+//
+// Sub-requests are dispatched in memory through the application's handler, so they go
+// through the guards, interceptors and error rendering of their routes like any other
+// request. They inherit the headers of the batch request, e.g. its Authorization header,
+// unless they set them.
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/huboh/godi"
+)
+
+const (
+	defaultPath        = "/batch"
+	defaultMaxRequests = 20
+	maxBodySize        = 10 << 20
+)
+
+// ErrInvalidBatch is rendered with 400 when a batch request is malformed or has too many sub-requests.
+var ErrInvalidBatch = errors.New("batch: invalid batch request")
+
+// Request is a sub-request of a batch.
+type Request struct {
+	// ID identifies the sub-request in the responses. Defaults to its index.
+	ID string `json:"id,omitempty"`
+
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is sent as the sub-request's JSON body.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is the response to a sub-request.
+type Response struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is the JSON body of the response, or its body as a JSON string otherwise.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Module serves the batch endpoint.
+type Module struct {
+	// Path is the path of the batch endpoint. Defaults to "/batch".
+	Path string
+
+	// MaxRequests is the maximum number of sub-requests of a batch. Defaults to 20.
+	MaxRequests int
+
+	// Concurrent dispatches the sub-requests concurrently rather than in order,
+	// for batches whose sub-requests do not depend on each other.
+	Concurrent bool
+
+	// Guards are applied to the batch endpoint, before the guards of the sub-requests' routes.
+	Guards []godi.Guard
+
+	// GuardsCtors provides constructors for guards that require dependency injection.
+	GuardsCtors []godi.GuardConstructor
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		ControllersCtors: []godi.ControllerConstructor{
+			func(server *godi.HttpServer) *controller {
+				return &controller{module: m, server: server}
+			},
+		},
+	}
+}
+
+// controller serves the batch endpoint.
+type controller struct {
+	module *Module
+	server *godi.HttpServer
+}
+
+func (c *controller) Config() *godi.ControllerConfig {
+	return &godi.ControllerConfig{
+		Pattern:     "/",
+		Guards:      c.module.Guards,
+		GuardsCtors: c.module.GuardsCtors,
+		RoutesCfgs: []*godi.RouteConfig{
+			{Method: http.MethodPost, Pattern: c.path(), Handler: http.HandlerFunc(c.handleBatch)},
+		},
+	}
+}
+
+func (c *controller) path() string {
+	if c.module.Path != "" {
+		return c.module.Path
+	}
+	return defaultPath
+}
+
+func (c *controller) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var reqs []Request
+
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&reqs)
+	if err != nil {
+		godi.RenderError(w, r, http.StatusBadRequest, fmt.Errorf("%w: %w", ErrInvalidBatch, err))
+		return
+	}
+
+	maxRequests := c.module.MaxRequests
+	if maxRequests <= 0 {
+		maxRequests = defaultMaxRequests
+	}
+	if len(reqs) > maxRequests {
+		godi.RenderError(w, r, http.StatusBadRequest, fmt.Errorf("%w: more than %d sub-requests", ErrInvalidBatch, maxRequests))
+		return
+	}
+
+	subs := make([]*http.Request, len(reqs))
+	for i, req := range reqs {
+		if req.ID == "" {
+			reqs[i].ID = fmt.Sprint(i)
+		}
+		subs[i], err = c.newRequest(r, req)
+		if err != nil {
+			godi.RenderError(w, r, http.StatusBadRequest, fmt.Errorf("%w: sub-request %s: %w", ErrInvalidBatch, reqs[i].ID, err))
+			return
+		}
+	}
+
+	resps := make([]Response, len(reqs))
+	if c.module.Concurrent {
+		var wg sync.WaitGroup
+		for i := range subs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resps[i] = c.dispatch(reqs[i].ID, subs[i])
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range subs {
+			resps[i] = c.dispatch(reqs[i].ID, subs[i])
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resps)
+}
+
+// newRequest returns the sub-request of the batch request r. It is detached from the
+// batch request's state, so its guards authenticate it on their own, but is canceled
+// with it and shares its request ID.
+func (c *controller) newRequest(r *http.Request, req Request) (*http.Request, error) {
+	if !strings.HasPrefix(req.Path, "/") {
+		return nil, fmt.Errorf("path %q is not absolute", req.Path)
+	}
+	if strings.SplitN(req.Path, "?", 2)[0] == c.path() {
+		return nil, errors.New("batches cannot be nested")
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	context.AfterFunc(r.Context(), cancel)
+
+	sub, err := http.NewRequestWithContext(ctx, method, req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	sub.Host = r.Host
+	sub.RemoteAddr = r.RemoteAddr
+	sub.TLS = r.TLS
+	sub.Header = r.Header.Clone()
+	sub.Header.Del("Content-Length")
+	sub.Header.Del("Content-Encoding")
+	if len(req.Body) > 0 {
+		sub.Header.Set("Content-Type", "application/json")
+	} else {
+		sub.Header.Del("Content-Type")
+	}
+	for name, value := range req.Headers {
+		sub.Header.Set(name, value)
+	}
+	if id := godi.RequestIDFrom(r.Context()); id != "" {
+		sub.Header.Set(godi.RequestIDHeader, id)
+	}
+	return sub, nil
+}
+
+// dispatch serves the sub-request with the application's handler, recovering from
+// panics so one failing sub-request does not fail the batch.
+func (c *controller) dispatch(id string, r *http.Request) (resp Response) {
+	rec := &recorder{header: http.Header{}}
+
+	defer func() {
+		if v := recover(); v != nil {
+			godi.LoggerFrom(r.Context()).Error("batch sub-request panicked", "id", id, "panic", fmt.Sprint(v))
+			rec = &recorder{header: http.Header{}, status: http.StatusInternalServerError}
+		}
+		resp = rec.response(id)
+	}()
+
+	c.server.Handler().ServeHTTP(rec, r)
+	return resp
+}
+
+// recorder is the http.ResponseWriter recording the response to a sub-request.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.body.Write(p)
+}
+
+// response returns the recorded response, with the JSON body included as is and other
+// bodies as JSON strings.
+func (rec *recorder) response(id string) Response {
+	resp := Response{ID: id, Status: rec.status, Headers: map[string]string{}}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+
+	for name, values := range rec.header {
+		resp.Headers[name] = strings.Join(values, ", ")
+	}
+
+	body := bytes.TrimSpace(rec.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body) && strings.Contains(rec.header.Get("Content-Type"), "json"):
+		resp.Body = body
+	default:
+		resp.Body, _ = json.Marshal(string(body))
+	}
+	return resp
+}
@@ -0,0 +1,227 @@
+// Package bot provides a godi module screening requests with pluggable bot-detection
+// providers, and a guard blocking bots or challenging suspicious clients, e.g. with a
+// CAPTCHA page, before they reach public-facing routes.
+//
+// Detectors run in order, from cheap header heuristics to external services, and the
+// first one reaching a verdict other than Allow decides:
+//
+//	Imports: []godi.Module{
+//		&bot.Module{
+//			Detectors:  []bot.Detector{bot.Heuristics{}, captchaService},
+//			Challenger: captchaService,
+//		},
+//	}
+//
+//	func (c *SignupController) Config() *godi.ControllerConfig {
+//		return &godi.ControllerConfig{
+//			GuardsCtors: []godi.GuardConstructor{bot.NewGuard},
+//			...
+//		}
+//	}
+//
+// Blocked requests are rendered with 403 and ErrBot. Challenged requests are responded to
+// by the Challenger, and clients that solved a challenge skip detection until the proof of
+// their solution, e.g. a signed cookie, expires.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/huboh/godi"
+)
+
+// ErrBot is rendered with 403 when a request is blocked as coming from a bot.
+var ErrBot = errors.New("bot: blocked")
+
+// Action is the action taken on a request.
+type Action int
+
+const (
+	// Allow lets the request through, unless a later detector reaches another verdict.
+	Allow Action = iota
+
+	// Challenge responds to the request with a challenge the client must solve.
+	Challenge
+
+	// Block rejects the request.
+	Block
+)
+
+func (a Action) String() string {
+	switch a {
+	case Allow:
+		return "allow"
+	case Challenge:
+		return "challenge"
+	case Block:
+		return "block"
+	}
+	return "unknown"
+}
+
+// Verdict is the decision of a detector about a request.
+type Verdict struct {
+	Action Action
+
+	// Reason explains the verdict in logs, e.g. "missing user agent".
+	Reason string
+}
+
+// Detector decides whether a request comes from a bot, e.g. with header heuristics
+// or by querying an external bot management service.
+type Detector interface {
+	Detect(r *http.Request) (Verdict, error)
+}
+
+// DetectorFunc is a function implementing Detector.
+type DetectorFunc func(r *http.Request) (Verdict, error)
+
+// Detect implements the Detector interface.
+func (fn DetectorFunc) Detect(r *http.Request) (Verdict, error) {
+	return fn(r)
+}
+
+// Challenger challenges suspicious clients, e.g. with a CAPTCHA or proof-of-work page.
+type Challenger interface {
+	// Passed reports whether the request carries the proof of a solved challenge,
+	// e.g. a signed cookie set once the client solved it.
+	Passed(r *http.Request) bool
+
+	// Challenge responds to the request with a challenge.
+	Challenge(w http.ResponseWriter, r *http.Request) error
+}
+
+// Module provides the Screen detecting bots to every module of the application.
+type Module struct {
+	// Detectors decide whether requests come from bots, in order.
+	Detectors []Detector
+
+	// Challenger challenges the requests detectors reach the Challenge verdict for.
+	// They are blocked if it is nil.
+	Challenger Challenger
+
+	// FailOpen allows the requests a detector fails to screen, e.g. when an external
+	// service is unavailable. By default, they are rejected with 500.
+	FailOpen bool
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newScreen},
+		ExportsCtors:   []godi.ProviderConstructor{m.newScreen},
+	}
+}
+
+func (m *Module) newScreen() *Screen {
+	return &Screen{detectors: m.Detectors, challenger: m.Challenger, failOpen: m.FailOpen}
+}
+
+// Screen screens requests with the module's detectors.
+type Screen struct {
+	detectors  []Detector
+	challenger Challenger
+	failOpen   bool
+}
+
+// Screen returns the verdict of the first detector reaching one other than Allow.
+// Requests carrying the proof of a solved challenge are allowed without detection.
+func (s *Screen) Screen(r *http.Request) (Verdict, error) {
+	if s.challenger != nil && s.challenger.Passed(r) {
+		return Verdict{Action: Allow, Reason: "challenge passed"}, nil
+	}
+
+	for _, d := range s.detectors {
+		v, err := d.Detect(r)
+		if err != nil {
+			if s.failOpen {
+				continue
+			}
+			return Verdict{}, fmt.Errorf("bot: error detecting with (%T): %w", d, err)
+		}
+		if v.Action != Allow {
+			return v, nil
+		}
+	}
+	return Verdict{Action: Allow}, nil
+}
+
+// Guard is a godi.Guard blocking or challenging the requests the screen detects as bots.
+type Guard struct {
+	screen *Screen
+}
+
+// NewGuard returns a guard acting on the verdicts of the screen.
+// It can be listed in the GuardsCtors of a controller or route.
+func NewGuard(s *Screen) *Guard {
+	return &Guard{screen: s}
+}
+
+// Allow implements the godi.Guard interface.
+func (g *Guard) Allow(gctx godi.GuardContext) (bool, error) {
+	var (
+		w = gctx.Http.W
+		r = gctx.Http.R
+	)
+
+	v, err := g.screen.Screen(r)
+	if err != nil {
+		return false, err
+	}
+
+	if v.Action != Allow {
+		godi.LoggerFrom(r.Context()).Info("bot detected", "action", v.Action.String(), "reason", v.Reason)
+	}
+
+	switch {
+	case v.Action == Allow:
+		return true, nil
+	case v.Action == Challenge && g.screen.challenger != nil:
+		err = g.screen.challenger.Challenge(w, r)
+		if err != nil {
+			return false, fmt.Errorf("bot: error challenging request: %w", err)
+		}
+		return false, godi.ErrResponded
+	default:
+		return false, &godi.StatusError{Status: http.StatusForbidden, Err: ErrBot}
+	}
+}
+
+// Heuristics is a Detector challenging requests whose headers are unlikely to come from
+// a browser: a missing user agent, the user agent of a known automation tool, or a missing
+// Accept header.
+type Heuristics struct {
+	// UserAgents lists additional substrings of bot user agents, matched case-insensitively.
+	UserAgents []string
+
+	// Block blocks the detected requests rather than challenging them.
+	Block bool
+}
+
+// defaultBotAgents lists substrings of the user agents of common automation tools.
+var defaultBotAgents = []string{"curl/", "wget/", "python-requests", "go-http-client", "headlesschrome", "scrapy", "phantomjs"}
+
+// Detect implements the Detector interface.
+func (h Heuristics) Detect(r *http.Request) (Verdict, error) {
+	action := Challenge
+	if h.Block {
+		action = Block
+	}
+
+	ua := strings.ToLower(r.UserAgent())
+	switch {
+	case ua == "":
+		return Verdict{Action: action, Reason: "missing user agent"}, nil
+	case slices.ContainsFunc(slices.Concat(defaultBotAgents, h.UserAgents), func(s string) bool {
+		return strings.Contains(ua, strings.ToLower(s))
+	}):
+		return Verdict{Action: action, Reason: "automation user agent"}, nil
+	case r.Header.Get("Accept") == "":
+		return Verdict{Action: action, Reason: "missing accept header"}, nil
+	}
+	return Verdict{Action: Allow}, nil
+}
@@ -0,0 +1,148 @@
+// Package cli provides a godi module for running command line tasks, such as
+// migrations, admin tasks and seeders, with the application's injected services.
+//
+// Modules register commands with [Register], and [godi.App.RunCLI] dispatches the
+// process arguments to them:
+//
+//	func NewMigrateCommand(db *sql.DB) *cli.Command {
+//		var steps int
+//		return &cli.Command{
+//			Name:  "migrate",
+//			Usage: "apply pending database migrations",
+//			Flags: func(fs *flag.FlagSet) {
+//				fs.IntVar(&steps, "steps", 0, "number of migrations to apply")
+//			},
+//			Run: func(ctx context.Context, fs *flag.FlagSet) error {
+//				return migrate(ctx, db, steps)
+//			},
+//		}
+//	}
+//
+//	ProvidersCtors: []godi.ProviderConstructor{cli.Register(NewMigrateCommand)}
+//
+//	err = app.RunCLI(os.Args[1:])
+package cli
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/huboh/godi"
+	"go.uber.org/dig"
+)
+
+// commandsGroup is the value group commands are registered in.
+const commandsGroup = "godi.cli.commands"
+
+// Module provides the command runner used by godi.App.RunCLI.
+// It is global, so commands registered by any module are available.
+type Module struct{}
+
+func (*Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{godi.Provide[godi.CommandRunner](NewRunner)},
+		ExportsCtors:   []godi.ProviderConstructor{godi.Provide[godi.CommandRunner](NewRunner)},
+	}
+}
+
+// Command is a command line task whose handler can use the app's injected services.
+type Command struct {
+	// Name is the name the command is invoked with.
+	Name string
+
+	// Usage is a short description of the command shown in the help output.
+	Usage string
+
+	// Flags optionally registers the command's flags on the flag set.
+	Flags func(fs *flag.FlagSet)
+
+	// Run runs the command. The flag set has been parsed, and fs.Args()
+	// returns the remaining positional arguments.
+	Run func(ctx context.Context, fs *flag.FlagSet) error
+}
+
+// Register returns a ProviderConstructor registering the *Command produced by the constructor.
+func Register(ctor godi.ProviderConstructor) godi.ProviderConstructor {
+	return godi.Group(commandsGroup, ctor)
+}
+
+// Runner dispatches command line arguments to registered commands.
+type Runner struct {
+	out      io.Writer
+	commands map[string]*Command
+}
+
+// RunnerInput holds the dependencies of the Runner.
+type RunnerInput struct {
+	dig.In
+	Commands []*Command `group:"godi.cli.commands"`
+}
+
+// NewRunner creates a Runner for the registered commands,
+// reporting an error if two commands share a name.
+func NewRunner(input RunnerInput) (*Runner, error) {
+	r := &Runner{
+		out:      os.Stderr,
+		commands: map[string]*Command{},
+	}
+
+	for _, cmd := range input.Commands {
+		if cmd == nil || cmd.Name == "" || cmd.Run == nil {
+			return nil, errors.New("command must have a name and a run function")
+		}
+		if _, exists := r.commands[cmd.Name]; exists {
+			return nil, fmt.Errorf("command (%s) is registered more than once", cmd.Name)
+		}
+		r.commands[cmd.Name] = cmd
+	}
+
+	return r, nil
+}
+
+// RunCommand runs the command named by the first argument, passing it the remaining arguments.
+// Running without arguments, or with "help", prints the available commands.
+func (r *Runner) RunCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		r.printUsage()
+		return nil
+	}
+
+	cmd, ok := r.commands[args[0]]
+	if !ok {
+		r.printUsage()
+		return fmt.Errorf("unknown command (%s)", args[0])
+	}
+
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(r.out)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return err
+	}
+
+	return cmd.Run(ctx, fs)
+}
+
+func (r *Runner) printUsage() {
+	cmds := slices.SortedFunc(
+		maps.Values(r.commands),
+		func(a, b *Command) int { return cmp.Compare(a.Name, b.Name) },
+	)
+
+	fmt.Fprintln(r.out, "Commands:")
+	for _, cmd := range cmds {
+		fmt.Fprintf(r.out, "  %-16s %s\n", cmd.Name, cmd.Usage)
+	}
+}
@@ -0,0 +1,211 @@
+// Package codec provides a godi module exposing a Codec that encrypts and authenticates
+// values handed to clients, such as cookies, tokens and OAuth state parameters, so they
+// can be neither read nor forged.
+//
+// Values are sealed with AES-256-GCM and bound to their name, so a value cannot be
+// replayed under another name. Keys are rotated by prepending the new key: values are
+// sealed with the first key and opened with any of them.
+//
+//	Imports: []godi.Module{
+//		&codec.Module{Keys: [][]byte{newKey, oldKey}, MaxAge: 24 * time.Hour},
+//	}
+//
+//	func (c *AuthController) handleLogin(w http.ResponseWriter, r *http.Request) {
+//		...
+//		err := c.codec.SetCookie(w, &http.Cookie{Name: "session", Path: "/", HttpOnly: true}, session)
+//	}
+//
+//	var session Session
+//	err := c.codec.ReadCookie(r, "session", &session)
+package codec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// version is the format version prefixed to sealed values.
+const version = 1
+
+var (
+	// ErrInvalid is returned when opening a value that was tampered with, sealed under
+	// another name or with a key that is no longer configured.
+	ErrInvalid = errors.New("codec: invalid value")
+
+	// ErrExpired is returned when opening a value older than the codec's maximum age.
+	ErrExpired = errors.New("codec: expired value")
+)
+
+// Module provides the Codec to every module of the application.
+type Module struct {
+	// Keys are the 32-byte keys of the codec, the first one sealing values.
+	Keys [][]byte
+
+	// MaxAge is the age beyond which sealed values are rejected. Values do not expire if zero.
+	MaxAge time.Duration
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	_, err := New(m.Keys, m.MaxAge)
+	return err
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newCodec},
+		ExportsCtors:   []godi.ProviderConstructor{m.newCodec},
+	}
+}
+
+func (m *Module) newCodec() (*Codec, error) {
+	return New(m.Keys, m.MaxAge)
+}
+
+// Codec seals and opens values with authenticated encryption.
+type Codec struct {
+	keys   []key
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+type key struct {
+	id   [4]byte
+	aead cipher.AEAD
+}
+
+// New returns a Codec sealing values with the first of the 32-byte keys and opening them
+// with any of them, rejecting values older than maxAge unless it is zero.
+func New(keys [][]byte, maxAge time.Duration) (*Codec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("codec: no keys configured")
+	}
+
+	c := &Codec{maxAge: maxAge, now: time.Now}
+	for i, k := range keys {
+		if len(k) != 32 {
+			return nil, fmt.Errorf("codec: key at index %d is %d bytes long, not 32", i, len(k))
+		}
+
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("codec: invalid key at index %d: %w", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("codec: invalid key at index %d: %w", i, err)
+		}
+
+		sum := sha256.Sum256(k)
+		c.keys = append(c.keys, key{id: [4]byte(sum[:4]), aead: aead})
+	}
+	return c, nil
+}
+
+// Seal encrypts and authenticates the value under the name, returning a URL-safe string.
+func (c *Codec) Seal(name string, value []byte) (string, error) {
+	k := c.keys[0]
+
+	plaintext := binary.BigEndian.AppendUint64(nil, uint64(c.now().Unix()))
+	plaintext = append(plaintext, value...)
+
+	out := make([]byte, 0, 1+len(k.id)+k.aead.NonceSize()+len(plaintext)+k.aead.Overhead())
+	out = append(out, version)
+	out = append(out, k.id[:]...)
+
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("codec: error generating nonce: %w", err)
+	}
+	out = append(out, nonce...)
+	out = k.aead.Seal(out, nonce, plaintext, []byte(name))
+
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Open decrypts and authenticates a value sealed under the name.
+func (c *Codec) Open(name, sealed string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < 5 || data[0] != version {
+		return nil, ErrInvalid
+	}
+
+	id, data := [4]byte(data[1:5]), data[5:]
+	for _, k := range c.keys {
+		if k.id != id || len(data) < k.aead.NonceSize() {
+			continue
+		}
+
+		nonce, ciphertext := data[:k.aead.NonceSize()], data[k.aead.NonceSize():]
+		plaintext, err := k.aead.Open(nil, nonce, ciphertext, []byte(name))
+		if err != nil || len(plaintext) < 8 {
+			return nil, ErrInvalid
+		}
+
+		sealedAt := time.Unix(int64(binary.BigEndian.Uint64(plaintext)), 0)
+		if c.maxAge > 0 && c.now().Sub(sealedAt) > c.maxAge {
+			return nil, ErrExpired
+		}
+		return plaintext[8:], nil
+	}
+	return nil, ErrInvalid
+}
+
+// Encode seals the JSON encoding of v under the name.
+func (c *Codec) Encode(name string, v any) (string, error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("codec: error encoding value: %w", err)
+	}
+	return c.Seal(name, value)
+}
+
+// Decode opens a value sealed by Encode under the name and decodes it into v.
+func (c *Codec) Decode(name, sealed string, v any) error {
+	value, err := c.Open(name, sealed)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(value, v); err != nil {
+		return fmt.Errorf("codec: error decoding value: %w", err)
+	}
+	return nil
+}
+
+// SetCookie sets the cookie with the sealed JSON encoding of v as value, sealed under the
+// cookie's name. The cookie's expiry defaults to the codec's maximum age.
+func (c *Codec) SetCookie(w http.ResponseWriter, cookie *http.Cookie, v any) error {
+	value, err := c.Encode(cookie.Name, v)
+	if err != nil {
+		return err
+	}
+
+	cookie.Value = value
+	if cookie.MaxAge == 0 && cookie.Expires.IsZero() && c.maxAge > 0 {
+		cookie.MaxAge = int(c.maxAge.Seconds())
+	}
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// ReadCookie decodes the value of the named cookie of the request into v,
+// returning http.ErrNoCookie if the request has no such cookie.
+func (c *Codec) ReadCookie(r *http.Request, name string, v any) error {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return err
+	}
+	return c.Decode(name, cookie.Value, v)
+}
@@ -0,0 +1,217 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 32)
+	key3 = bytes.Repeat([]byte{3}, 32)
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		keys [][]byte
+		ok   bool
+	}{
+		{"one key", [][]byte{key1}, true},
+		{"rotated keys", [][]byte{key2, key1}, true},
+		{"no keys", nil, false},
+		{"short key", [][]byte{key1[:16]}, false},
+		{"long key", [][]byte{append(key1, 0)}, false},
+		{"short old key", [][]byte{key1, key2[:31]}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.keys, 0)
+			if (err == nil) != tt.ok {
+				t.Errorf("New = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	var (
+		old     = mustNew(t, key1)
+		rotated = mustNew(t, key2, key1)
+		removed = mustNew(t, key3, key2)
+	)
+
+	sealedOld, err := old.Seal("session", []byte("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealedRotated, err := rotated.Seal("session", []byte("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		codec   *Codec
+		opened  string
+		sealed  string
+		wantErr error
+	}{
+		{"same codec", old, "session", sealedOld, nil},
+		{"old key after rotation", rotated, "session", sealedOld, nil},
+		{"new key after rotation", rotated, "session", sealedRotated, nil},
+		{"new key before rotation", old, "session", sealedRotated, ErrInvalid},
+		{"removed key", removed, "session", sealedOld, ErrInvalid},
+		{"another name", rotated, "csrf", sealedRotated, ErrInvalid},
+		{"empty", rotated, "session", "", ErrInvalid},
+		{"not base64", rotated, "session", "!!!", ErrInvalid},
+		{"truncated", rotated, "session", sealedRotated[:len(sealedRotated)-4], ErrInvalid},
+		{"header only", rotated, "session", sealedRotated[:8], ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.codec.Open(tt.opened, tt.sealed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Open = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(value) != "user-1" {
+				t.Errorf("Open = %q, want %q", value, "user-1")
+			}
+		})
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	c := mustNew(t, key1)
+
+	sealed, err := c.Seal("session", []byte("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range data {
+		tampered := bytes.Clone(data)
+		tampered[i] ^= 0x01
+
+		_, err := c.Open("session", base64.RawURLEncoding.EncodeToString(tampered))
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("Open with byte %d flipped = %v, want ErrInvalid", i, err)
+		}
+	}
+}
+
+func TestOpenExpired(t *testing.T) {
+	var (
+		now = time.Unix(1_700_000_000, 0)
+		c   = mustNew(t, key1)
+	)
+	c.maxAge = time.Hour
+	c.now = func() time.Time { return now }
+
+	sealed, err := c.Seal("session", []byte("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		age     time.Duration
+		wantErr error
+	}{
+		{0, nil},
+		{time.Hour, nil},
+		{time.Hour + time.Second, ErrExpired},
+		{24 * time.Hour, ErrExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.age.String(), func(t *testing.T) {
+			c.now = func() time.Time { return now.Add(tt.age) }
+
+			_, err := c.Open("session", sealed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSealNonce(t *testing.T) {
+	c := mustNew(t, key1)
+
+	a, err := c.Seal("session", []byte("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Seal("session", []byte("user-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("sealing the same value twice returned the same string")
+	}
+}
+
+func TestCookie(t *testing.T) {
+	type session struct {
+		UserID string `json:"userId"`
+	}
+
+	c := mustNew(t, key1)
+	c.maxAge = time.Hour
+
+	w := httptest.NewRecorder()
+	err := c.SetCookie(w, &http.Cookie{Name: "session", Path: "/", HttpOnly: true}, session{UserID: "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge != 3600 || strings.Contains(cookies[0].Value, "user-1") {
+		t.Fatalf("cookies = %v, want one sealed session cookie expiring in an hour", cookies)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+
+	var got session
+	if err := c.ReadCookie(r, "session", &got); err != nil {
+		t.Fatalf("ReadCookie: %v", err)
+	}
+	if got.UserID != "user-1" {
+		t.Errorf("ReadCookie = %+v, want user-1", got)
+	}
+
+	// a sealed value cannot be moved to another cookie
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "remember", Value: cookies[0].Value})
+	if err := c.ReadCookie(r, "remember", &got); !errors.Is(err, ErrInvalid) {
+		t.Errorf("ReadCookie of a moved value = %v, want ErrInvalid", err)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := c.ReadCookie(r, "session", &got); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("ReadCookie without cookie = %v, want http.ErrNoCookie", err)
+	}
+}
+
+func mustNew(t *testing.T, keys ...[]byte) *Codec {
+	t.Helper()
+
+	c, err := New(keys, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
@@ -0,0 +1,130 @@
+package credentials
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Default parameters of Argon2id, following the OWASP recommendations.
+const (
+	defaultArgon2Time    = 2
+	defaultArgon2Memory  = 19 * 1024 // KiB
+	defaultArgon2Threads = 1
+)
+
+// Argon2id is the Argon2id Algorithm of RFC 9106, with hashes encoded as
+// "$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>".
+type Argon2id struct {
+	// Time is the number of passes over the memory. Defaults to 2.
+	Time uint32
+
+	// Memory is the size of the memory in KiB. Defaults to 19 MiB.
+	Memory uint32
+
+	// Threads is the number of threads. Defaults to 1.
+	Threads uint8
+
+	// SaltLen is the length of salts in bytes. Defaults to 16.
+	SaltLen int
+
+	// KeyLen is the length of derived keys in bytes. Defaults to 32.
+	KeyLen int
+}
+
+// argon2Params are the parameters of an Argon2id hash.
+type argon2Params struct {
+	time, memory uint32
+	threads      uint8
+}
+
+// ID implements the Algorithm interface.
+func (a *Argon2id) ID() string {
+	return "argon2id"
+}
+
+// Hash implements the Algorithm interface.
+func (a *Argon2id) Hash(password []byte) (string, error) {
+	p, saltLen, keyLen := a.params()
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %w", err)
+	}
+
+	key := argon2.IDKey(password, salt, p.time, p.memory, p.threads, uint32(keyLen))
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		a.ID(), argon2.Version, p.memory, p.time, p.threads, b64.EncodeToString(salt), b64.EncodeToString(key),
+	), nil
+}
+
+// Verify implements the Algorithm interface.
+func (a *Argon2id) Verify(password []byte, encoded string) (bool, error) {
+	p, salt, key, err := a.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	derived := argon2.IDKey(password, salt, p.time, p.memory, p.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(derived, key) == 1, nil
+}
+
+// NeedsRehash implements the Algorithm interface.
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	want, saltLen, keyLen := a.params()
+
+	p, salt, key, err := a.decode(encoded)
+	return err != nil || p.time < want.time || p.memory < want.memory || p.threads < want.threads ||
+		len(salt) < saltLen || len(key) < keyLen
+}
+
+func (a *Argon2id) params() (p argon2Params, saltLen, keyLen int) {
+	p = argon2Params{time: a.Time, memory: a.Memory, threads: a.Threads}
+	if p.time == 0 {
+		p.time = defaultArgon2Time
+	}
+	if p.memory == 0 {
+		p.memory = defaultArgon2Memory
+	}
+	if p.threads == 0 {
+		p.threads = defaultArgon2Threads
+	}
+
+	saltLen, keyLen = a.SaltLen, a.KeyLen
+	if saltLen <= 0 {
+		saltLen = defaultSaltLen
+	}
+	if keyLen <= 0 {
+		keyLen = defaultKeyLen
+	}
+	return p, saltLen, keyLen
+}
+
+func (a *Argon2id) decode(encoded string) (p argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != a.ID() || parts[2] != "v="+strconv.Itoa(argon2.Version) {
+		return p, nil, nil, ErrMalformedHash
+	}
+
+	var threads uint32
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &threads)
+	if err != nil || p.memory == 0 || p.time == 0 || threads == 0 || threads > 255 {
+		return p, nil, nil, ErrMalformedHash
+	}
+	p.threads = uint8(threads)
+
+	salt, err = b64.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrMalformedHash
+	}
+	key, err = b64.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrMalformedHash
+	}
+	return p, salt, key, nil
+}
@@ -0,0 +1,54 @@
+package credentials
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt is the bcrypt Algorithm, with hashes in their modular crypt format,
+// e.g. "$2a$12$<salt and key>". Passwords longer than 72 bytes are rejected.
+type Bcrypt struct {
+	// Cost is the base-2 logarithm of the number of iterations. Defaults to 12.
+	Cost int
+}
+
+// ID implements the Algorithm interface. Hashes of the "2b" and "2y"
+// variants are verified by the algorithm too.
+func (b *Bcrypt) ID() string {
+	return "2a"
+}
+
+// Hash implements the Algorithm interface.
+func (b *Bcrypt) Hash(password []byte) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword(password, b.cost())
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify implements the Algorithm interface.
+func (b *Bcrypt) Verify(password []byte, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), password)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	}
+	return false, ErrMalformedHash
+}
+
+// NeedsRehash implements the Algorithm interface.
+func (b *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < b.cost()
+}
+
+func (b *Bcrypt) cost() int {
+	if b.Cost <= 0 {
+		return 12
+	}
+	return b.Cost
+}
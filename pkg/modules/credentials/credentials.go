@@ -0,0 +1,151 @@
+// Package credentials provides a godi module exposing a PasswordHasher, so the modules
+// authenticating users share one implementation of password hashing and verification.
+//
+// Passwords are hashed with Argon2id by default, or with bcrypt, both implemented by
+// golang.org/x/crypto. Hashes are encoded in the PHC string format, or the modular crypt
+// format for bcrypt, e.g. "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>", and name the
+// algorithm and parameters they were made with. Hashes made with a legacy algorithm or
+// weaker parameters than the current ones are upgraded on verification:
+//
+//	Imports: []godi.Module{
+//		&credentials.Module{
+//			Algorithm: &credentials.Argon2id{Memory: 64 * 1024},
+//			Legacy:    []credentials.Algorithm{&credentials.Bcrypt{}, &credentials.PBKDF2{}},
+//		},
+//	}
+//
+//	ok, rehashed, err := c.hasher.Verify(password, user.PasswordHash)
+//	if ok && rehashed != "" {
+//		err = c.users.SetPasswordHash(ctx, user.ID, rehashed)
+//	}
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/huboh/godi"
+)
+
+var (
+	// ErrUnknownAlgorithm is returned when verifying a hash made with an algorithm that is
+	// neither the current nor a legacy one.
+	ErrUnknownAlgorithm = errors.New("credentials: unknown hash algorithm")
+
+	// ErrMalformedHash is returned by Algorithms when verifying a hash they cannot decode.
+	ErrMalformedHash = errors.New("credentials: malformed hash")
+)
+
+// Algorithm is a password hashing algorithm.
+type Algorithm interface {
+	// ID is the identifier of the algorithm in the hashes it makes, e.g. "pbkdf2-sha256".
+	ID() string
+
+	// Hash returns the encoded hash of the password with a random salt.
+	Hash(password []byte) (string, error)
+
+	// Verify reports whether the encoded hash is the hash of the password.
+	Verify(password []byte, encoded string) (bool, error)
+
+	// NeedsRehash reports whether the encoded hash was made with weaker parameters
+	// than the algorithm's current ones.
+	NeedsRehash(encoded string) bool
+}
+
+// Module provides the PasswordHasher to every module of the application.
+type Module struct {
+	// Algorithm hashes passwords. Defaults to Argon2id with its default parameters.
+	Algorithm Algorithm
+
+	// Legacy lists the algorithms of existing hashes, which are verified and upgraded
+	// to Algorithm on successful verification.
+	Legacy []Algorithm
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	for i, alg := range m.Legacy {
+		if alg == nil {
+			return fmt.Errorf("credentials: legacy algorithm at index %d is nil", i)
+		}
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newPasswordHasher},
+		ExportsCtors:   []godi.ProviderConstructor{m.newPasswordHasher},
+	}
+}
+
+func (m *Module) newPasswordHasher() *PasswordHasher {
+	alg := m.Algorithm
+	if alg == nil {
+		alg = &Argon2id{}
+	}
+	return NewPasswordHasher(alg, m.Legacy...)
+}
+
+// PasswordHasher hashes passwords and verifies them against their hashes.
+type PasswordHasher struct {
+	current Algorithm
+	byID    map[string]Algorithm
+}
+
+// NewPasswordHasher returns a PasswordHasher hashing passwords with alg, and verifying
+// hashes made with it or with the legacy algorithms.
+func NewPasswordHasher(alg Algorithm, legacy ...Algorithm) *PasswordHasher {
+	h := &PasswordHasher{current: alg, byID: map[string]Algorithm{}}
+	for _, l := range legacy {
+		h.byID[l.ID()] = l
+	}
+	h.byID[alg.ID()] = alg
+	return h
+}
+
+// Hash returns the encoded hash of the password.
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	encoded, err := h.current.Hash([]byte(password))
+	if err != nil {
+		return "", fmt.Errorf("credentials: error hashing password: %w", err)
+	}
+	return encoded, nil
+}
+
+// Verify reports whether the encoded hash is the hash of the password. If it is, and the
+// hash was made with a legacy algorithm or weaker parameters, the password is hashed again
+// and the new hash is returned so it can replace the stored one.
+func (h *PasswordHasher) Verify(password, encoded string) (ok bool, rehashed string, err error) {
+	id := hashID(encoded)
+
+	alg, found := h.byID[id]
+	if !found {
+		return false, "", fmt.Errorf("%w (%s)", ErrUnknownAlgorithm, id)
+	}
+
+	ok, err = alg.Verify([]byte(password), encoded)
+	if err != nil || !ok {
+		return false, "", err
+	}
+
+	if alg != h.current || h.current.NeedsRehash(encoded) {
+		rehashed, err = h.Hash(password)
+		if err != nil {
+			return true, "", err
+		}
+	}
+	return true, rehashed, nil
+}
+
+// hashID returns the algorithm identifier of a PHC-formatted hash, identifying
+// the variants of bcrypt hashes by the one of Bcrypt.
+func hashID(encoded string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(encoded, "$"), "$")
+	if id == "2b" || id == "2y" {
+		return "2a"
+	}
+	return id
+}
@@ -0,0 +1,204 @@
+package credentials
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Parameters cheap enough for tests.
+var (
+	testArgon2 = &Argon2id{Time: 1, Memory: 64}
+	testBcrypt = &Bcrypt{Cost: 4}
+	testPBKDF2 = &PBKDF2{Iterations: 1000}
+)
+
+func TestAlgorithms(t *testing.T) {
+	for _, alg := range []Algorithm{testArgon2, testBcrypt, testPBKDF2} {
+		t.Run(alg.ID(), func(t *testing.T) {
+			encoded, err := alg.Hash([]byte("correct horse"))
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+			if hashID(encoded) != alg.ID() {
+				t.Errorf("hash %q does not name the algorithm %q", encoded, alg.ID())
+			}
+
+			again, err := alg.Hash([]byte("correct horse"))
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+			if again == encoded {
+				t.Error("hashing the same password twice returned the same hash")
+			}
+
+			tests := []struct {
+				password string
+				want     bool
+			}{
+				{"correct horse", true},
+				{"correct horse ", false},
+				{"Correct horse", false},
+				{"", false},
+			}
+			for _, tt := range tests {
+				ok, err := alg.Verify([]byte(tt.password), encoded)
+				if err != nil || ok != tt.want {
+					t.Errorf("Verify(%q) = %v, %v, want %v", tt.password, ok, err, tt.want)
+				}
+			}
+
+			if alg.NeedsRehash(encoded) {
+				t.Error("NeedsRehash of a hash made with the current parameters = true")
+			}
+		})
+	}
+}
+
+func TestKnownHashes(t *testing.T) {
+	tests := []struct {
+		name     string
+		alg      Algorithm
+		password string
+		encoded  string
+	}{
+		{
+			// computed with Python's hashlib.pbkdf2_hmac
+			"pbkdf2-sha256", testPBKDF2, "password",
+			"$pbkdf2-sha256$i=1000$c2FsdHNhbHRzYWx0c2FsdA$8nX7hwFEzIB8aPajJTYK8weHQc5Ngz0pFVAKvSu4jQA",
+		},
+		{
+			// from the test vectors of OpenBSD's bcrypt
+			"bcrypt", testBcrypt, "U*U",
+			"$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := tt.alg.Verify([]byte(tt.password), tt.encoded)
+			if err != nil || !ok {
+				t.Errorf("Verify = %v, %v, want true", ok, err)
+			}
+		})
+	}
+}
+
+func TestMalformedHashes(t *testing.T) {
+	tests := []struct {
+		name    string
+		alg     Algorithm
+		encoded string
+	}{
+		{"argon2id missing key", testArgon2, "$argon2id$v=19$m=64,t=1,p=1$c2FsdA"},
+		{"argon2id other version", testArgon2, "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5"},
+		{"argon2id zero memory", testArgon2, "$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5"},
+		{"argon2id too many threads", testArgon2, "$argon2id$v=19$m=64,t=1,p=256$c2FsdA$a2V5"},
+		{"argon2id bad salt", testArgon2, "$argon2id$v=19$m=64,t=1,p=1$!!$a2V5"},
+		{"argon2id empty key", testArgon2, "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$"},
+		{"bcrypt truncated", testBcrypt, "$2a$04$CCCCCCCCCCCCCCCCCCCCC."},
+		{"pbkdf2 missing iterations", testPBKDF2, "$pbkdf2-sha256$c2FsdA$a2V5"},
+		{"pbkdf2 zero iterations", testPBKDF2, "$pbkdf2-sha256$i=0$c2FsdA$a2V5"},
+		{"pbkdf2 bad key", testPBKDF2, "$pbkdf2-sha256$i=1000$c2FsdA$!!"},
+		{"other algorithm", testPBKDF2, "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := tt.alg.Verify([]byte("password"), tt.encoded)
+			if ok || !errors.Is(err, ErrMalformedHash) {
+				t.Errorf("Verify = %v, %v, want ErrMalformedHash", ok, err)
+			}
+			if !tt.alg.NeedsRehash(tt.encoded) {
+				t.Error("NeedsRehash of a malformed hash = false")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherVerify(t *testing.T) {
+	hash := func(alg Algorithm) string {
+		encoded, err := alg.Hash([]byte("correct horse"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+
+	var (
+		current = hash(testArgon2)
+		bcrypt  = hash(testBcrypt)
+		pbkdf2  = hash(testPBKDF2)
+		weaker  = hash(&Argon2id{Time: 1, Memory: 32})
+		shorter = hash(&Argon2id{Time: 1, Memory: 64, KeyLen: 16})
+		h       = NewPasswordHasher(testArgon2, testBcrypt, testPBKDF2)
+	)
+
+	tests := []struct {
+		name       string
+		password   string
+		encoded    string
+		wantOK     bool
+		wantRehash bool
+		wantErr    error
+	}{
+		{"current", "correct horse", current, true, false, nil},
+		{"weaker parameters", "correct horse", weaker, true, true, nil},
+		{"shorter key", "correct horse", shorter, true, true, nil},
+		{"legacy bcrypt", "correct horse", bcrypt, true, true, nil},
+		{"legacy bcrypt 2b", "correct horse", strings.Replace(bcrypt, "$2a$", "$2b$", 1), true, true, nil},
+		{"legacy pbkdf2", "correct horse", pbkdf2, true, true, nil},
+		{"wrong password", "battery staple", current, false, false, nil},
+		{"wrong legacy password", "battery staple", pbkdf2, false, false, nil},
+		{"unknown algorithm", "correct horse", "$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5", false, false, ErrUnknownAlgorithm},
+		{"not a hash", "correct horse", "correct horse", false, false, ErrUnknownAlgorithm},
+		{"malformed", "correct horse", "$argon2id$v=19$m=64", false, false, ErrMalformedHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehashed, err := h.Verify(tt.password, tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify error = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("Verify = %v, want %v", ok, tt.wantOK)
+			}
+			if (rehashed != "") != tt.wantRehash {
+				t.Fatalf("rehashed = %q, want a rehash %v", rehashed, tt.wantRehash)
+			}
+			if rehashed == "" {
+				return
+			}
+
+			// the new hash is made with the current algorithm and parameters
+			if hashID(rehashed) != testArgon2.ID() || testArgon2.NeedsRehash(rehashed) {
+				t.Errorf("rehashed = %q, want a current argon2id hash", rehashed)
+			}
+			ok, again, err := h.Verify(tt.password, rehashed)
+			if err != nil || !ok || again != "" {
+				t.Errorf("Verify of the rehashed hash = %v, %q, %v, want true without rehash", ok, again, err)
+			}
+		})
+	}
+}
+
+func TestHashID(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    string
+	}{
+		{"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5", "argon2id"},
+		{"$pbkdf2-sha256$i=1000$c2FsdA$a2V5", "pbkdf2-sha256"},
+		{"$2a$04$CCCCCCCCCCCCCCCCCCCCC.", "2a"},
+		{"$2b$04$CCCCCCCCCCCCCCCCCCCCC.", "2a"},
+		{"$2y$04$CCCCCCCCCCCCCCCCCCCCC.", "2a"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := hashID(tt.encoded); got != tt.want {
+			t.Errorf("hashID(%q) = %q, want %q", tt.encoded, got, tt.want)
+		}
+	}
+}
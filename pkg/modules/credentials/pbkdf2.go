@@ -0,0 +1,108 @@
+package credentials
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Default parameters of PBKDF2, following the OWASP recommendations for PBKDF2-HMAC-SHA256.
+const (
+	defaultIterations = 600_000
+	defaultSaltLen    = 16
+	defaultKeyLen     = 32
+)
+
+// PBKDF2 is the PBKDF2-HMAC-SHA256 Algorithm, with hashes encoded as
+// "$pbkdf2-sha256$i=<iterations>$<salt>$<key>". It is meant to verify the hashes
+// of existing systems as a legacy algorithm, Argon2id being preferred for new ones.
+type PBKDF2 struct {
+	// Iterations is the number of iterations. Defaults to 600,000.
+	Iterations int
+
+	// SaltLen is the length of salts in bytes. Defaults to 16.
+	SaltLen int
+
+	// KeyLen is the length of derived keys in bytes. Defaults to 32.
+	KeyLen int
+}
+
+// ID implements the Algorithm interface.
+func (p *PBKDF2) ID() string {
+	return "pbkdf2-sha256"
+}
+
+// Hash implements the Algorithm interface.
+func (p *PBKDF2) Hash(password []byte) (string, error) {
+	iterations, saltLen, keyLen := p.params()
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %w", err)
+	}
+
+	key := pbkdf2.Key(password, salt, iterations, keyLen, sha256.New)
+	return "$" + p.ID() + "$i=" + strconv.Itoa(iterations) + "$" + b64.EncodeToString(salt) + "$" + b64.EncodeToString(key), nil
+}
+
+// Verify implements the Algorithm interface.
+func (p *PBKDF2) Verify(password []byte, encoded string) (bool, error) {
+	iterations, salt, key, err := p.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(pbkdf2.Key(password, salt, iterations, len(key), sha256.New), key) == 1, nil
+}
+
+// NeedsRehash implements the Algorithm interface.
+func (p *PBKDF2) NeedsRehash(encoded string) bool {
+	iterations, saltLen, keyLen := p.params()
+
+	i, salt, key, err := p.decode(encoded)
+	return err != nil || i < iterations || len(salt) < saltLen || len(key) < keyLen
+}
+
+func (p *PBKDF2) params() (iterations, saltLen, keyLen int) {
+	iterations, saltLen, keyLen = p.Iterations, p.SaltLen, p.KeyLen
+	if iterations <= 0 {
+		iterations = defaultIterations
+	}
+	if saltLen <= 0 {
+		saltLen = defaultSaltLen
+	}
+	if keyLen <= 0 {
+		keyLen = defaultKeyLen
+	}
+	return iterations, saltLen, keyLen
+}
+
+func (p *PBKDF2) decode(encoded string) (iterations int, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != p.ID() || !strings.HasPrefix(parts[2], "i=") {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	iterations, err = strconv.Atoi(strings.TrimPrefix(parts[2], "i="))
+	if err != nil || iterations <= 0 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+
+	salt, err = b64.DecodeString(parts[3])
+	if err != nil {
+		return 0, nil, nil, ErrMalformedHash
+	}
+	key, err = b64.DecodeString(parts[4])
+	if err != nil || len(key) == 0 {
+		return 0, nil, nil, ErrMalformedHash
+	}
+	return iterations, salt, key, nil
+}
+
+// b64 is the unpadded base64 encoding of the PHC string format.
+var b64 = base64.RawStdEncoding
@@ -0,0 +1,183 @@
+// Package diagnostics provides an opt-in godi module that mounts net/http/pprof,
+// expvar and runtime statistics endpoints on the application's server, so
+// production debugging doesn't require a second server.
+//
+// The endpoints expose sensitive information and must be protected with guards, which
+// the module requires unless AllowUnauthenticated is set:
+//
+//	Imports: []godi.Module{
+//		&diagnostics.Module{
+//			Prefix: "/_ops",
+//			Guards: []godi.Guard{&AdminGuard{}},
+//		},
+//	}
+//
+// mounts the following endpoints:
+//
+//	GET  /_ops/pprof/           index of the available profiles
+//	GET  /_ops/pprof/{profile}  a named profile, e.g. heap, goroutine, allocs
+//	GET  /_ops/pprof/cmdline    the running program's command line
+//	GET  /_ops/pprof/profile    a CPU profile
+//	GET  /_ops/pprof/symbol     program counter to function name lookups
+//	POST /_ops/pprof/symbol
+//	GET  /_ops/pprof/trace      an execution trace
+//	GET  /_ops/vars             expvar variables
+//	GET  /_ops/runtime          goroutine, memory and garbage collector statistics
+package diagnostics
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// defaultPrefix is the path the endpoints are mounted under when no prefix is set.
+const defaultPrefix = "/debug"
+
+// Module mounts the diagnostics endpoints.
+type Module struct {
+	// Prefix is the path the endpoints are mounted under. Defaults to "/debug".
+	Prefix string
+
+	// Guards are applied to every diagnostics endpoint.
+	Guards []godi.Guard
+
+	// GuardsCtors provides constructors for guards that require dependency injection.
+	GuardsCtors []godi.GuardConstructor
+
+	// AllowUnauthenticated mounts the endpoints without guards, e.g. when the application is
+	// only reachable from a private network. By default, a module without guards is invalid.
+	AllowUnauthenticated bool
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	if len(m.Guards) == 0 && len(m.GuardsCtors) == 0 && !m.AllowUnauthenticated {
+		return errors.New("diagnostics: no guards configured, set AllowUnauthenticated to mount the endpoints without any")
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		ControllersCtors: []godi.ControllerConstructor{
+			func() *controller { return &controller{module: m} },
+		},
+	}
+}
+
+// controller serves the diagnostics endpoints.
+type controller struct {
+	module *Module
+}
+
+func (c *controller) Config() *godi.ControllerConfig {
+	return &godi.ControllerConfig{
+		Pattern:     cmp.Or(c.module.Prefix, defaultPrefix),
+		Guards:      c.module.Guards,
+		GuardsCtors: c.module.GuardsCtors,
+		RoutesCfgs: []*godi.RouteConfig{
+			{Method: http.MethodGet, Pattern: "/pprof/{$}", Handler: http.HandlerFunc(pprof.Index)},
+			{Method: http.MethodGet, Pattern: "/pprof/{profile}", Handler: http.HandlerFunc(handleProfile)},
+			{Method: http.MethodGet, Pattern: "/pprof/cmdline", Handler: http.HandlerFunc(pprof.Cmdline)},
+			{Method: http.MethodGet, Pattern: "/pprof/profile", Handler: http.HandlerFunc(pprof.Profile)},
+			{Method: http.MethodGet, Pattern: "/pprof/symbol", Handler: http.HandlerFunc(pprof.Symbol)},
+			{Method: http.MethodPost, Pattern: "/pprof/symbol", Handler: http.HandlerFunc(pprof.Symbol)},
+			{Method: http.MethodGet, Pattern: "/pprof/trace", Handler: http.HandlerFunc(pprof.Trace)},
+			{Method: http.MethodGet, Pattern: "/vars", Handler: expvar.Handler()},
+			{Method: http.MethodGet, Pattern: "/runtime", Handler: http.HandlerFunc(handleRuntime)},
+		},
+	}
+}
+
+// handleProfile serves a named profile. pprof.Index only serves named profiles
+// under "/debug/pprof/", so they are served explicitly to support other prefixes.
+func handleProfile(w http.ResponseWriter, r *http.Request) {
+	pprof.Handler(r.PathValue("profile")).ServeHTTP(w, r)
+}
+
+// RuntimeStats holds goroutine, memory and garbage collector statistics.
+type RuntimeStats struct {
+	Goroutines int         `json:"goroutines"`
+	NumCPU     int         `json:"numCPU"`
+	GoVersion  string      `json:"goVersion"`
+	Memory     MemoryStats `json:"memory"`
+	GC         GCStats     `json:"gc"`
+}
+
+// MemoryStats holds a summary of runtime.MemStats.
+type MemoryStats struct {
+	HeapAlloc    uint64  `json:"heapAlloc"`
+	HeapInuse    uint64  `json:"heapInuse"`
+	HeapIdle     uint64  `json:"heapIdle"`
+	HeapObjects  uint64  `json:"heapObjects"`
+	StackInuse   uint64  `json:"stackInuse"`
+	Sys          uint64  `json:"sys"`
+	TotalAlloc   uint64  `json:"totalAlloc"`
+	Mallocs      uint64  `json:"mallocs"`
+	Frees        uint64  `json:"frees"`
+	NextGC       uint64  `json:"nextGC"`
+	GCCPUPercent float64 `json:"gcCPUPercent"`
+}
+
+// GCStats holds a summary of debug.GCStats.
+type GCStats struct {
+	NumGC      int64         `json:"numGC"`
+	LastGC     time.Time     `json:"lastGC"`
+	PauseTotal time.Duration `json:"pauseTotal"`
+	LastPause  time.Duration `json:"lastPause"`
+}
+
+// ReadRuntimeStats returns the current runtime statistics.
+func ReadRuntimeStats() RuntimeStats {
+	var (
+		mem runtime.MemStats
+		gc  debug.GCStats
+	)
+
+	runtime.ReadMemStats(&mem)
+	debug.ReadGCStats(&gc)
+
+	stats := RuntimeStats{
+		Goroutines: runtime.NumGoroutine(),
+		NumCPU:     runtime.NumCPU(),
+		GoVersion:  runtime.Version(),
+		Memory: MemoryStats{
+			HeapAlloc:    mem.HeapAlloc,
+			HeapInuse:    mem.HeapInuse,
+			HeapIdle:     mem.HeapIdle,
+			HeapObjects:  mem.HeapObjects,
+			StackInuse:   mem.StackInuse,
+			Sys:          mem.Sys,
+			TotalAlloc:   mem.TotalAlloc,
+			Mallocs:      mem.Mallocs,
+			Frees:        mem.Frees,
+			NextGC:       mem.NextGC,
+			GCCPUPercent: mem.GCCPUFraction * 100,
+		},
+		GC: GCStats{
+			NumGC:      gc.NumGC,
+			LastGC:     gc.LastGC,
+			PauseTotal: gc.PauseTotal,
+		},
+	}
+
+	if len(gc.Pause) > 0 {
+		stats.GC.LastPause = gc.Pause[0]
+	}
+
+	return stats
+}
+
+func handleRuntime(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ReadRuntimeStats())
+}
@@ -0,0 +1,113 @@
+package discovery
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Consul is a Registry backed by the HTTP API of the local Consul agent.
+type Consul struct {
+	// Addr is the address of the Consul agent. Defaults to "http://127.0.0.1:8500".
+	Addr string
+
+	// Token is the ACL token sent with requests, if any.
+	Token string
+
+	// CheckInterval is how often Consul checks the instance's health path. Defaults to "10s".
+	CheckInterval string
+
+	// DeregisterAfter makes Consul deregister instances that stay critical for the
+	// given duration, e.g. "1m", cleaning up after crashed instances.
+	DeregisterAfter string
+
+	// Client sends the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// consulRegistration is the body of Consul's service registration endpoint.
+type consulRegistration struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   *consulCheck      `json:"Check,omitempty"`
+}
+
+type consulCheck struct {
+	HTTP                           string `json:"HTTP"`
+	Interval                       string `json:"Interval"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+// Register implements the Registry interface.
+func (c *Consul) Register(ctx context.Context, instance Instance) error {
+	reg := consulRegistration{
+		ID:      instance.ID,
+		Name:    instance.Name,
+		Address: instance.Address,
+		Port:    instance.Port,
+		Tags:    instance.Tags,
+		Meta:    instance.Meta,
+	}
+
+	if instance.HealthPath != "" {
+		reg.Check = &consulCheck{
+			HTTP:                           "http://" + net.JoinHostPort(instance.Address, strconv.Itoa(instance.Port)) + instance.HealthPath,
+			Interval:                       cmp.Or(c.CheckInterval, "10s"),
+			DeregisterCriticalServiceAfter: c.DeregisterAfter,
+		}
+	}
+
+	body, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+
+	return c.do(ctx, "/v1/agent/service/register", body)
+}
+
+// Deregister implements the Registry interface.
+func (c *Consul) Deregister(ctx context.Context, instance Instance) error {
+	return c.do(ctx, "/v1/agent/service/deregister/"+url.PathEscape(instance.ID), nil)
+}
+
+func (c *Consul) do(ctx context.Context, path string, body []byte) error {
+	addr := strings.TrimSuffix(cmp.Or(c.Addr, "http://127.0.0.1:8500"), "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("consul responded with %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package discovery provides a godi module registering the application with a
+// service registry, such as Consul, when the server starts listening, and
+// deregistering it as soon as the server shuts down, before requests drain.
+//
+//	Imports: []godi.Module{
+//		&discovery.Module{
+//			Registry:   &discovery.Consul{Addr: "http://consul:8500"},
+//			Name:       "orders",
+//			Tags:       []string{"v2"},
+//			HealthPath: "/readyz",
+//		},
+//	}
+//
+// Other registries, like etcd or Eureka, are supported by implementing [Registry].
+package discovery
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/huboh/godi"
+)
+
+// Instance describes a running instance of the application.
+type Instance struct {
+	// ID uniquely identifies the instance in the registry.
+	ID string
+
+	// Name is the name of the service the instance belongs to.
+	Name string
+
+	// Address and Port are where the instance is reachable.
+	Address string
+	Port    int
+
+	// Tags and Meta are attached to the instance in the registry.
+	Tags []string
+	Meta map[string]string
+
+	// HealthPath is the path the registry checks the instance's health on, if any.
+	HealthPath string
+}
+
+// Registry registers and deregisters application instances with a service registry.
+type Registry interface {
+	Register(ctx context.Context, instance Instance) error
+	Deregister(ctx context.Context, instance Instance) error
+}
+
+// Module registers the application with the registry while it serves requests.
+type Module struct {
+	// Registry is the service registry the application is registered with.
+	Registry Registry
+
+	// Name is the name of the service.
+	Name string
+
+	// ID identifies the instance. Defaults to the name, host name and process ID.
+	ID string
+
+	// Address is the address advertised to the registry. Defaults to the address the
+	// server listens on, or the host name when the server listens on all interfaces.
+	Address string
+
+	// Tags and Meta are attached to the instance in the registry.
+	Tags []string
+	Meta map[string]string
+
+	// HealthPath is the path the registry checks the instance's health on, e.g. the
+	// readiness endpoint mounted with godi.WithReadinessEndpoint.
+	HealthPath string
+
+	mu       sync.Mutex
+	instance *Instance
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{}
+}
+
+// Start registers the instance once the server listens on addr.
+func (m *Module) Start(ctx context.Context, addr net.Addr) error {
+	if m.Registry == nil {
+		return errors.New("discovery: no registry configured")
+	}
+	if m.Name == "" {
+		return errors.New("discovery: no service name configured")
+	}
+
+	instance, err := m.newInstance(addr)
+	if err != nil {
+		return err
+	}
+
+	err = m.Registry.Register(ctx, instance)
+	if err != nil {
+		return fmt.Errorf("error registering instance (%s): %w", instance.ID, err)
+	}
+
+	m.mu.Lock()
+	m.instance = &instance
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Stop deregisters the instance, so the registry stops routing traffic to it
+// before in-flight requests are drained.
+func (m *Module) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	instance := m.instance
+	m.instance = nil
+	m.mu.Unlock()
+
+	if instance == nil {
+		return nil
+	}
+
+	err := m.Registry.Deregister(ctx, *instance)
+	if err != nil {
+		return fmt.Errorf("error deregistering instance (%s): %w", instance.ID, err)
+	}
+	return nil
+}
+
+func (m *Module) newInstance(addr net.Addr) (Instance, error) {
+	host, portStr, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return Instance{}, err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return Instance{}, err
+	}
+
+	hostname, _ := os.Hostname()
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = hostname
+	}
+
+	return Instance{
+		ID:         cmp.Or(m.ID, fmt.Sprintf("%s-%s-%d", m.Name, hostname, os.Getpid())),
+		Name:       m.Name,
+		Address:    cmp.Or(m.Address, host),
+		Port:       port,
+		Tags:       m.Tags,
+		Meta:       m.Meta,
+		HealthPath: m.HealthPath,
+	}, nil
+}
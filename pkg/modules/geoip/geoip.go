@@ -0,0 +1,270 @@
+// Package geoip provides a godi module resolving the country and reputation of
+// client IP addresses with a pluggable Provider, and a guard blocking requests by
+// country or reputation score.
+//
+// The guard applies the module's policy, which controllers and routes override
+// through their metadata:
+//
+//	Imports: []godi.Module{
+//		&geoip.Module{
+//			Provider: geoip.NewHTTPProvider("https://ip-intel.internal/lookup/%s"),
+//			Policy:   geoip.Policy{BlockCountries: []string{"KP"}, MaxScore: 80},
+//		},
+//	}
+//
+//	func (c *CheckoutController) Config() *godi.ControllerConfig {
+//		return &godi.ControllerConfig{
+//			GuardsCtors: []godi.GuardConstructor{geoip.NewGuard},
+//			Metadata: map[string]string{
+//				geoip.AllowCountriesKey: "US,CA",
+//				geoip.MaxScoreKey:       "50",
+//			},
+//			...
+//		}
+//	}
+//
+// Rejected requests are rendered with 403 and ErrBlocked.
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Metadata keys overriding the module's Policy for a controller or route. Lists are comma-separated.
+const (
+	AllowCountriesKey = "geoip.allowCountries"
+	BlockCountriesKey = "geoip.blockCountries"
+	MaxScoreKey       = "geoip.maxScore"
+)
+
+// maxCached bounds the number of cached lookups, which are discarded once reached.
+const maxCached = 100_000
+
+// ErrBlocked is rendered with 403 when a request is blocked by the guard.
+var ErrBlocked = errors.New("geoip: blocked")
+
+// Info is the intelligence about an IP address.
+type Info struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country the address is located in,
+	// e.g. "US", or empty if unknown.
+	Country string
+
+	// Score is the abuse score of the address from 0 (trusted) to 100 (malicious).
+	Score int
+}
+
+// Provider looks up the intelligence about IP addresses, e.g. from a MaxMind database
+// or an external reputation API.
+type Provider interface {
+	Lookup(ctx context.Context, ip netip.Addr) (Info, error)
+}
+
+// ProviderFunc is a function implementing Provider.
+type ProviderFunc func(ctx context.Context, ip netip.Addr) (Info, error)
+
+// Lookup implements the Provider interface.
+func (fn ProviderFunc) Lookup(ctx context.Context, ip netip.Addr) (Info, error) {
+	return fn(ctx, ip)
+}
+
+// Policy lists the countries and reputation allowed to access routes.
+type Policy struct {
+	// AllowCountries lists the only countries allowed, if set.
+	// Requests from addresses of unknown country are then blocked.
+	AllowCountries []string
+
+	// BlockCountries lists the countries blocked.
+	BlockCountries []string
+
+	// MaxScore is the highest abuse score allowed. Scores are not checked if zero.
+	MaxScore int
+}
+
+// allows reports whether the policy allows an address with the info.
+func (p Policy) allows(info Info) bool {
+	country := strings.ToUpper(info.Country)
+	if len(p.AllowCountries) > 0 && !slices.Contains(p.AllowCountries, country) {
+		return false
+	}
+	if slices.Contains(p.BlockCountries, country) && country != "" {
+		return false
+	}
+	return p.MaxScore == 0 || info.Score <= p.MaxScore
+}
+
+// override returns the policy overridden by the metadata, which take precedence in order.
+func (p Policy) override(metadata ...any) (Policy, error) {
+	if v, ok := godi.MetadataValue(AllowCountriesKey, metadata...); ok {
+		p.AllowCountries = countries(v)
+	}
+	if v, ok := godi.MetadataValue(BlockCountriesKey, metadata...); ok {
+		p.BlockCountries = countries(v)
+	}
+	if v, ok := godi.MetadataValue(MaxScoreKey, metadata...); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return p, fmt.Errorf("invalid %s metadata (%s): %w", MaxScoreKey, v, err)
+		}
+		p.MaxScore = n
+	}
+	return p, nil
+}
+
+// Module provides the Lookup service resolving client IP addresses to every module of the application.
+type Module struct {
+	// Provider looks up the intelligence about IP addresses.
+	Provider Provider
+
+	// Policy is the policy applied by the guard to routes without metadata overriding it.
+	Policy Policy
+
+	// CacheTTL is how long lookups are cached. Defaults to an hour; a negative TTL disables the cache.
+	CacheTTL time.Duration
+
+	// FailOpen allows requests whose address cannot be looked up, e.g. when the provider
+	// is unavailable. By default, they are rejected with 500.
+	FailOpen bool
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	if m.Provider == nil {
+		return errors.New("geoip: no provider configured")
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newLookup},
+		ExportsCtors:   []godi.ProviderConstructor{m.newLookup},
+	}
+}
+
+func (m *Module) newLookup() *Lookup {
+	ttl := m.CacheTTL
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	return &Lookup{provider: m.Provider, policy: normalize(m.Policy), ttl: ttl, failOpen: m.FailOpen, cache: map[netip.Addr]cached{}}
+}
+
+// Lookup resolves IP addresses with the module's provider, caching the results.
+type Lookup struct {
+	provider Provider
+	policy   Policy
+	ttl      time.Duration
+	failOpen bool
+
+	mu    sync.Mutex
+	cache map[netip.Addr]cached
+}
+
+type cached struct {
+	info    Info
+	expires time.Time
+}
+
+// Lookup returns the intelligence about the IP address.
+func (l *Lookup) Lookup(ctx context.Context, ip netip.Addr) (Info, error) {
+	ip = ip.Unmap()
+
+	if l.ttl > 0 {
+		l.mu.Lock()
+		c, ok := l.cache[ip]
+		l.mu.Unlock()
+
+		if ok && time.Now().Before(c.expires) {
+			return c.info, nil
+		}
+	}
+
+	info, err := l.provider.Lookup(ctx, ip)
+	if err != nil {
+		return Info{}, fmt.Errorf("geoip: error looking up address (%s): %w", ip, err)
+	}
+
+	if l.ttl > 0 {
+		l.mu.Lock()
+		if len(l.cache) >= maxCached {
+			clear(l.cache)
+		}
+		l.cache[ip] = cached{info: info, expires: time.Now().Add(l.ttl)}
+		l.mu.Unlock()
+	}
+	return info, nil
+}
+
+// Request returns the intelligence about the client address of the request, as returned
+// by godi.ClientIP.
+func (l *Lookup) Request(r *http.Request) (Info, error) {
+	host := godi.ClientIP(r)
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return Info{}, fmt.Errorf("geoip: invalid client address (%s): %w", host, err)
+	}
+	return l.Lookup(r.Context(), ip)
+}
+
+// Guard is a godi.Guard blocking the requests whose client address is not allowed by
+// the module's policy, as overridden by the metadata of the route and its controller.
+type Guard struct {
+	lookup *Lookup
+}
+
+// NewGuard returns a guard enforcing the module's policy with the lookup.
+// It can be listed in the GuardsCtors of a controller or route.
+func NewGuard(l *Lookup) *Guard {
+	return &Guard{lookup: l}
+}
+
+// Allow implements the godi.Guard interface.
+func (g *Guard) Allow(gctx godi.GuardContext) (bool, error) {
+	policy, err := g.lookup.policy.override(gctx.RouteCfg.Metadata, gctx.ControllerCfg.Metadata)
+	if err != nil {
+		return false, err
+	}
+
+	info, err := g.lookup.Request(gctx.Http.R)
+	if err != nil {
+		if g.lookup.failOpen {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if !policy.allows(info) {
+		return false, &godi.StatusError{Status: http.StatusForbidden, Err: ErrBlocked}
+	}
+	return true, nil
+}
+
+// normalize upper-cases the country codes of the policy.
+func normalize(p Policy) Policy {
+	p.AllowCountries = countries(strings.Join(p.AllowCountries, ","))
+	p.BlockCountries = countries(strings.Join(p.BlockCountries, ","))
+	return p
+}
+
+// countries parses a comma-separated list of country codes.
+func countries(list string) []string {
+	var codes []string
+	for _, code := range strings.Split(list, ",") {
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
@@ -0,0 +1,84 @@
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+)
+
+// HTTPProvider is a Provider querying an external IP intelligence API responding
+// with {"country": "US", "score": 12}.
+type HTTPProvider struct {
+	// URL is the format of the lookup URL, with a %s verb replaced by the address,
+	// e.g. "https://ip-intel.internal/lookup/%s".
+	URL string
+
+	// Client sends the lookup requests. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// Header is added to the lookup requests, e.g. to authenticate them.
+	Header http.Header
+}
+
+// NewHTTPProvider returns an HTTPProvider querying the URL format.
+func NewHTTPProvider(url string) *HTTPProvider {
+	return &HTTPProvider{URL: url}
+}
+
+// Lookup implements the Provider interface.
+func (p *HTTPProvider) Lookup(ctx context.Context, ip netip.Addr) (Info, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(p.URL, ip), nil)
+	if err != nil {
+		return Info{}, err
+	}
+	for key, values := range p.Header {
+		req.Header[key] = values
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return Info{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Info{}, fmt.Errorf("unexpected status (%s)", res.Status)
+	}
+
+	var body struct {
+		Country string `json:"country"`
+		Score   int    `json:"score"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return Info{}, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return Info{Country: body.Country, Score: body.Score}, nil
+}
+
+// Networks is a Provider resolving addresses from a static table of networks, e.g. a list
+// of known proxies or a country database exported to CIDR ranges. The most specific network
+// containing an address wins, and addresses outside every network have no intelligence.
+type Networks map[netip.Prefix]Info
+
+// Lookup implements the Provider interface.
+func (n Networks) Lookup(ctx context.Context, ip netip.Addr) (Info, error) {
+	var (
+		info Info
+		bits = -1
+	)
+	for prefix, i := range n {
+		if prefix.Contains(ip) && prefix.Bits() > bits {
+			info, bits = i, prefix.Bits()
+		}
+	}
+	return info, nil
+}
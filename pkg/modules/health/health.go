@@ -0,0 +1,253 @@
+// Package health provides a godi module serving liveness and readiness probes
+// aggregated from the health indicators contributed by the application's modules.
+//
+// Modules contribute indicators to the "health.indicators" group, so probes
+// cover every dependency of the application without central registration:
+//
+//	Imports: []godi.Module{&health.Module{}}
+//
+//	ProvidersCtors: []godi.ProviderConstructor{
+//		health.Contribute(func(db *sql.DB) *health.Indicator {
+//			return &health.Indicator{Name: "database", Check: db.PingContext}
+//		}),
+//	}
+//
+// Built-in modules depending on external services, like the lock module's Redis
+// locker and the outbox module's database, contribute their indicators automatically.
+package health
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// GroupName is the value group health indicators are contributed to.
+const GroupName = "health.indicators"
+
+const (
+	defaultLivenessPath  = "/livez"
+	defaultReadinessPath = "/readyz"
+	defaultTimeout       = 5 * time.Second
+)
+
+// Criticality is the probe a failing indicator fails.
+type Criticality int
+
+const (
+	// Readiness indicators fail the readiness probe, so traffic is routed away
+	// from the instance until the dependency recovers, e.g. a database.
+	Readiness Criticality = iota
+
+	// Liveness indicators fail both probes, so the instance is restarted,
+	// e.g. a deadlocked worker.
+	Liveness
+
+	// Informational indicators are reported without failing any probe,
+	// e.g. an optional cache.
+	Informational
+)
+
+func (c Criticality) String() string {
+	switch c {
+	case Readiness:
+		return "readiness"
+	case Liveness:
+		return "liveness"
+	case Informational:
+		return "informational"
+	}
+	return "unknown"
+}
+
+// Indicator checks the health of a dependency.
+type Indicator struct {
+	// Name identifies the indicator in reports.
+	Name string
+
+	// Criticality is the probe the indicator fails when its check fails.
+	Criticality Criticality
+
+	// Check returns an error if the dependency is unhealthy.
+	Check func(ctx context.Context) error
+}
+
+// Contribute returns a ProviderConstructor contributing the *Indicator built by
+// ctor to the health indicators.
+func Contribute(ctor godi.ProviderConstructor) godi.ProviderConstructor {
+	return godi.Group(GroupName, ctor)
+}
+
+// Module serves the liveness and readiness probes.
+// It is global, so every module can inject the Checker.
+type Module struct {
+	// LivenessPath is the path of the liveness probe. Defaults to "/livez".
+	LivenessPath string
+
+	// ReadinessPath is the path of the readiness probe. Defaults to "/readyz".
+	ReadinessPath string
+
+	// Timeout bounds each probe. Defaults to 5 seconds.
+	Timeout time.Duration
+
+	// Guards are applied to the probes.
+	Guards []godi.Guard
+
+	// GuardsCtors provides constructors for guards that require dependency injection.
+	GuardsCtors []godi.GuardConstructor
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{godi.Struct[Checker]()},
+		ExportsCtors:   []godi.ProviderConstructor{godi.Struct[Checker]()},
+		ControllersCtors: []godi.ControllerConstructor{
+			func(checker *Checker, app *godi.App) *controller {
+				return &controller{module: m, checker: checker, app: app}
+			},
+		},
+	}
+}
+
+// Checker runs the contributed health indicators.
+type Checker struct {
+	Indicators []*Indicator `godi:"inject,group=health.indicators"`
+}
+
+// Status is the health of an indicator or probe.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Report is the result of a probe.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult is the result of an indicator's check.
+type CheckResult struct {
+	Status      Status        `json:"status"`
+	Criticality string        `json:"criticality"`
+	Duration    time.Duration `json:"duration"`
+	Error       string        `json:"error,omitempty"`
+	err         error
+}
+
+// Check runs the indicators of the probe concurrently and reports their results.
+// The liveness probe runs the Liveness indicators, and the readiness probe runs
+// every indicator. The probe is down if a non-informational indicator fails.
+func (c *Checker) Check(ctx context.Context, probe Criticality) Report {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		report = Report{Status: StatusUp, Checks: map[string]CheckResult{}}
+	)
+
+	for _, indicator := range c.Indicators {
+		if indicator == nil || (probe == Liveness && indicator.Criticality != Liveness) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := check(ctx, indicator)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[indicator.Name] = result
+			if result.Status == StatusDown && indicator.Criticality != Informational {
+				report.Status = StatusDown
+			}
+		}()
+	}
+
+	wg.Wait()
+	return report
+}
+
+// check runs the indicator's check, recovering from panics.
+func check(ctx context.Context, indicator *Indicator) (result CheckResult) {
+	start := time.Now()
+	result = CheckResult{Status: StatusUp, Criticality: indicator.Criticality.String()}
+
+	defer func() {
+		if v := recover(); v != nil {
+			result.err = errors.New("check panicked")
+		}
+		result.Duration = time.Since(start)
+		if result.err != nil {
+			result.Status = StatusDown
+		}
+	}()
+
+	if indicator.Check == nil {
+		return result
+	}
+	result.err = indicator.Check(ctx)
+	return result
+}
+
+// controller serves the probes.
+type controller struct {
+	module  *Module
+	checker *Checker
+	app     *godi.App
+}
+
+func (c *controller) Config() *godi.ControllerConfig {
+	return &godi.ControllerConfig{
+		Pattern:     "/",
+		Guards:      c.module.Guards,
+		GuardsCtors: c.module.GuardsCtors,
+		Metadata:    map[string]string{godi.MaintenanceExemptKey: "true"}, // probes stay live in maintenance mode
+		RoutesCfgs: []*godi.RouteConfig{
+			{Method: http.MethodGet, Pattern: cmp.Or(c.module.LivenessPath, defaultLivenessPath), Handler: c.probe(Liveness)},
+			{Method: http.MethodGet, Pattern: cmp.Or(c.module.ReadinessPath, defaultReadinessPath), Handler: c.probe(Readiness)},
+		},
+	}
+}
+
+// probe returns the handler of the probe, responding with 503 if it is down.
+// The readiness probe is also down once the server starts shutting down.
+func (c *controller) probe(probe Criticality) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), cmp.Or(c.module.Timeout, defaultTimeout))
+		defer cancel()
+
+		report := c.checker.Check(ctx, probe)
+		if probe == Readiness && c.app.DrainStatus().ShuttingDown {
+			report.Status = StatusDown
+		}
+
+		// errors are redacted as they may contain connection strings
+		for name, result := range report.Checks {
+			if result.err != nil {
+				result.Error = c.app.Redactor().RedactString(result.err.Error())
+				report.Checks[name] = result
+			}
+		}
+
+		code := http.StatusOK
+		if report.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,91 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// LeaderElector elects a single leader among the instances campaigning for the
+// same name, using a lock that the leader keeps refreshing.
+type LeaderElector struct {
+	locker Locker
+	name   string
+	ttl    time.Duration
+	leader atomic.Bool
+}
+
+// NewLeaderElector returns an elector campaigning for the named leadership.
+// The ttl bounds how long the cluster is leaderless when a leader dies.
+func NewLeaderElector(locker Locker, name string, ttl time.Duration) *LeaderElector {
+	return &LeaderElector{
+		locker: locker,
+		name:   name,
+		ttl:    ttl,
+	}
+}
+
+// IsLeader reports whether this instance currently holds the leadership.
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns for the leadership until ctx is done, calling fn whenever it is
+// won. The context passed to fn is canceled once the leadership is lost, and the
+// leadership is released when fn returns.
+func (e *LeaderElector) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	for {
+		l, err := Acquire(ctx, e.locker, e.name, e.ttl, e.ttl/3)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		err = e.lead(ctx, l, fn)
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// lead runs fn while refreshing the lock, releasing it once fn returns.
+func (e *LeaderElector) lead(ctx context.Context, l Lock, fn func(ctx context.Context) error) error {
+	e.leader.Store(true)
+	defer e.leader.Store(false)
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- fn(leadCtx) }()
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			unlockCtx, cancelUnlock := context.WithTimeout(context.WithoutCancel(ctx), e.ttl)
+			defer cancelUnlock()
+
+			if unlockErr := l.Unlock(unlockCtx); unlockErr != nil && !errors.Is(unlockErr, ErrLockLost) {
+				return errors.Join(err, unlockErr)
+			}
+			return err
+
+		case <-ticker.C:
+			if err := l.Refresh(leadCtx, e.ttl); err != nil {
+				// the leadership is lost, stop leading and campaign again
+				cancel()
+				<-done
+				if errors.Is(err, ErrLockLost) || ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
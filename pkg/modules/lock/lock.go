@@ -0,0 +1,127 @@
+// Package lock provides a godi module exposing distributed locks and leader
+// election, so scheduled jobs and singleton background tasks run on exactly one
+// instance of a clustered application.
+//
+//	Imports: []godi.Module{
+//		&lock.Module{Locker: &lock.Redis{Addr: "redis:6379"}},
+//	}
+//
+//	func NewReportJob(locker lock.Locker) *ReportJob {
+//		return &ReportJob{elector: lock.NewLeaderElector(locker, "report-job", 15*time.Second)}
+//	}
+//
+//	// runs fn on one instance at a time, handing over if the leader goes away
+//	go job.elector.Run(ctx, job.run)
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/huboh/godi"
+	"github.com/huboh/godi/pkg/modules/health"
+)
+
+var (
+	// ErrNotAcquired is returned by TryLock when the lock is held by someone else.
+	ErrNotAcquired = errors.New("lock: not acquired")
+
+	// ErrLockLost is returned when a lock expired or was taken over before being
+	// refreshed or released.
+	ErrLockLost = errors.New("lock: lost")
+)
+
+// Locker acquires named locks shared by every instance using the same backend.
+type Locker interface {
+	// TryLock acquires the named lock for the ttl without waiting,
+	// returning ErrNotAcquired if it is held by someone else.
+	TryLock(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+}
+
+// Lock is an acquired lock. It expires after its ttl unless refreshed.
+type Lock interface {
+	// Refresh extends the lock's expiry to ttl from now, returning ErrLockLost
+	// if the lock is no longer held.
+	Refresh(ctx context.Context, ttl time.Duration) error
+
+	// Unlock releases the lock, returning ErrLockLost if it is no longer held.
+	Unlock(ctx context.Context) error
+}
+
+// Module provides the Locker to every module of the application.
+type Module struct {
+	// Locker is the backend locks are acquired with. Defaults to an in-memory
+	// locker, which only coordinates the goroutines of a single instance.
+	Locker Locker
+
+	// HealthCriticality is the probe failed by the health indicator of lockers
+	// implementing Pinger, such as the Redis locker. Defaults to readiness.
+	HealthCriticality health.Criticality
+}
+
+// Pinger is implemented by lockers that can check the health of their backend.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal: true,
+		ProvidersCtors: []godi.ProviderConstructor{
+			m.newLocker,
+			health.Contribute(m.newIndicator),
+		},
+		ExportsCtors: []godi.ProviderConstructor{m.newLocker},
+	}
+}
+
+// newIndicator returns the health indicator of the locker's backend.
+func (m *Module) newIndicator(locker Locker) *health.Indicator {
+	pinger, ok := locker.(Pinger)
+	if !ok {
+		return nil
+	}
+
+	return &health.Indicator{
+		Name:        "lock",
+		Criticality: m.HealthCriticality,
+		Check:       pinger.Ping,
+	}
+}
+
+func (m *Module) newLocker() Locker {
+	if m.Locker == nil {
+		return NewMemory()
+	}
+	return m.Locker
+}
+
+// Acquire acquires the named lock for the ttl, retrying every interval until it
+// is acquired or ctx is done.
+func Acquire(ctx context.Context, locker Locker, name string, ttl, interval time.Duration) (Lock, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l, err := locker.TryLock(ctx, name, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return l, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// newToken returns a random token identifying a lock holder.
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
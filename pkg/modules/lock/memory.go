@@ -0,0 +1,72 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Locker that coordinates the goroutines of a single process.
+// It is useful in development and tests.
+type Memory struct {
+	mu    sync.Mutex
+	locks map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	token   string
+	expires time.Time
+}
+
+// NewMemory returns an in-memory Locker.
+func NewMemory() *Memory {
+	return &Memory{locks: map[string]memoryEntry{}}
+}
+
+// TryLock implements the Locker interface.
+func (m *Memory) TryLock(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.locks[name]; ok && time.Now().Before(e.expires) {
+		return nil, ErrNotAcquired
+	}
+
+	token := newToken()
+	m.locks[name] = memoryEntry{token: token, expires: time.Now().Add(ttl)}
+	return &memoryLock{memory: m, name: name, token: token}, nil
+}
+
+type memoryLock struct {
+	memory *Memory
+	name   string
+	token  string
+}
+
+func (l *memoryLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	l.memory.mu.Lock()
+	defer l.memory.mu.Unlock()
+
+	if !l.held() {
+		return ErrLockLost
+	}
+	l.memory.locks[l.name] = memoryEntry{token: l.token, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (l *memoryLock) Unlock(ctx context.Context) error {
+	l.memory.mu.Lock()
+	defer l.memory.mu.Unlock()
+
+	if !l.held() {
+		return ErrLockLost
+	}
+	delete(l.memory.locks, l.name)
+	return nil
+}
+
+// held reports whether the lock is still held. The memory's mutex must be held.
+func (l *memoryLock) held() bool {
+	e, ok := l.memory.locks[l.name]
+	return ok && e.token == l.token && time.Now().Before(e.expires)
+}
@@ -0,0 +1,202 @@
+package lock
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// unlockScript deletes the key if it still holds the lock's token.
+	unlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+	// refreshScript extends the key's expiry if it still holds the lock's token.
+	refreshScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+)
+
+// Redis is a Locker backed by a Redis server, using SET NX with an expiry to
+// acquire locks and scripts checking the holder's token to refresh and release them.
+type Redis struct {
+	// Addr is the address of the Redis server. Defaults to "localhost:6379".
+	Addr string
+
+	// Username and Password authenticate the connection, if set.
+	Username string
+	Password string
+
+	// DB is the database locks are stored in.
+	DB int
+
+	// Prefix is prepended to lock names to form keys. Defaults to "godi:lock:".
+	Prefix string
+}
+
+// TryLock implements the Locker interface.
+func (r *Redis) TryLock(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	var (
+		key   = cmp.Or(r.Prefix, "godi:lock:") + name
+		token = newToken()
+	)
+
+	reply, err := r.do(ctx, "SET", key, token, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrNotAcquired
+	}
+
+	return &redisLock{redis: r, key: key, token: token}, nil
+}
+
+// Ping implements the Pinger interface.
+func (r *Redis) Ping(ctx context.Context) error {
+	reply, err := r.do(ctx, "PING")
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("redis: unexpected ping reply %v", reply)
+	}
+	return nil
+}
+
+type redisLock struct {
+	redis *Redis
+	key   string
+	token string
+}
+
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	reply, err := l.redis.do(ctx, "EVAL", refreshScript, "1", l.key, l.token, strconv.FormatInt(ttl.Milliseconds(), 10))
+	return lockReply(reply, err)
+}
+
+func (l *redisLock) Unlock(ctx context.Context) error {
+	reply, err := l.redis.do(ctx, "EVAL", unlockScript, "1", l.key, l.token)
+	return lockReply(reply, err)
+}
+
+// lockReply converts the reply of the lock scripts to an error.
+func lockReply(reply any, err error) error {
+	if err != nil {
+		return err
+	}
+	if n, ok := reply.(int64); !ok || n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// do runs the command on a new connection, selecting the configured database.
+func (r *Redis) do(ctx context.Context, args ...string) (any, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", cmp.Or(r.Addr, "localhost:6379"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var cmds [][]string
+	if r.Password != "" {
+		if r.Username != "" {
+			cmds = append(cmds, []string{"AUTH", r.Username, r.Password})
+		} else {
+			cmds = append(cmds, []string{"AUTH", r.Password})
+		}
+	}
+	if r.DB != 0 {
+		cmds = append(cmds, []string{"SELECT", strconv.Itoa(r.DB)})
+	}
+	cmds = append(cmds, args)
+
+	w := bufio.NewWriter(conn)
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, "*%d\r\n", len(cmd))
+		for _, arg := range cmd {
+			fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	var (
+		reply any
+		br    = bufio.NewReader(conn)
+	)
+	for range cmds {
+		reply, err = readReply(br)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reply, nil
+}
+
+// redisError is an error reply of the Redis server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readReply reads a RESP reply, returning strings, integers, nil and arrays as
+// string, int64, nil and []any, and error replies as errors.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, errors.New("redis: malformed reply")
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+
+	case '-':
+		return nil, redisError(line[1:])
+
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]any, n)
+		for i := range values {
+			if values[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+}
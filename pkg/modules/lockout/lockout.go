@@ -0,0 +1,345 @@
+// Package lockout provides a godi module tracking the failed authentication attempts of
+// each account identifier and client IP address, and a guard slowing down, then locking
+// out, further attempts once too many failed, to protect login and similar routes from
+// brute-force and credential stuffing attacks.
+//
+// Handlers report the outcome of attempts to the Tracker; the guard only enforces it:
+//
+//	Imports: []godi.Module{
+//		&lockout.Module{
+//			Keys:        []lockout.KeyFunc{lockout.ByIP(), lockout.ByFormValue("email")},
+//			MaxAttempts: 5,
+//			Tarpit:      time.Second,
+//		},
+//	}
+//
+//	func (c *LoginController) handleLogin(w http.ResponseWriter, r *http.Request) {
+//		if !c.users.CheckPassword(...) {
+//			c.lockout.Failed(r)
+//			...
+//		}
+//		c.lockout.Succeeded(r)
+//	}
+//
+// Locked out requests are rendered with 429 and ErrLockedOut, with a Retry-After header.
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Defaults of the module.
+const (
+	defaultMaxAttempts  = 5
+	defaultWindow       = 15 * time.Minute
+	defaultLockDuration = 15 * time.Minute
+	defaultMaxTarpit    = 10 * time.Second
+)
+
+// ErrLockedOut is rendered with 429 when an attempt is made while its identifier or client is locked out.
+var ErrLockedOut = errors.New("lockout: too many failed attempts")
+
+// Store holds the counters of failed attempts and lockouts.
+type Store interface {
+	// Increment increments the counter of the key, created to expire at expiry if it does
+	// not exist or expired, and returns its new value.
+	Increment(ctx context.Context, key string, expiry time.Time) (int64, error)
+
+	// Get returns the value and expiry of the counter of the key, or zero if it does not
+	// exist or expired.
+	Get(ctx context.Context, key string) (int64, time.Time, error)
+
+	// Delete deletes the counter of the key.
+	Delete(ctx context.Context, key string) error
+}
+
+// KeyFunc returns the key an attempt is tracked under, e.g. the account identifier or
+// client IP address it was made with, reporting false if it has none.
+type KeyFunc func(r *http.Request) (string, bool)
+
+// ByIP tracks attempts by the IP address of the request's client, as returned by godi.ClientIP,
+// so the proxies the application runs behind must be trusted with godi.WithTrustedProxies.
+func ByIP() KeyFunc {
+	return func(r *http.Request) (string, bool) {
+		ip := godi.ClientIP(r)
+		return ip, ip != ""
+	}
+}
+
+// ByFormValue tracks attempts by the value of a form field, e.g. the submitted username.
+// Values are compared case-insensitively.
+func ByFormValue(name string) KeyFunc {
+	return func(r *http.Request) (string, bool) {
+		v := strings.ToLower(strings.TrimSpace(r.FormValue(name)))
+		return v, v != ""
+	}
+}
+
+// ByHeader tracks attempts by the value of a request header.
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) (string, bool) {
+		v := r.Header.Get(name)
+		return v, v != ""
+	}
+}
+
+// Module provides the Tracker of failed attempts to every module of the application.
+type Module struct {
+	// Store holds the counters. Defaults to an in-memory store, which only tracks
+	// the attempts made to a single instance.
+	Store Store
+
+	// Keys track attempts, each independently. Defaults to ByIP.
+	Keys []KeyFunc
+
+	// MaxAttempts is the number of failed attempts within Window locking out a key. Defaults to 5.
+	MaxAttempts int
+
+	// Window is the period failed attempts are counted over. Defaults to 15 minutes.
+	Window time.Duration
+
+	// LockDuration is how long keys are locked out. Defaults to 15 minutes.
+	LockDuration time.Duration
+
+	// Tarpit delays attempts by this duration for every failed attempt of their keys
+	// within Window, up to MaxTarpit. Attempts are not delayed if zero.
+	Tarpit time.Duration
+
+	// MaxTarpit bounds the delay of attempts. Defaults to 10 seconds.
+	MaxTarpit time.Duration
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	for i, key := range m.Keys {
+		if key == nil {
+			return fmt.Errorf("lockout: key at index %d is nil", i)
+		}
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newTracker},
+		ExportsCtors:   []godi.ProviderConstructor{m.newTracker},
+	}
+}
+
+func (m *Module) newTracker() *Tracker {
+	t := &Tracker{
+		store:        m.Store,
+		keys:         m.Keys,
+		maxAttempts:  int64(m.MaxAttempts),
+		window:       m.Window,
+		lockDuration: m.LockDuration,
+		tarpit:       m.Tarpit,
+		maxTarpit:    m.MaxTarpit,
+	}
+	if t.store == nil {
+		t.store = NewMemory()
+	}
+	if len(t.keys) == 0 {
+		t.keys = []KeyFunc{ByIP()}
+	}
+	if t.maxAttempts <= 0 {
+		t.maxAttempts = defaultMaxAttempts
+	}
+	if t.window <= 0 {
+		t.window = defaultWindow
+	}
+	if t.lockDuration <= 0 {
+		t.lockDuration = defaultLockDuration
+	}
+	if t.maxTarpit <= 0 {
+		t.maxTarpit = defaultMaxTarpit
+	}
+	return t
+}
+
+// Tracker tracks failed attempts and lockouts.
+type Tracker struct {
+	store        Store
+	keys         []KeyFunc
+	maxAttempts  int64
+	window       time.Duration
+	lockDuration time.Duration
+	tarpit       time.Duration
+	maxTarpit    time.Duration
+}
+
+// Status is the state of the keys of an attempt.
+type Status struct {
+	// Failures is the highest number of recent failed attempts of the keys.
+	Failures int64
+
+	// LockedUntil is the time the last lockout of the keys ends, or zero if none is locked out.
+	LockedUntil time.Time
+}
+
+// Locked reports whether a key of the attempt is locked out.
+func (s Status) Locked() bool {
+	return !s.LockedUntil.IsZero()
+}
+
+// Status returns the state of the keys of the attempt made with the request.
+func (t *Tracker) Status(r *http.Request) (Status, error) {
+	var s Status
+	for i, key := range t.keys {
+		k, ok := key(r)
+		if !ok {
+			continue
+		}
+
+		locks, until, err := t.store.Get(r.Context(), lockKey(i, k))
+		if err != nil {
+			return Status{}, fmt.Errorf("lockout: error retrieving lockout: %w", err)
+		}
+		if locks > 0 && until.After(s.LockedUntil) {
+			s.LockedUntil = until
+		}
+
+		failures, _, err := t.store.Get(r.Context(), failuresKey(i, k))
+		if err != nil {
+			return Status{}, fmt.Errorf("lockout: error retrieving failed attempts: %w", err)
+		}
+		s.Failures = max(s.Failures, failures)
+	}
+	return s, nil
+}
+
+// Failed records a failed attempt made with the request, locking out the keys
+// that reached the maximum number of failed attempts.
+func (t *Tracker) Failed(r *http.Request) error {
+	now := time.Now()
+	for i, key := range t.keys {
+		k, ok := key(r)
+		if !ok {
+			continue
+		}
+
+		failures, err := t.store.Increment(r.Context(), failuresKey(i, k), now.Add(t.window))
+		if err != nil {
+			return fmt.Errorf("lockout: error recording failed attempt: %w", err)
+		}
+		if failures < t.maxAttempts {
+			continue
+		}
+
+		godi.LoggerFrom(r.Context()).Warn("too many failed attempts, locking out", "key", k, "duration", t.lockDuration.String())
+
+		_, err = t.store.Increment(r.Context(), lockKey(i, k), now.Add(t.lockDuration))
+		if err != nil {
+			return fmt.Errorf("lockout: error recording lockout: %w", err)
+		}
+		err = t.store.Delete(r.Context(), failuresKey(i, k))
+		if err != nil {
+			return fmt.Errorf("lockout: error resetting failed attempts: %w", err)
+		}
+	}
+	return nil
+}
+
+// Succeeded records a successful attempt made with the request, resetting the
+// failed attempts of its keys.
+func (t *Tracker) Succeeded(r *http.Request) error {
+	for i, key := range t.keys {
+		k, ok := key(r)
+		if !ok {
+			continue
+		}
+
+		err := t.store.Delete(r.Context(), failuresKey(i, k))
+		if err != nil {
+			return fmt.Errorf("lockout: error resetting failed attempts: %w", err)
+		}
+	}
+	return nil
+}
+
+// Unlock lifts the lockout and resets the failed attempts of the keys of the request,
+// e.g. once the account's owner reset their password.
+func (t *Tracker) Unlock(r *http.Request) error {
+	for i, key := range t.keys {
+		k, ok := key(r)
+		if !ok {
+			continue
+		}
+
+		for _, sk := range []string{lockKey(i, k), failuresKey(i, k)} {
+			if err := t.store.Delete(r.Context(), sk); err != nil {
+				return fmt.Errorf("lockout: error unlocking: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// delay returns how long an attempt with the status is delayed.
+func (t *Tracker) delay(s Status) time.Duration {
+	if t.tarpit <= 0 || s.Failures == 0 {
+		return 0
+	}
+	return min(t.tarpit*time.Duration(s.Failures), t.maxTarpit)
+}
+
+// failuresKey and lockKey return the store keys of the failed attempts and lockouts of
+// the key returned by the i-th KeyFunc, so that keys of different KeyFuncs never collide.
+func failuresKey(i int, key string) string {
+	return "lockout:failures:" + strconv.Itoa(i) + ":" + key
+}
+
+func lockKey(i int, key string) string {
+	return "lockout:lock:" + strconv.Itoa(i) + ":" + key
+}
+
+// Guard is a godi.Guard rejecting the attempts whose keys are locked out with 429
+// and ErrLockedOut, and delaying those whose keys recently failed.
+type Guard struct {
+	tracker *Tracker
+}
+
+// NewGuard returns a guard enforcing the lockouts of the tracker.
+// It can be listed in the GuardsCtors of a controller or route.
+func NewGuard(t *Tracker) *Guard {
+	return &Guard{tracker: t}
+}
+
+// Allow implements the godi.Guard interface.
+func (g *Guard) Allow(gctx godi.GuardContext) (bool, error) {
+	var (
+		w = gctx.Http.W
+		r = gctx.Http.R
+	)
+
+	s, err := g.tracker.Status(r)
+	if err != nil {
+		return false, err
+	}
+
+	if s.Locked() {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(time.Until(s.LockedUntil).Seconds())+1, 10))
+		return false, &godi.StatusError{Status: http.StatusTooManyRequests, Err: ErrLockedOut}
+	}
+
+	if d := g.tracker.delay(s); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return false, r.Context().Err()
+		}
+	}
+	return true, nil
+}
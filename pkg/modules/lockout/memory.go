@@ -0,0 +1,77 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Store tracking the attempts made to a single process.
+// It is useful in development and tests.
+type Memory struct {
+	mu       sync.Mutex
+	counters map[string]memoryCounter
+	swept    time.Time
+}
+
+type memoryCounter struct {
+	value  int64
+	expiry time.Time
+}
+
+// NewMemory returns an in-memory Store.
+func NewMemory() *Memory {
+	return &Memory{counters: map[string]memoryCounter{}}
+}
+
+// Increment implements the Store interface.
+func (m *Memory) Increment(ctx context.Context, key string, expiry time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	c := m.counters[key]
+	if !now.Before(c.expiry) {
+		c = memoryCounter{expiry: expiry}
+	}
+	c.value++
+	m.counters[key] = c
+	return c.value, nil
+}
+
+// Get implements the Store interface.
+func (m *Memory) Get(ctx context.Context, key string) (int64, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.counters[key]
+	if !ok || !time.Now().Before(c.expiry) {
+		return 0, time.Time{}, nil
+	}
+	return c.value, c.expiry, nil
+}
+
+// Delete implements the Store interface.
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.counters, key)
+	return nil
+}
+
+// sweep deletes the expired counters, at most once a minute.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.swept) < time.Minute {
+		return
+	}
+	m.swept = now
+
+	for key, c := range m.counters {
+		if !now.Before(c.expiry) {
+			delete(m.counters, key)
+		}
+	}
+}
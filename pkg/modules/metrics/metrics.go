@@ -0,0 +1,156 @@
+// Package metrics provides a godi module recording HTTP request metrics and
+// serving them in the Prometheus text exposition format.
+//
+// Controllers opt in by adding the interceptor, and routes declare extra labels,
+// such as the owning team or service tier, through their metadata:
+//
+//	Imports: []godi.Module{
+//		&metrics.Module{Labels: []string{"team", "tier"}},
+//	}
+//
+//	func (c *OrderController) Config() *godi.ControllerConfig {
+//		return &godi.ControllerConfig{
+//			Pattern:           "/orders",
+//			Metadata:          map[string]string{"team": "checkout"},
+//			InterceptorsCtors: []godi.InterceptorConstructor{metrics.NewInterceptor},
+//			RoutesCfgs: []*godi.RouteConfig{
+//				{Method: http.MethodPost, Pattern: "/", Handler: ..., Metadata: map[string]string{"tier": "critical"}},
+//			},
+//		}
+//	}
+//
+// Requests are recorded in the http_request_duration_seconds histogram, labeled
+// with the method, route pattern, status code and the configured metadata labels,
+// enabling per-team SLO dashboards without custom instrumentation. The build_info
+// gauge carries the application's godi.BuildInfo as labels, and the http_connections
+// gauge counts the server's open connections by state (new, active and idle).
+package metrics
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// defaultPath is the path the metrics are served at when no path is set.
+const defaultPath = "/metrics"
+
+// Module records request metrics and serves them.
+// It is global, so every module can use the registry and interceptor.
+type Module struct {
+	// Path is the path the metrics are served at. Defaults to "/metrics".
+	Path string
+
+	// Labels lists the metadata keys attached as labels to request metrics.
+	// Values are read from the route metadata, then the controller metadata.
+	Labels []string
+
+	// Buckets are the upper bounds, in seconds, of the request duration histogram buckets.
+	// Defaults to DefaultBuckets.
+	Buckets []float64
+
+	// Guards are applied to the metrics endpoint.
+	Guards []godi.Guard
+
+	// GuardsCtors provides constructors for guards that require dependency injection.
+	GuardsCtors []godi.GuardConstructor
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newRegistry},
+		ExportsCtors:   []godi.ProviderConstructor{m.newRegistry},
+		ControllersCtors: []godi.ControllerConstructor{
+			func(reg *Registry) *controller { return &controller{module: m, registry: reg} },
+		},
+	}
+}
+
+func (m *Module) newRegistry(info godi.BuildInfo, server *godi.HttpServer) *Registry {
+	reg := NewRegistry(m.Labels, m.Buckets)
+	reg.SetBuildInfo(info.Labels())
+	server.OnConnState(reg.TrackConnState)
+	return reg
+}
+
+// controller serves the metrics endpoint.
+type controller struct {
+	module   *Module
+	registry *Registry
+}
+
+func (c *controller) Config() *godi.ControllerConfig {
+	return &godi.ControllerConfig{
+		Pattern:     "/",
+		Guards:      c.module.Guards,
+		GuardsCtors: c.module.GuardsCtors,
+		RoutesCfgs: []*godi.RouteConfig{
+			{Method: http.MethodGet, Pattern: cmp.Or(c.module.Path, defaultPath), Handler: c.registry},
+		},
+	}
+}
+
+// Labeler is implemented by route and controller metadata that provides its metric labels.
+type Labeler interface {
+	MetricLabels() map[string]string
+}
+
+// Interceptor records the duration of the requests handled by the routes it wraps.
+type Interceptor struct {
+	registry *Registry
+}
+
+// NewInterceptor returns an interceptor recording request metrics in the registry.
+// It can be listed in the InterceptorsCtors of a controller or route.
+func NewInterceptor(reg *Registry) *Interceptor {
+	return &Interceptor{registry: reg}
+}
+
+// Intercept implements the godi.Interceptor interface.
+func (i *Interceptor) Intercept(ictx godi.InterceptorContext, next http.Handler) error {
+	var (
+		start = time.Now()
+		req   = ictx.Http.R
+		rec   = godi.NewStatusRecorder(ictx.Http.W)
+	)
+
+	next.ServeHTTP(rec, req)
+
+	_, route, _ := strings.Cut(req.Pattern, " ")
+	values := []string{req.Method, cmp.Or(route, req.Pattern), strconv.Itoa(rec.Status())}
+	for _, key := range i.registry.labels {
+		values = append(values, metadataLabel(key, ictx.RouteCfg.Metadata, ictx.ControllerCfg.Metadata))
+	}
+
+	i.registry.observe(values, time.Since(start))
+	return nil
+}
+
+// metadataLabel returns the value of the label key in the first metadata that defines it.
+func metadataLabel(key string, metadata ...any) string {
+	for _, md := range metadata {
+		var labels map[string]string
+
+		switch md := md.(type) {
+		case Labeler:
+			labels = md.MetricLabels()
+		case map[string]string:
+			labels = md
+		case map[string]any:
+			if v, ok := md[key]; ok {
+				return fmt.Sprint(v)
+			}
+		}
+
+		if v, ok := labels[key]; ok {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the default request duration histogram buckets, in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// invalidLabelChars matches the characters not allowed in Prometheus label names.
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Registry holds the recorded request metrics.
+type Registry struct {
+	mu       sync.Mutex
+	labels   []string
+	names    []string
+	buckets  []float64
+	requests map[string]*histogram
+
+	// build holds the labels of the build_info gauge, if set.
+	build map[string]string
+
+	// conns holds the state of each open client connection.
+	conns map[net.Conn]http.ConnState
+}
+
+// NewRegistry returns a registry whose request metrics carry the given metadata labels
+// in addition to the method, route and status labels.
+func NewRegistry(labels []string, buckets []float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Sorted(slices.Values(buckets))
+
+	names := []string{"method", "route", "status"}
+	for _, label := range labels {
+		names = append(names, invalidLabelChars.ReplaceAllString(label, "_"))
+	}
+
+	return &Registry{
+		labels:   slices.Clone(labels),
+		names:    names,
+		buckets:  buckets,
+		requests: map[string]*histogram{},
+		conns:    map[net.Conn]http.ConnState{},
+	}
+}
+
+// SetBuildInfo sets the labels of the build_info gauge, e.g. to the
+// labels of godi.BuildInfo, so dashboards can correlate metrics with deploys.
+func (r *Registry) SetBuildInfo(labels map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.build = maps.Clone(labels)
+}
+
+// connStates are the connection states reported by the http_connections gauge.
+var connStates = []http.ConnState{http.StateNew, http.StateActive, http.StateIdle}
+
+// TrackConnState records the state of a client connection in the http_connections
+// gauge. It can be registered as a connection state hook of the server.
+func (r *Registry) TrackConnState(c net.Conn, state http.ConnState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(r.conns, c)
+	default:
+		r.conns[c] = state
+	}
+}
+
+// histogram is a cumulative histogram of request durations for one set of label values.
+type histogram struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (r *Registry) observe(values []string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.Join(values, "\xff")
+	h, ok := r.requests[key]
+	if !ok {
+		h = &histogram{values: values, counts: make([]uint64, len(r.buckets))}
+		r.requests[key] = h
+	}
+
+	seconds := d.Seconds()
+	for i, bound := range r.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+
+	fmt.Fprintln(cw, "# HELP http_request_duration_seconds Duration of HTTP requests handled by godi routes.")
+	fmt.Fprintln(cw, "# TYPE http_request_duration_seconds histogram")
+
+	for _, key := range slices.Sorted(maps.Keys(r.requests)) {
+		h := r.requests[key]
+		labels := r.formatLabels(h.values)
+
+		for i, bound := range r.buckets {
+			fmt.Fprintf(cw, "http_request_duration_seconds_bucket{%s,le=%q} %d\n", labels, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(cw, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(cw, "http_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.sum))
+		fmt.Fprintf(cw, "http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	counts := map[http.ConnState]int{}
+	for _, state := range r.conns {
+		counts[state]++
+	}
+
+	fmt.Fprintln(cw, "# HELP http_connections Number of open client connections by state.")
+	fmt.Fprintln(cw, "# TYPE http_connections gauge")
+	for _, state := range connStates {
+		fmt.Fprintf(cw, "http_connections{state=%q} %d\n", state.String(), counts[state])
+	}
+
+	if len(r.build) > 0 {
+		var pairs []string
+		for _, key := range slices.Sorted(maps.Keys(r.build)) {
+			name := invalidLabelChars.ReplaceAllString(key, "_")
+			pairs = append(pairs, name+`="`+escapeLabelValue(r.build[key])+`"`)
+		}
+
+		fmt.Fprintln(cw, "# HELP build_info Build information of the running binary.")
+		fmt.Fprintln(cw, "# TYPE build_info gauge")
+		fmt.Fprintf(cw, "build_info{%s} 1\n", strings.Join(pairs, ","))
+	}
+
+	if cw.err == nil {
+		cw.err = bw.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+func (r *Registry) formatLabels(values []string) string {
+	pairs := make([]string, len(values))
+	for i, v := range values {
+		pairs[i] = r.names[i] + `="` + escapeLabelValue(v) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// countingWriter counts the bytes written and records the first write error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
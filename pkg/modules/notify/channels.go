@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"strings"
+
+	"github.com/huboh/godi/pkg/modules/webhook"
+)
+
+// Mailer sends emails.
+type Mailer interface {
+	SendMail(ctx context.Context, to, subject, body string) error
+}
+
+// SMTPMailer is a Mailer sending plain text emails through an SMTP server.
+type SMTPMailer struct {
+	// Addr is the address of the SMTP server, e.g. "smtp.example.com:587".
+	Addr string
+
+	// From is the sender address.
+	From string
+
+	// Auth authenticates with the server, e.g. smtp.PlainAuth. Optional.
+	Auth smtp.Auth
+}
+
+// SendMail implements the Mailer interface. The context is not used, as net/smtp does
+// not support cancellation.
+func (m *SMTPMailer) SendMail(ctx context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return errors.New("invalid recipient or subject: contains line breaks")
+	}
+
+	msg := "From: " + m.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + body
+	return smtp.SendMail(m.Addr, m.Auth, m.From, []string{to}, []byte(msg))
+}
+
+// Email returns a Channel emailing notifications with the mailer to the address of their
+// recipient, returned by address. Recipients without an address, for which address
+// returns an empty address, are skipped.
+func Email(mailer Mailer, address func(ctx context.Context, recipient string) (string, error)) *Channel {
+	return &Channel{
+		Name: "email",
+		Deliver: func(ctx context.Context, n Notification) error {
+			to, err := address(ctx, n.Recipient)
+			if err != nil || to == "" {
+				return err
+			}
+			return mailer.SendMail(ctx, to, n.Title, n.Body)
+		},
+	}
+}
+
+// Webhook returns a Channel sending notifications as webhooks of their kind with the
+// dispatcher, to the endpoints subscribed to it.
+func Webhook(d *webhook.Dispatcher) *Channel {
+	return &Channel{
+		Name: "webhook",
+		Deliver: func(ctx context.Context, n Notification) error {
+			_, err := d.Send(ctx, n.Kind, n)
+			return err
+		},
+	}
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/huboh/godi"
+)
+
+const defaultHubBuffer = 16
+
+// ErrNoRecipient is rendered with 401 when a request to the Hub's handler has no recipient.
+var ErrNoRecipient = errors.New("notify: no recipient")
+
+// Hub delivers notifications to the connected clients of their recipients, e.g. the
+// browser tabs of a user. Its Handler serves them over server-sent events, and other
+// transports, like WebSockets, subscribe to it to relay them.
+type Hub struct {
+	buffer int
+
+	mu   sync.Mutex
+	subs map[string]map[*subscription]struct{}
+}
+
+type subscription struct {
+	ch chan Notification
+}
+
+// NewHub returns a Hub buffering up to buffer notifications per subscriber, or 16 if buffer is zero.
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = defaultHubBuffer
+	}
+	return &Hub{buffer: buffer, subs: map[string]map[*subscription]struct{}{}}
+}
+
+// Subscribe returns a channel receiving the notifications of the recipient until
+// cancel is called. Notifications are dropped while the channel's buffer is full.
+func (h *Hub) Subscribe(recipient string) (notifications <-chan Notification, cancel func()) {
+	sub := &subscription{ch: make(chan Notification, h.buffer)}
+
+	h.mu.Lock()
+	if h.subs[recipient] == nil {
+		h.subs[recipient] = map[*subscription]struct{}{}
+	}
+	h.subs[recipient][sub] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			delete(h.subs[recipient], sub)
+			if len(h.subs[recipient]) == 0 {
+				delete(h.subs, recipient)
+			}
+			close(sub.ch)
+		})
+	}
+}
+
+// Publish sends the notification to the subscribers of its recipient,
+// returning the number of subscribers it was sent to.
+func (h *Hub) Publish(n Notification) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sent := 0
+	for sub := range h.subs[n.Recipient] {
+		select {
+		case sub.ch <- n:
+			sent++
+		default:
+		}
+	}
+	return sent
+}
+
+// Channel returns the Channel publishing notifications to the hub.
+func (h *Hub) Channel() *Channel {
+	return &Channel{
+		Name: "hub",
+		Deliver: func(ctx context.Context, n Notification) error {
+			h.Publish(n)
+			return nil
+		},
+	}
+}
+
+// Handler returns a handler streaming the notifications of the recipient of each request
+// as server-sent events, whose event type is the notification's kind and whose data is
+// its JSON encoding. Requests without a recipient, as reported by recipient, are rejected
+// with 401.
+func (h *Hub) Handler(recipient func(r *http.Request) (string, bool)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := recipient(r)
+		if !ok {
+			godi.RenderError(w, r, http.StatusUnauthorized, ErrNoRecipient)
+			return
+		}
+
+		notifications, cancel := h.Subscribe(id)
+		defer cancel()
+
+		s := godi.NewStreamWriter(w, r)
+		s.Header().Set("Content-Type", "text/event-stream")
+		s.Header().Set("Cache-Control", "no-cache")
+		s.Header().Set("X-Accel-Buffering", "no")
+
+		// send the headers right away, so clients know the stream is open
+		if err := s.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case <-s.Done():
+				return
+			case n := <-notifications:
+				data, err := json.Marshal(n)
+				if err != nil {
+					godi.LoggerFrom(r.Context()).Error("error encoding notification", "kind", n.Kind, "error", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(s, "event: %s\ndata: %s\n\n", n.Kind, data); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,186 @@
+// Package notify provides a godi module delivering notifications to their recipients
+// over every configured channel, e.g. the browser tabs connected to the Hub over
+// server-sent events, email or webhooks, so domain services emit notifications
+// without knowing how they are delivered.
+//
+//	Imports: []godi.Module{
+//		&notify.Module{Channels: []*notify.Channel{notify.Email(mailer, addresses)}},
+//	}
+//
+//	// contributed by a module owning a delivery mechanism
+//	ProvidersCtors: []godi.ProviderConstructor{
+//		notify.Contribute(func(d *webhook.Dispatcher) *notify.Channel { return notify.Webhook(d) }),
+//	}
+//
+//	func (s *OrderService) Ship(ctx context.Context, order Order) error {
+//		...
+//		return s.notifier.Notify(ctx, notify.Notification{
+//			Recipient: order.CustomerID,
+//			Kind:      "order.shipped",
+//			Title:     "Your order has shipped",
+//		})
+//	}
+//
+//	// serving the Hub's notifications to connected clients
+//	Handler: hub.Handler(func(r *http.Request) (string, bool) { ... })
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// GroupName is the value group notification channels are contributed to.
+const GroupName = "notify.channels"
+
+// Notification is a message for a recipient.
+type Notification struct {
+	// Recipient identifies who the notification is for, e.g. a user ID.
+	Recipient string `json:"recipient"`
+
+	// Kind identifies the type of notification, e.g. "order.shipped".
+	Kind string `json:"kind"`
+
+	// Title and Body are the user-facing content of the notification.
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+
+	// Data is the structured payload of the notification, encoded as JSON by channels.
+	Data any `json:"data,omitempty"`
+
+	// Time is the time the notification was emitted. Defaults to the time Notify is called.
+	Time time.Time `json:"time"`
+}
+
+// Notifier delivers notifications to their recipients.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Channel is a delivery mechanism of notifications.
+type Channel struct {
+	// Name identifies the channel in logs and errors, e.g. "email".
+	Name string
+
+	// Kinds restricts the kinds of notifications delivered over the channel.
+	// Every notification is delivered if empty.
+	Kinds []string
+
+	// Deliver delivers the notification over the channel.
+	Deliver func(ctx context.Context, n Notification) error
+}
+
+// accepts reports whether the channel delivers notifications of the kind.
+func (c *Channel) accepts(kind string) bool {
+	if len(c.Kinds) == 0 {
+		return true
+	}
+	for _, k := range c.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Contribute returns a ProviderConstructor contributing the *Channel built by
+// ctor to the channels of the Notifier.
+func Contribute(ctor godi.ProviderConstructor) godi.ProviderConstructor {
+	return godi.Group(GroupName, ctor)
+}
+
+// Module provides the Notifier and the Hub to every module of the application.
+type Module struct {
+	// Channels deliver notifications, along with the Hub and the contributed channels.
+	Channels []*Channel
+
+	// HubBuffer is the number of notifications buffered for each Hub subscriber,
+	// beyond which notifications are dropped for slow subscribers. Defaults to 16.
+	HubBuffer int
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	for i, c := range m.Channels {
+		if c == nil || c.Deliver == nil {
+			return fmt.Errorf("notify: channel at index %d has no Deliver function", i)
+		}
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{godi.Struct[contributions](), m.newHub, m.newNotifier},
+		ExportsCtors:   []godi.ProviderConstructor{m.newHub, m.newNotifier},
+	}
+}
+
+// contributions holds the channels contributed by other modules.
+type contributions struct {
+	Channels []*Channel `godi:"inject,group=notify.channels"`
+}
+
+func (m *Module) newHub() *Hub {
+	return NewHub(m.HubBuffer)
+}
+
+func (m *Module) newNotifier(hub *Hub, c *contributions) Notifier {
+	channels := []*Channel{hub.Channel()}
+	for _, ch := range append(m.Channels, c.Channels...) {
+		if ch != nil && ch.Deliver != nil {
+			channels = append(channels, ch)
+		}
+	}
+	return &fanout{channels: channels}
+}
+
+// fanout is the Notifier delivering notifications over every channel.
+type fanout struct {
+	channels []*Channel
+}
+
+// Notify delivers the notification over every channel accepting its kind concurrently,
+// returning the errors of the channels that failed to deliver it once they all returned.
+func (f *fanout) Notify(ctx context.Context, n Notification) error {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, ch := range f.channels {
+		if !ch.accepts(n.Kind) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := ch.Deliver(ctx, n)
+			if err == nil {
+				return
+			}
+
+			godi.LoggerFrom(ctx).Error("error delivering notification", "channel", ch.Name, "kind", n.Kind, "error", err)
+
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("notify: error delivering over channel (%s): %w", ch.Name, err))
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package operations
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Store keeping operations in the memory of a single process, discarding
+// finished operations after the retention period.
+type Memory struct {
+	retention time.Duration
+
+	mu    sync.Mutex
+	ops   map[string]Operation
+	swept time.Time
+}
+
+// NewMemory returns an in-memory Store keeping finished operations for the retention
+// period, or a day if it is zero.
+func NewMemory(retention time.Duration) *Memory {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	return &Memory{retention: retention, ops: map[string]Operation{}}
+}
+
+// Save implements the Store interface.
+func (m *Memory) Save(ctx context.Context, op Operation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(time.Now())
+	m.ops[op.ID] = op
+	return nil
+}
+
+// Get implements the Store interface.
+func (m *Memory) Get(ctx context.Context, id string) (Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op, ok := m.ops[id]
+	if !ok {
+		return Operation{}, ErrNotFound
+	}
+	return op, nil
+}
+
+// sweep discards the operations finished before the retention period, at most once a minute.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.swept) < time.Minute {
+		return
+	}
+
+	m.swept = now
+	for id, op := range m.ops {
+		if op.Status.Done() && now.Sub(op.UpdatedAt) > m.retention {
+			delete(m.ops, id)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Package operations provides a godi module for long-running operations: handlers start
+// an operation and respond with 202 and its ID right away, the operation reports its
+// progress while it runs in the background, and clients poll its status at
+// "/operations/{id}".
+//
+//	Imports: []godi.Module{
+//		&operations.Module{},
+//	}
+//
+//	func (c *ReportController) handleExport(w http.ResponseWriter, r *http.Request) {
+//		op, err := c.operations.Start(r.Context(), func(ctx context.Context, p *operations.Progress) (any, error) {
+//			for i, chunk := range chunks {
+//				...
+//				p.Update(float64(i+1)/float64(len(chunks)), "exporting")
+//			}
+//			return ExportResult{URL: url}, nil
+//		})
+//		...
+//		c.operations.Accept(w, op)
+//	}
+//
+// Operations run by other processes, e.g. the workers of a job queue, are created with
+// Create and reported with Update, Complete and Fail, through a Store shared with them.
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+const (
+	defaultPath      = "/operations"
+	defaultRetention = 24 * time.Hour
+)
+
+// ErrNotFound is returned by Stores for operations they do not hold.
+var ErrNotFound = errors.New("operations: not found")
+
+// Status is the status of an operation.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Done reports whether operations with the status have finished.
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
+
+// Operation is the state of a long-running operation.
+type Operation struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+
+	// Progress is the completed fraction of the operation, from 0 to 1.
+	Progress float64 `json:"progress"`
+
+	// Message describes the current step of the operation.
+	Message string `json:"message,omitempty"`
+
+	// Result is the JSON encoding of the result of a succeeded operation.
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error describes why a failed operation failed.
+	Error string `json:"error,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store stores the state of operations.
+type Store interface {
+	// Save creates or replaces the operation. Finished operations may be discarded
+	// after the retention period.
+	Save(ctx context.Context, op Operation) error
+
+	// Get returns the operation with the ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (Operation, error)
+}
+
+// Module provides the Manager of operations to every module of the application and
+// serves their status.
+type Module struct {
+	// Store stores the operations. Defaults to an in-memory store, which only holds
+	// the operations of a single instance.
+	Store Store
+
+	// Path is the path the status of operations is served under. Defaults to "/operations".
+	Path string
+
+	// Retention is how long finished operations are kept by the in-memory store. Defaults to a day.
+	Retention time.Duration
+
+	// Guards are applied to the status endpoint.
+	Guards []godi.Guard
+
+	// GuardsCtors provides constructors for guards that require dependency injection.
+	GuardsCtors []godi.GuardConstructor
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newManager},
+		ExportsCtors:   []godi.ProviderConstructor{m.newManager},
+		ControllersCtors: []godi.ControllerConstructor{
+			func(manager *Manager) *controller {
+				return &controller{module: m, manager: manager}
+			},
+		},
+	}
+}
+
+// newManager returns the manager, generating operation IDs with the application's ID
+// generator and waiting for running operations once in-flight requests are drained.
+func (m *Module) newManager(server *godi.HttpServer, ids godi.IDGenerator) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mgr := &Manager{
+		store:  m.Store,
+		ids:    ids,
+		path:   m.Path,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if mgr.store == nil {
+		mgr.store = NewMemory(m.Retention)
+	}
+	if mgr.path == "" {
+		mgr.path = defaultPath
+	}
+
+	server.OnShutdown(mgr.Close, godi.PostDrain)
+	return mgr
+}
+
+// Manager starts operations and tracks their state.
+type Manager struct {
+	store Store
+	ids   godi.IDGenerator
+	path  string
+
+	ctx    context.Context // canceled when the manager is closed.
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Create creates a pending operation, to be run and reported by another process.
+func (m *Manager) Create(ctx context.Context) (Operation, error) {
+	now := time.Now().UTC()
+	op := Operation{
+		ID:        m.ids.NewID(),
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := m.store.Save(ctx, op)
+	if err != nil {
+		return Operation{}, fmt.Errorf("operations: error creating operation: %w", err)
+	}
+	return op, nil
+}
+
+// Start creates an operation and runs fn in the background, recording its progress,
+// and its result or error once it returns. The context passed to fn is canceled when
+// the application shuts down and fn did not return before the shutdown timeout.
+func (m *Manager) Start(ctx context.Context, fn func(ctx context.Context, p *Progress) (any, error)) (Operation, error) {
+	op, err := m.Create(ctx)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		// the operation outlives the request, but keeps its logger and correlation fields
+		ctx := context.WithoutCancel(ctx)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(m.ctx, cancel)
+		defer stop()
+
+		log := godi.LoggerFrom(ctx).With("operation", op.ID)
+		p := &Progress{manager: m, id: op.ID, ctx: ctx}
+
+		_ = m.Update(ctx, op.ID, 0, "")
+
+		result, err := run(ctx, fn, p)
+		if err != nil {
+			log.Error("operation failed", "error", err)
+			err = m.Fail(ctx, op.ID, err)
+		} else {
+			err = m.Complete(ctx, op.ID, result)
+		}
+		if err != nil {
+			log.Error("error recording operation outcome", "error", err)
+		}
+	}()
+
+	return op, nil
+}
+
+// run calls fn, recovering from panics.
+func run(ctx context.Context, fn func(ctx context.Context, p *Progress) (any, error), p *Progress) (result any, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("operation panicked: %v", v)
+		}
+	}()
+	return fn(ctx, p)
+}
+
+// Get returns the operation with the ID.
+func (m *Manager) Get(ctx context.Context, id string) (Operation, error) {
+	return m.store.Get(ctx, id)
+}
+
+// Update records the progress of the running operation, a fraction from 0 to 1.
+func (m *Manager) Update(ctx context.Context, id string, progress float64, message string) error {
+	return m.update(ctx, id, func(op *Operation) error {
+		op.Status = StatusRunning
+		op.Progress = min(max(progress, 0), 1)
+		op.Message = message
+		return nil
+	})
+}
+
+// Complete records the success of the operation and the result it returned.
+func (m *Manager) Complete(ctx context.Context, id string, result any) error {
+	return m.update(ctx, id, func(op *Operation) error {
+		if result != nil {
+			data, err := json.Marshal(result)
+			if err != nil {
+				return fmt.Errorf("error encoding result: %w", err)
+			}
+			op.Result = data
+		}
+		op.Status = StatusSucceeded
+		op.Progress = 1
+		op.Message = ""
+		return nil
+	})
+}
+
+// Fail records the failure of the operation. The error is shown to clients polling it.
+func (m *Manager) Fail(ctx context.Context, id string, cause error) error {
+	return m.update(ctx, id, func(op *Operation) error {
+		op.Status = StatusFailed
+		op.Error = cause.Error()
+		return nil
+	})
+}
+
+func (m *Manager) update(ctx context.Context, id string, fn func(op *Operation) error) error {
+	op, err := m.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("operations: error retrieving operation (%s): %w", id, err)
+	}
+	if op.Status.Done() {
+		return fmt.Errorf("operations: operation (%s) already %s", id, op.Status)
+	}
+
+	err = fn(&op)
+	if err != nil {
+		return fmt.Errorf("operations: error updating operation (%s): %w", id, err)
+	}
+	op.UpdatedAt = time.Now().UTC()
+
+	err = m.store.Save(ctx, op)
+	if err != nil {
+		return fmt.Errorf("operations: error saving operation (%s): %w", id, err)
+	}
+	return nil
+}
+
+// URL returns the path the status of the operation is served at.
+func (m *Manager) URL(id string) string {
+	return path.Join(m.path, id)
+}
+
+// Accept responds to the request that started the operation with 202, the operation
+// as JSON, and a Location header pointing at its status.
+func (m *Manager) Accept(w http.ResponseWriter, op Operation) {
+	w.Header().Set("Location", m.URL(op.ID))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(op)
+}
+
+// Close cancels the operations still running once ctx is done, and waits for them to return.
+func (m *Manager) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		return ctx.Err()
+	}
+}
+
+// Progress reports the progress of an operation started with Start.
+type Progress struct {
+	manager *Manager
+	id      string
+	ctx     context.Context
+}
+
+// ID returns the ID of the operation.
+func (p *Progress) ID() string {
+	return p.id
+}
+
+// Update records the completed fraction of the operation, from 0 to 1, and its current step.
+func (p *Progress) Update(progress float64, message string) {
+	err := p.manager.Update(p.ctx, p.id, progress, message)
+	if err != nil {
+		godi.LoggerFrom(p.ctx).Error("error recording operation progress", "operation", p.id, "error", err)
+	}
+}
+
+// controller serves the status of operations.
+type controller struct {
+	module  *Module
+	manager *Manager
+}
+
+func (c *controller) Config() *godi.ControllerConfig {
+	return &godi.ControllerConfig{
+		Pattern:     c.manager.path,
+		Guards:      c.module.Guards,
+		GuardsCtors: c.module.GuardsCtors,
+		RoutesCfgs: []*godi.RouteConfig{
+			{Method: http.MethodGet, Pattern: "/{id}", Handler: http.HandlerFunc(c.handleGet)},
+		},
+	}
+}
+
+// handleGet responds with the operation, telling clients polling unfinished
+// operations when to poll again.
+func (c *controller) handleGet(w http.ResponseWriter, r *http.Request) {
+	op, err := c.manager.Get(r.Context(), r.PathValue("id"))
+	if errors.Is(err, ErrNotFound) {
+		godi.RenderError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		godi.RenderError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !op.Status.Done() {
+		w.Header().Set("Retry-After", "1")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(op)
+}
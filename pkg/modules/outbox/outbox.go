@@ -0,0 +1,245 @@
+// Package outbox provides a godi module implementing the transactional outbox
+// pattern: events are stored in the same database transaction as the business
+// change producing them, and a background relay publishes the stored events to
+// the message transport, so no event is lost or published for a rolled back change.
+//
+//	Imports: []godi.Module{
+//		&outbox.Module{Publisher: kafkaPublisher, Locker: &lock.Redis{Addr: "redis:6379"}},
+//	}
+//
+//	func (s *OrderService) Place(ctx context.Context, order Order) error {
+//		tx, err := s.db.BeginTx(ctx, nil)
+//		...
+//		err = s.outbox.Add(ctx, tx, "orders.placed", order.ID, order)
+//		...
+//		return tx.Commit()
+//	}
+//
+// The module depends on a *sql.DB provided by a global module. Events are stored
+// in a table created with a schema like [Schema].
+//
+// Events are delivered at least once: an event published right before the relay
+// stops may be published again, so consumers should deduplicate them by ID.
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/huboh/godi"
+	"github.com/huboh/godi/pkg/modules/health"
+	"github.com/huboh/godi/pkg/modules/lock"
+)
+
+// Schema is the Postgres schema of the default outbox table.
+// Other databases use the same columns with their own types.
+const Schema = `CREATE TABLE IF NOT EXISTS outbox (
+	id           BIGSERIAL PRIMARY KEY,
+	topic        TEXT NOT NULL,
+	event_key    TEXT NOT NULL,
+	payload      BYTEA NOT NULL,
+	created_at   TIMESTAMP NOT NULL,
+	published_at TIMESTAMP NULL
+)`
+
+// Event is an event stored in the outbox.
+type Event struct {
+	// ID identifies the event. Consumers can use it to deduplicate events.
+	ID int64
+
+	// Topic is the topic the event is published to.
+	Topic string
+
+	// Key identifies the entity the event is about, e.g. to partition events.
+	Key string
+
+	// Payload is the encoded event.
+	Payload []byte
+
+	// CreatedAt is the time the event was added to the outbox.
+	CreatedAt time.Time
+}
+
+// Publisher publishes events to the message transport.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// PublisherFunc is a function implementing Publisher.
+type PublisherFunc func(ctx context.Context, event Event) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// Execer executes statements, e.g. a *sql.Tx or *sql.DB.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Placeholder returns the bind parameter of the nth argument of a query.
+type Placeholder func(n int) string
+
+// Dollar returns Postgres style placeholders, e.g. "$1".
+func Dollar(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Question returns MySQL and SQLite style placeholders, e.g. "?".
+func Question(n int) string {
+	return "?"
+}
+
+// Module stores events in the outbox and relays them to the publisher while
+// the server runs. It is global, so every module can add events to the outbox.
+type Module struct {
+	// Publisher publishes the stored events.
+	Publisher Publisher
+
+	// Table is the table events are stored in. Defaults to "outbox".
+	Table string
+
+	// Placeholder formats the bind parameters of queries. Defaults to Dollar.
+	Placeholder Placeholder
+
+	// Interval is how often the relay polls for unpublished events. Defaults to a second.
+	Interval time.Duration
+
+	// BatchSize is the maximum number of events published per poll. Defaults to 100.
+	BatchSize int
+
+	// Locker, if set, makes a single instance of the application relay events at a time,
+	// preserving their order across instances.
+	Locker lock.Locker
+
+	// HealthCriticality is the probe failed by the outbox's health indicator
+	// when the database is unreachable. Defaults to readiness.
+	HealthCriticality health.Criticality
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	if m.Publisher == nil {
+		return errors.New("outbox: no publisher configured")
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal: true,
+		ProvidersCtors: []godi.ProviderConstructor{
+			m.newOutbox,
+			health.Contribute(m.newIndicator),
+		},
+		ExportsCtors: []godi.ProviderConstructor{m.newOutbox},
+	}
+}
+
+// newIndicator returns the health indicator of the outbox's database.
+func (m *Module) newIndicator(db *sql.DB) *health.Indicator {
+	return &health.Indicator{
+		Name:        "outbox",
+		Criticality: m.HealthCriticality,
+		Check:       db.PingContext,
+	}
+}
+
+// newOutbox returns the outbox, starting its relay with the server.
+func (m *Module) newOutbox(db *sql.DB, app *godi.App) (*Outbox, error) {
+	o := &Outbox{
+		db:    db,
+		table: cmp.Or(m.Table, "outbox"),
+	}
+	if m.Placeholder != nil {
+		o.placeholder = m.Placeholder
+	} else {
+		o.placeholder = Dollar
+	}
+
+	r := &relay{
+		outbox:    o,
+		publisher: m.Publisher,
+		interval:  cmp.Or(m.Interval, time.Second),
+		batchSize: cmp.Or(m.BatchSize, 100),
+		locker:    m.Locker,
+		logger:    app.Logger(),
+	}
+
+	app.OnStart(r.start)
+	app.OnShutdown(r.stop, godi.PostDrain)
+
+	return o, nil
+}
+
+// Outbox stores events to be published.
+type Outbox struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+// Add stores an event in the outbox using tx, which is usually the transaction
+// of the business change producing the event. The payload is stored as is if it
+// is a []byte, and encoded as JSON otherwise.
+func (o *Outbox) Add(ctx context.Context, tx Execer, topic, key string, payload any) error {
+	data, ok := payload.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("error encoding event payload: %w", err)
+		}
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (topic, event_key, payload, created_at) VALUES (%s, %s, %s, %s)",
+		o.table, o.placeholder(1), o.placeholder(2), o.placeholder(3), o.placeholder(4),
+	)
+
+	_, err := tx.ExecContext(ctx, query, topic, key, data, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("error adding event to outbox: %w", err)
+	}
+	return nil
+}
+
+// pending returns up to limit unpublished events, oldest first.
+func (o *Outbox) pending(ctx context.Context, limit int) ([]Event, error) {
+	query := fmt.Sprintf(
+		"SELECT id, topic, event_key, payload, created_at FROM %s WHERE published_at IS NULL ORDER BY id LIMIT %d",
+		o.table, limit,
+	)
+
+	rows, err := o.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Topic, &e.Key, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// markPublished records that the event has been published.
+func (o *Outbox) markPublished(ctx context.Context, id int64) error {
+	query := fmt.Sprintf(
+		"UPDATE %s SET published_at = %s WHERE id = %s",
+		o.table, o.placeholder(1), o.placeholder(2),
+	)
+
+	_, err := o.db.ExecContext(ctx, query, time.Now().UTC(), id)
+	return err
+}
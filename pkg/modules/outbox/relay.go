@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/huboh/godi/pkg/modules/lock"
+)
+
+// maxElectionBackoff bounds the delay between the campaigns of a relay failing to elect a leader.
+const maxElectionBackoff = time.Minute
+
+// relay periodically publishes the unpublished events of the outbox.
+type relay struct {
+	outbox    *Outbox
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+	locker    lock.Locker
+	logger    *slog.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// start runs the relay in the background once the server listens.
+func (r *relay) start(_ context.Context, _ net.Addr) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		if r.locker == nil {
+			r.run(ctx)
+			return
+		}
+
+		r.campaign(ctx)
+	}()
+
+	return nil
+}
+
+// campaign runs the relay whenever this instance is elected to, until ctx is done.
+// Failing elections, e.g. when the lock's store is unreachable, are retried with
+// an exponential backoff.
+func (r *relay) campaign(ctx context.Context) {
+	var (
+		elector = lock.NewLeaderElector(r.locker, "outbox:"+r.outbox.table, 3*r.interval)
+		backoff = r.interval
+	)
+
+	for {
+		start := time.Now()
+		err := elector.Run(ctx, func(ctx context.Context) error {
+			r.run(ctx)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		// elections failing after a long campaign are retried promptly
+		if time.Since(start) > maxElectionBackoff {
+			backoff = r.interval
+		}
+		r.logger.Error("outbox: error electing relay", "error", err, "retryIn", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxElectionBackoff)
+	}
+}
+
+// stop stops the relay once in-flight requests have finished adding events.
+func (r *relay) stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run publishes pending events every interval until ctx is done.
+func (r *relay) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		err := r.publishPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("outbox: error relaying events", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishPending publishes a batch of pending events in order, stopping at the
+// first event that fails to publish so it is retried before later events.
+func (r *relay) publishPending(ctx context.Context) error {
+	events, err := r.outbox.pending(ctx, r.batchSize)
+	if err != nil {
+		return fmt.Errorf("error reading pending events: %w", err)
+	}
+
+	for _, event := range events {
+		err := r.publisher.Publish(ctx, event)
+		if err != nil {
+			return fmt.Errorf("error publishing event (%d): %w", event.ID, err)
+		}
+
+		err = r.outbox.markPublished(ctx, event.ID)
+		if err != nil {
+			return fmt.Errorf("error marking event (%d) as published: %w", event.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Store counting the requests of a single process.
+// It is useful in development and tests.
+type Memory struct {
+	mu       sync.Mutex
+	counters map[string]memoryCounter
+	swept    time.Time
+}
+
+type memoryCounter struct {
+	used   int64
+	expiry time.Time
+}
+
+// NewMemory returns an in-memory Store.
+func NewMemory() *Memory {
+	return &Memory{counters: map[string]memoryCounter{}}
+}
+
+// Increment implements the Store interface.
+func (m *Memory) Increment(ctx context.Context, key string, expiry time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	c := m.counters[key]
+	if !now.Before(c.expiry) {
+		c = memoryCounter{expiry: expiry}
+	}
+	c.used++
+	m.counters[key] = c
+
+	return c.used, nil
+}
+
+// sweep discards the expired counters at most once an hour, so the counters
+// of past periods do not accumulate.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.swept) < time.Hour {
+		return
+	}
+
+	m.swept = now
+	for key, c := range m.counters {
+		if !now.Before(c.expiry) {
+			delete(m.counters, key)
+		}
+	}
+}
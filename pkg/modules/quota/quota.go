@@ -0,0 +1,283 @@
+// Package quota provides a godi module tracking the requests each consumer of an API
+// makes per day or month, e.g. per API key or per client IP, and a guard rejecting the
+// requests of consumers that exhausted their quota with 429.
+//
+// Usage is counted in a pluggable Store, so quotas are shared by every instance of the
+// application when the store is, and routes opt in by adding the guard:
+//
+//	Imports: []godi.Module{
+//		&quota.Module{
+//			Limits: []quota.Limit{{Requests: 1000, Period: quota.Day}, {Requests: 20000, Period: quota.Month}},
+//			Key:    quota.ByPrincipal(func(key *APIKey) string { return key.ID }),
+//		},
+//	}
+//
+//	func (c *SearchController) Config() *godi.ControllerConfig {
+//		return &godi.ControllerConfig{
+//			GuardsCtors: []godi.GuardConstructor{NewAPIKeyGuard, quota.NewGuard},
+//			...
+//		}
+//	}
+//
+// Quota keys must identify authenticated consumers, taken from the principal attached by
+// the guard authenticating requests with ByPrincipal, as consumers evade their quota by
+// sending another key otherwise, e.g. with ByHeader and an API key that is not verified.
+//
+// Responses report the quota with the X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset
+// headers, the latter holding the seconds until the quota resets, for the limit closest
+// to being exhausted. Rejected requests also carry a Retry-After header.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Headers reporting the quota of the consumer of a request.
+const (
+	LimitHeader     = "X-Quota-Limit"
+	RemainingHeader = "X-Quota-Remaining"
+	ResetHeader     = "X-Quota-Reset"
+)
+
+// ErrQuotaExceeded is rendered with 429 when the consumer of a request exhausted its quota.
+var ErrQuotaExceeded = errors.New("quota: exceeded")
+
+// Period is the calendar period a quota applies to. Periods start at midnight UTC.
+type Period int
+
+const (
+	Day Period = iota
+	Month
+)
+
+func (p Period) String() string {
+	switch p {
+	case Day:
+		return "day"
+	case Month:
+		return "month"
+	}
+	return "unknown"
+}
+
+// window returns the start and the end of the period containing t.
+func (p Period) window(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	switch p {
+	case Month:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+// Limit is the number of requests a consumer can make per period.
+type Limit struct {
+	Requests int64
+	Period   Period
+}
+
+// Store counts the requests of consumers. Implementations backed by a shared database
+// enforce quotas across the instances of the application.
+type Store interface {
+	// Increment increments the usage counted under key and returns it. The usage
+	// can be discarded once the expiry passes.
+	Increment(ctx context.Context, key string, expiry time.Time) (int64, error)
+}
+
+// KeyFunc returns the key identifying the consumer of a request, reporting false
+// if the request has no consumer, in which case its quota is not enforced.
+type KeyFunc func(r *http.Request) (string, bool)
+
+// ByHeader identifies consumers by the value of a request header. The value is not
+// authenticated, so it must be verified by an earlier guard, e.g. as an API key.
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) (string, bool) {
+		v := r.Header.Get(name)
+		return v, v != ""
+	}
+}
+
+// ByIP identifies consumers by the IP address of the request's client, as returned by
+// godi.ClientIP. Clients sharing an address, e.g. behind a NAT, share their quota.
+func ByIP() KeyFunc {
+	return func(r *http.Request) (string, bool) {
+		ip := godi.ClientIP(r)
+		return ip, ip != ""
+	}
+}
+
+// ByPrincipal identifies consumers by the principal attached to the request by a guard,
+// e.g. its user or tenant ID. The quota guard must then run after the guard authenticating
+// requests.
+func ByPrincipal[T any](id func(principal T) string) KeyFunc {
+	return func(r *http.Request) (string, bool) {
+		principal, ok := godi.PrincipalFrom[T](r.Context())
+		if !ok {
+			return "", false
+		}
+		key := id(principal)
+		return key, key != ""
+	}
+}
+
+// Module provides the Tracker counting the requests of consumers to every module of
+// the application.
+type Module struct {
+	// Store counts the requests. Defaults to an in-memory store, which only counts
+	// the requests of a single instance.
+	Store Store
+
+	// Limits are the quotas of every consumer.
+	Limits []Limit
+
+	// LimitsFor returns the quotas of a consumer, e.g. from its subscription plan.
+	// It overrides Limits if set.
+	LimitsFor func(ctx context.Context, key string) ([]Limit, error)
+
+	// Key identifies the consumer of requests. Defaults to ByIP.
+	Key KeyFunc
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newTracker},
+		ExportsCtors:   []godi.ProviderConstructor{m.newTracker},
+	}
+}
+
+func (m *Module) newTracker() *Tracker {
+	t := &Tracker{
+		store:     m.Store,
+		key:       m.Key,
+		limitsFor: m.LimitsFor,
+	}
+	if t.store == nil {
+		t.store = NewMemory()
+	}
+	if t.key == nil {
+		t.key = ByIP()
+	}
+	if t.limitsFor == nil {
+		t.limitsFor = func(context.Context, string) ([]Limit, error) { return m.Limits, nil }
+	}
+	return t
+}
+
+// Tracker counts the requests of consumers against their quotas.
+type Tracker struct {
+	store     Store
+	key       KeyFunc
+	limitsFor func(ctx context.Context, key string) ([]Limit, error)
+}
+
+// Usage is the state of a consumer's quota for a period.
+type Usage struct {
+	Limit Limit
+	Used  int64
+	Reset time.Time
+}
+
+// Remaining returns the number of requests left until the quota resets.
+func (u Usage) Remaining() int64 {
+	return max(u.Limit.Requests-u.Used, 0)
+}
+
+// Exceeded reports whether the request counted last exceeded the quota.
+func (u Usage) Exceeded() bool {
+	return u.Used > u.Limit.Requests
+}
+
+// Track counts a request of the consumer identified by key against each of its quotas,
+// returning their usage.
+func (t *Tracker) Track(ctx context.Context, key string) ([]Usage, error) {
+	limits, err := t.limitsFor(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving quotas of consumer: %w", err)
+	}
+
+	var (
+		now   = time.Now()
+		usage = make([]Usage, 0, len(limits))
+	)
+
+	for _, limit := range limits {
+		start, reset := limit.Period.window(now)
+
+		used, err := t.store.Increment(ctx, counterKey(key, limit.Period, start), reset)
+		if err != nil {
+			return nil, fmt.Errorf("error counting request: %w", err)
+		}
+		usage = append(usage, Usage{Limit: limit, Used: used, Reset: reset})
+	}
+	return usage, nil
+}
+
+// counterKey returns the key the requests of a consumer are counted under for the period starting at start.
+func counterKey(key string, p Period, start time.Time) string {
+	return "quota:" + key + ":" + p.String() + ":" + start.Format("2006-01-02")
+}
+
+// Guard is a godi.Guard counting the requests of the routes it applies to and rejecting
+// them with 429 and ErrQuotaExceeded once their consumer exhausted one of its quotas.
+type Guard struct {
+	tracker *Tracker
+}
+
+// NewGuard returns a guard enforcing the quotas tracked by the tracker.
+// It can be listed in the GuardsCtors of a controller or route.
+func NewGuard(t *Tracker) *Guard {
+	return &Guard{tracker: t}
+}
+
+// Allow implements the godi.Guard interface.
+func (g *Guard) Allow(gctx godi.GuardContext) (bool, error) {
+	var (
+		w = gctx.Http.W
+		r = gctx.Http.R
+	)
+
+	key, ok := g.tracker.key(r)
+	if !ok {
+		return true, nil
+	}
+
+	usage, err := g.tracker.Track(r.Context(), key)
+	if err != nil || len(usage) == 0 {
+		return err == nil, err
+	}
+
+	// report the quota closest to being exhausted, or the exceeded one resetting last
+	closest := usage[0]
+	for _, u := range usage[1:] {
+		switch {
+		case u.Exceeded() && closest.Exceeded():
+			if u.Reset.After(closest.Reset) {
+				closest = u
+			}
+		case u.Exceeded() || (!closest.Exceeded() && u.Remaining() < closest.Remaining()):
+			closest = u
+		}
+	}
+
+	reset := strconv.FormatInt(int64(time.Until(closest.Reset).Seconds())+1, 10)
+	w.Header().Set(LimitHeader, strconv.FormatInt(closest.Limit.Requests, 10))
+	w.Header().Set(RemainingHeader, strconv.FormatInt(closest.Remaining(), 10))
+	w.Header().Set(ResetHeader, reset)
+
+	if closest.Exceeded() {
+		w.Header().Set("Retry-After", reset)
+		return false, &godi.StatusError{Status: http.StatusTooManyRequests, Err: ErrQuotaExceeded}
+	}
+	return true, nil
+}
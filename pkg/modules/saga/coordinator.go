@@ -0,0 +1,201 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Definition defines a saga operating on data of type T.
+type Definition[T any] struct {
+	// Name identifies the saga in persisted records.
+	Name string
+
+	// Steps are executed in order.
+	Steps []Step[T]
+}
+
+// Step is a step of a saga.
+type Step[T any] struct {
+	// Name identifies the step in errors.
+	Name string
+
+	// Do executes the step. Changes it makes to data are persisted once it succeeds.
+	Do func(ctx context.Context, data *T) error
+
+	// Compensate undoes the step after a later step failed. It is optional for
+	// steps that have nothing to undo, and must be safe to run more than once.
+	Compensate func(ctx context.Context, data *T) error
+}
+
+// StepError is returned when a step of a saga fails.
+type StepError struct {
+	// Step is the name of the failed step.
+	Step string
+
+	// Compensated reports whether the completed steps were compensated.
+	Compensated bool
+
+	// Err is the error returned by the step, joined with the compensation error, if any.
+	Err error
+}
+
+func (e *StepError) Error() string {
+	if e.Compensated {
+		return fmt.Sprintf("saga step (%s) failed and was compensated: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("saga step (%s) failed: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Coordinator executes sagas, persisting their progress in a Store.
+type Coordinator struct {
+	store Store
+}
+
+// NewCoordinator returns a coordinator persisting sagas in the store.
+func NewCoordinator(store Store) *Coordinator {
+	return &Coordinator{store: store}
+}
+
+// Load returns the persisted state of the saga.
+func (c *Coordinator) Load(ctx context.Context, id string) (Record, error) {
+	return c.store.Load(ctx, id)
+}
+
+// Run starts the saga identified by id, executing its steps on data.
+//
+// If a step fails, the steps completed before it are compensated in reverse
+// order and a *StepError is returned.
+func Run[T any](ctx context.Context, c *Coordinator, def *Definition[T], id string, data *T) error {
+	record := Record{
+		ID:     id,
+		Saga:   def.Name,
+		Status: StatusRunning,
+	}
+
+	return execute(ctx, c, def, record, data)
+}
+
+// Resume continues the saga identified by id from its last persisted step, e.g.
+// after the application crashed while it was running or compensating.
+// Completed and compensated sagas are not executed again.
+func Resume[T any](ctx context.Context, c *Coordinator, def *Definition[T], id string) error {
+	record, err := c.store.Load(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error loading saga (%s): %w", id, err)
+	}
+	if record.Saga != def.Name {
+		return fmt.Errorf("saga (%s) is a %q saga, not %q", id, record.Saga, def.Name)
+	}
+
+	data := new(T)
+	err = json.Unmarshal(record.Data, data)
+	if err != nil {
+		return fmt.Errorf("error decoding saga (%s) data: %w", id, err)
+	}
+
+	switch record.Status {
+	case StatusCompleted, StatusCompensated:
+		return nil
+
+	case StatusCompensating, StatusFailed:
+		return compensate(ctx, c, def, record, data, errors.New(record.Error))
+	}
+
+	return execute(ctx, c, def, record, data)
+}
+
+// execute runs the saga's steps from the record's step, compensating on failure.
+func execute[T any](ctx context.Context, c *Coordinator, def *Definition[T], record Record, data *T) error {
+	if err := save(ctx, c, &record, data); err != nil {
+		return err
+	}
+
+	for record.Step < len(def.Steps) {
+		step := def.Steps[record.Step]
+
+		err := step.Do(ctx, data)
+		if err != nil {
+			record.Status = StatusCompensating
+			record.Error = fmt.Sprintf("step (%s): %v", step.Name, err)
+			record.Step--
+
+			return compensate(ctx, c, def, record, data, &StepError{Step: step.Name, Err: err})
+		}
+
+		record.Step++
+		if err := save(ctx, c, &record, data); err != nil {
+			return err
+		}
+	}
+
+	record.Status = StatusCompleted
+	return save(ctx, c, &record, data)
+}
+
+// compensate undoes the completed steps in reverse order, starting with the
+// record's step, and returns cause.
+func compensate[T any](ctx context.Context, c *Coordinator, def *Definition[T], record Record, data *T, cause error) error {
+	stepErr, ok := cause.(*StepError)
+	if !ok {
+		stepErr = &StepError{Err: cause}
+	}
+
+	record.Status = StatusCompensating
+	if err := save(ctx, c, &record, data); err != nil {
+		return errors.Join(stepErr, err)
+	}
+
+	for ; record.Step >= 0; record.Step-- {
+		step := def.Steps[record.Step]
+		if step.Compensate == nil {
+			continue
+		}
+
+		err := step.Compensate(ctx, data)
+		if err != nil {
+			record.Status = StatusFailed
+			stepErr.Err = errors.Join(stepErr.Err, fmt.Errorf("error compensating step (%s): %w", step.Name, err))
+
+			if err := save(ctx, c, &record, data); err != nil {
+				return errors.Join(stepErr, err)
+			}
+			return stepErr
+		}
+
+		if err := save(ctx, c, &record, data); err != nil {
+			return errors.Join(stepErr, err)
+		}
+	}
+
+	record.Status = StatusCompensated
+	stepErr.Compensated = true
+
+	if err := save(ctx, c, &record, data); err != nil {
+		return errors.Join(stepErr, err)
+	}
+	return stepErr
+}
+
+// save persists the record with the current data.
+func save[T any](ctx context.Context, c *Coordinator, record *Record, data *T) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error encoding saga (%s) data: %w", record.ID, err)
+	}
+
+	record.Data = encoded
+	record.UpdatedAt = time.Now()
+
+	err = c.store.Save(ctx, *record)
+	if err != nil {
+		return fmt.Errorf("error saving saga (%s): %w", record.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package saga
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MemoryStore is a Store keeping sagas in memory. It is useful in development and tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore returns an in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+// Save implements the Store interface.
+func (s *MemoryStore) Save(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Data = slices.Clone(record.Data)
+	s.records[record.ID] = record
+	return nil
+}
+
+// Load implements the Store interface.
+func (s *MemoryStore) Load(ctx context.Context, id string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[id]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	return record, nil
+}
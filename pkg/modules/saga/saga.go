@@ -0,0 +1,107 @@
+// Package saga provides a godi module coordinating multi-step business flows as
+// sagas: each step has a compensation undoing it, and when a step fails the steps
+// completed before it are compensated in reverse order. Progress is persisted in a
+// pluggable Store, so sagas interrupted by a crash can be resumed.
+//
+// Sagas are defined in constructors, so their steps use dependency-injected services:
+//
+//	func NewCheckout(payments *PaymentService, stock *StockService) *saga.Definition[Order] {
+//		return &saga.Definition[Order]{
+//			Name: "checkout",
+//			Steps: []saga.Step[Order]{
+//				{Name: "reserve", Do: stock.Reserve, Compensate: stock.Release},
+//				{Name: "charge", Do: payments.Charge, Compensate: payments.Refund},
+//			},
+//		}
+//	}
+//
+//	err := saga.Run(ctx, coordinator, checkout, order.ID, &order)
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Status is the state of a saga.
+type Status string
+
+const (
+	// StatusRunning is the status of a saga whose steps are being executed.
+	StatusRunning Status = "running"
+
+	// StatusCompleted is the status of a saga whose steps all succeeded.
+	StatusCompleted Status = "completed"
+
+	// StatusCompensating is the status of a saga whose completed steps are being compensated.
+	StatusCompensating Status = "compensating"
+
+	// StatusCompensated is the status of a saga whose completed steps were all compensated.
+	StatusCompensated Status = "compensated"
+
+	// StatusFailed is the status of a saga whose compensation failed,
+	// which needs to be resolved manually or resumed.
+	StatusFailed Status = "failed"
+)
+
+// ErrNotFound is returned by stores when a saga does not exist.
+var ErrNotFound = errors.New("saga: not found")
+
+// Record is the persisted state of a saga.
+type Record struct {
+	// ID identifies the saga instance.
+	ID string
+
+	// Saga is the name of the saga's definition.
+	Saga string
+
+	// Status is the state of the saga.
+	Status Status
+
+	// Step is the index of the next step to execute, or to compensate
+	// while the saga is being compensated.
+	Step int
+
+	// Data is the JSON encoded data of the saga.
+	Data []byte
+
+	// Error is the message of the error that failed the saga, if any.
+	Error string
+
+	// UpdatedAt is the time the record was last saved.
+	UpdatedAt time.Time
+}
+
+// Store persists the state of sagas.
+type Store interface {
+	// Save creates or updates the record.
+	Save(ctx context.Context, record Record) error
+
+	// Load returns the record of the saga, or ErrNotFound.
+	Load(ctx context.Context, id string) (Record, error)
+}
+
+// Module provides the saga Coordinator to every module of the application.
+type Module struct {
+	// Store persists the state of sagas. Defaults to an in-memory store,
+	// which does not survive restarts.
+	Store Store
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newCoordinator},
+		ExportsCtors:   []godi.ProviderConstructor{m.newCoordinator},
+	}
+}
+
+func (m *Module) newCoordinator() *Coordinator {
+	if m.Store == nil {
+		return NewCoordinator(NewMemoryStore())
+	}
+	return NewCoordinator(m.Store)
+}
@@ -0,0 +1,305 @@
+// Package signing provides a godi module verifying the signatures of service-to-service
+// requests, and a Signer signing outgoing requests, so internal endpoints only accept
+// requests from services holding a known key.
+//
+// Requests are signed over their method, URI, timestamp and body digest, with an
+// HMAC-SHA256 secret shared by both services or an Ed25519 key pair:
+//
+//	Imports: []godi.Module{
+//		&signing.Module{Keys: signing.StaticKeys{"billing": {PublicKey: billingKey}}},
+//	}
+//
+//	func (c *InternalController) Config() *godi.ControllerConfig {
+//		return &godi.ControllerConfig{
+//			GuardsCtors: []godi.GuardConstructor{signing.NewGuard},
+//			...
+//		}
+//	}
+//
+//	// in the calling service
+//	client := &http.Client{Transport: signer.Transport(nil)}
+//
+// Verified requests carry a Caller principal identifying the key they were signed with.
+// Keys are looked up with the module's KeyProvider, or with the KeyProvider provided by
+// another module if it has none, e.g. one reading them from a secret store.
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Headers carrying the signature of a request and the digest of its body.
+const (
+	// SignatureHeader holds the key ID, timestamp and signature of a request,
+	// e.g. "keyId=billing,t=1700000000,v1=<hex>".
+	SignatureHeader = "Request-Signature"
+
+	// DigestHeader holds the SHA-256 digest of the request body as defined by RFC 9530,
+	// e.g. "sha-256=:<base64>:".
+	DigestHeader = "Content-Digest"
+)
+
+const (
+	defaultTolerance   = 5 * time.Minute
+	defaultMaxBodySize = 10 << 20
+)
+
+var (
+	// ErrInvalidSignature is rendered with 401 when a request is unsigned, signed with an
+	// unknown key, expired, or its signature or body digest does not match.
+	ErrInvalidSignature = errors.New("signing: invalid signature")
+
+	// ErrUnknownKey is returned by KeyProviders for key IDs they do not know.
+	ErrUnknownKey = errors.New("signing: unknown key")
+)
+
+// Key verifies the signatures of a caller. Exactly one of its fields is set.
+type Key struct {
+	// Secret is the HMAC-SHA256 secret shared with the caller.
+	Secret []byte
+
+	// PublicKey is the Ed25519 public key of the caller.
+	PublicKey ed25519.PublicKey
+}
+
+// KeyProvider looks up the keys verifying signatures by their ID.
+type KeyProvider interface {
+	Key(ctx context.Context, id string) (Key, error)
+}
+
+// KeyProviderFunc is a function implementing KeyProvider.
+type KeyProviderFunc func(ctx context.Context, id string) (Key, error)
+
+// Key implements the KeyProvider interface.
+func (fn KeyProviderFunc) Key(ctx context.Context, id string) (Key, error) {
+	return fn(ctx, id)
+}
+
+// StaticKeys is a KeyProvider of a fixed set of keys, by ID.
+type StaticKeys map[string]Key
+
+// Key implements the KeyProvider interface.
+func (s StaticKeys) Key(ctx context.Context, id string) (Key, error) {
+	key, ok := s[id]
+	if !ok {
+		return Key{}, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Caller is the principal of verified requests.
+type Caller struct {
+	// KeyID is the ID of the key the request was signed with.
+	KeyID string
+}
+
+// Module provides the Verifier of request signatures to every module of the application.
+type Module struct {
+	// Keys looks up the keys of callers. If nil, the KeyProvider provided by
+	// another module is used.
+	Keys KeyProvider
+
+	// Tolerance is how long signatures are valid after they are made, and how far in the
+	// future their timestamp may be to allow for clock skew. Defaults to 5 minutes.
+	Tolerance time.Duration
+
+	// MaxBodySize bounds the size of the bodies read to verify their digest. Defaults to 10 MiB.
+	MaxBodySize int64
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	providers := []godi.ProviderConstructor{m.newVerifier}
+	if m.Keys != nil {
+		providers = append(providers, func() KeyProvider { return m.Keys })
+	}
+
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: providers,
+		ExportsCtors:   []godi.ProviderConstructor{m.newVerifier},
+	}
+}
+
+func (m *Module) newVerifier(keys KeyProvider) *Verifier {
+	v := &Verifier{
+		keys:        keys,
+		tolerance:   m.Tolerance,
+		maxBodySize: m.MaxBodySize,
+	}
+	if v.tolerance <= 0 {
+		v.tolerance = defaultTolerance
+	}
+	if v.maxBodySize <= 0 {
+		v.maxBodySize = defaultMaxBodySize
+	}
+	return v
+}
+
+// Verifier verifies the signatures of requests.
+type Verifier struct {
+	keys        KeyProvider
+	tolerance   time.Duration
+	maxBodySize int64
+}
+
+// Verify verifies the signature and body digest of the request, returning the ID of the
+// key it was signed with. The body is read and replaced, so handlers can read it again.
+func (v *Verifier) Verify(r *http.Request) (string, error) {
+	sig, ok := parseSignature(r.Header.Get(SignatureHeader))
+	if !ok {
+		return "", ErrInvalidSignature
+	}
+
+	if age := time.Since(time.Unix(sig.timestamp, 0)); age > v.tolerance || age < -v.tolerance {
+		return "", fmt.Errorf("%w: expired", ErrInvalidSignature)
+	}
+
+	key, err := v.keys.Key(r.Context(), sig.keyID)
+	if errors.Is(err, ErrUnknownKey) {
+		return "", fmt.Errorf("%w: unknown key (%s)", ErrInvalidSignature, sig.keyID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("signing: error looking up key (%s): %w", sig.keyID, err)
+	}
+
+	body, err := readBody(r, v.maxBodySize)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	digest := contentDigest(body)
+	if !hmac.Equal([]byte(r.Header.Get(DigestHeader)), []byte(digest)) {
+		return "", fmt.Errorf("%w: body digest mismatch", ErrInvalidSignature)
+	}
+
+	payload := signedPayload(r.Method, r.URL.RequestURI(), sig.timestamp, digest)
+	if !key.verify(payload, sig.value) {
+		return "", ErrInvalidSignature
+	}
+	return sig.keyID, nil
+}
+
+func (k Key) verify(payload, sig []byte) bool {
+	switch {
+	case len(k.PublicKey) == ed25519.PublicKeySize:
+		return ed25519.Verify(k.PublicKey, payload, sig)
+	case len(k.Secret) > 0:
+		return hmac.Equal(sig, hmacSum(k.Secret, payload))
+	}
+	return false
+}
+
+// Guard is a godi.Guard rejecting the requests whose signature is invalid with 401
+// and ErrInvalidSignature, and attaching the Caller principal to verified requests.
+type Guard struct {
+	verifier *Verifier
+}
+
+// NewGuard returns a guard verifying request signatures with the verifier.
+// It can be listed in the GuardsCtors of a controller or route.
+func NewGuard(v *Verifier) *Guard {
+	return &Guard{verifier: v}
+}
+
+// Allow implements the godi.Guard interface.
+func (g *Guard) Allow(gctx godi.GuardContext) (bool, error) {
+	keyID, err := g.verifier.Verify(gctx.Http.R)
+	if errors.Is(err, ErrInvalidSignature) {
+		godi.LoggerFrom(gctx.Context()).Info("request signature rejected", "error", err)
+		return false, &godi.StatusError{Status: http.StatusUnauthorized, Err: err}
+	}
+	if err != nil {
+		return false, err
+	}
+
+	gctx.SetPrincipal(Caller{KeyID: keyID})
+	return true, nil
+}
+
+// signature is a parsed signature header.
+type signature struct {
+	keyID     string
+	timestamp int64
+	value     []byte
+}
+
+func parseSignature(header string) (signature, bool) {
+	var (
+		sig      signature
+		t, value string
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		key, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "keyId":
+			sig.keyID = v
+		case "t":
+			t = v
+		case "v1":
+			value = v
+		}
+	}
+
+	var err1, err2 error
+	sig.timestamp, err1 = strconv.ParseInt(t, 10, 64)
+	sig.value, err2 = hex.DecodeString(value)
+	return sig, sig.keyID != "" && err1 == nil && err2 == nil && len(sig.value) > 0
+}
+
+// signedPayload returns the bytes signed for a request.
+func signedPayload(method, uri string, timestamp int64, digest string) []byte {
+	return []byte(strings.Join([]string{method, uri, strconv.FormatInt(timestamp, 10), digest}, "\n"))
+}
+
+// contentDigest returns the Content-Digest header value of the body.
+func contentDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+}
+
+func hmacSum(secret, payload []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// readBody reads the request body up to max bytes, or entirely if max is zero, and
+// replaces it, so it can be read again.
+func readBody(r *http.Request, max int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body := io.Reader(r.Body)
+	if max > 0 {
+		body = io.LimitReader(r.Body, max+1)
+	}
+
+	data, err := io.ReadAll(body)
+	r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+	if max > 0 && int64(len(data)) > max {
+		return nil, errors.New("body too large")
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -0,0 +1,152 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		secret   = []byte("secret")
+		verifier = (&Module{}).newVerifier(StaticKeys{"hmac": {Secret: secret}, "ed25519": {PublicKey: pub}})
+	)
+
+	tests := []struct {
+		name    string
+		signer  *Signer
+		body    string
+		getBody bool
+	}{
+		{"hmac with GetBody", &Signer{KeyID: "hmac", Secret: secret}, `{"amount":42}`, true},
+		{"hmac without GetBody", &Signer{KeyID: "hmac", Secret: secret}, `{"amount":42}`, false},
+		{"ed25519 with GetBody", &Signer{KeyID: "ed25519", PrivateKey: priv}, `{"amount":42}`, true},
+		{"ed25519 without GetBody", &Signer{KeyID: "ed25519", PrivateKey: priv}, `{"amount":42}`, false},
+		{"no body", &Signer{KeyID: "hmac", Secret: secret}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRequest(t, tt.body, tt.getBody)
+
+			err := tt.signer.Sign(r)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			if got := r.Header.Get(DigestHeader); got != contentDigest([]byte(tt.body)) {
+				t.Errorf("digest = %q, want the digest of %q", got, tt.body)
+			}
+			if got := readAll(t, r); got != tt.body {
+				t.Errorf("body after Sign = %q, want %q", got, tt.body)
+			}
+
+			// the verifying service receives the body as sent
+			r.Body = io.NopCloser(strings.NewReader(tt.body))
+			keyID, err := verifier.Verify(r)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if keyID != tt.signer.KeyID {
+				t.Errorf("key ID = %q, want %q", keyID, tt.signer.KeyID)
+			}
+			if got := readAll(t, r); got != tt.body {
+				t.Errorf("body after Verify = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	var (
+		secret   = []byte("secret")
+		signer   = &Signer{KeyID: "hmac", Secret: secret}
+		verifier = (&Module{}).newVerifier(StaticKeys{"hmac": {Secret: secret}})
+	)
+
+	tests := []struct {
+		name   string
+		tamper func(r *http.Request)
+	}{
+		{"tampered body", func(r *http.Request) {
+			r.Body = io.NopCloser(strings.NewReader(`{"amount":4200}`))
+		}},
+		{"tampered URI", func(r *http.Request) {
+			r.URL.Path = "/transfers/other"
+		}},
+		{"unknown key", func(r *http.Request) {
+			r.Header.Set(SignatureHeader, strings.Replace(r.Header.Get(SignatureHeader), "keyId=hmac", "keyId=other", 1))
+		}},
+		{"unsigned", func(r *http.Request) {
+			r.Header.Del(SignatureHeader)
+		}},
+		{"body too large", func(r *http.Request) {
+			r.Body = io.NopCloser(strings.NewReader(strings.Repeat("a", defaultMaxBodySize+1)))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRequest(t, `{"amount":42}`, false)
+			if err := signer.Sign(r); err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			tt.tamper(r)
+
+			_, err := verifier.Verify(r)
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		expired := &Signer{KeyID: "hmac", Secret: secret, Now: func() time.Time {
+			return time.Now().Add(-time.Hour)
+		}}
+
+		r := newRequest(t, `{"amount":42}`, true)
+		if err := expired.Sign(r); err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+
+		_, err := verifier.Verify(r)
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Verify = %v, want ErrInvalidSignature", err)
+		}
+	})
+}
+
+// newRequest returns a request with the body, whose GetBody is only set if getBody is.
+func newRequest(t *testing.T, body string, getBody bool) *http.Request {
+	t.Helper()
+
+	r, err := http.NewRequest(http.MethodPost, "http://billing.internal/transfers?dry=1", bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !getBody {
+		r.GetBody = nil
+	}
+	return r
+}
+
+func readAll(t *testing.T, r *http.Request) string {
+	t.Helper()
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return string(data)
+}
@@ -0,0 +1,41 @@
+package totp
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Memory is a RecoveryStore keeping recovery codes in the memory of a single process.
+// It is useful in development and tests.
+type Memory struct {
+	mu     sync.Mutex
+	hashes map[string][]string
+}
+
+// NewMemory returns an in-memory RecoveryStore.
+func NewMemory() *Memory {
+	return &Memory{hashes: map[string][]string{}}
+}
+
+// Replace implements the RecoveryStore interface.
+func (m *Memory) Replace(ctx context.Context, account string, hashes []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hashes[account] = slices.Clone(hashes)
+	return nil
+}
+
+// Consume implements the RecoveryStore interface.
+func (m *Memory) Consume(ctx context.Context, account string, hash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.Index(m.hashes[account], hash)
+	if i < 0 {
+		return false, nil
+	}
+	m.hashes[account] = slices.Delete(m.hashes[account], i, i+1)
+	return true, nil
+}
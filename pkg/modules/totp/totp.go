@@ -0,0 +1,289 @@
+// Package totp provides a godi module for two-factor authentication with time-based
+// one-time passwords as defined by RFC 6238, compatible with authenticator apps, and
+// single-use recovery codes for users who lost their device.
+//
+//	Imports: []godi.Module{
+//		&totp.Module{Issuer: "Acme", Recovery: recoveryStore},
+//	}
+//
+//	// enrollment: store the secret with the user, render the URI as a QR code
+//	secret, err := c.totp.Secret()
+//	uri := c.totp.URI(user.Email, secret)
+//	codes, err := c.totp.RecoveryCodes(ctx, user.ID, 10)
+//
+//	// login, after the password was verified
+//	step, ok := c.totp.VerifyStep(user.TOTPSecret, code, user.LastTOTPStep)
+//	if !ok {
+//		ok, err = c.totp.Recover(ctx, user.ID, code)
+//	}
+//
+// The module only verifies codes: the session or token marking a user as having passed
+// the second factor is issued by the application once verification succeeds.
+package totp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Defaults of the module, matching those of common authenticator apps.
+const (
+	defaultDigits = 6
+	defaultPeriod = 30 * time.Second
+	defaultSkew   = 1
+	secretSize    = 20
+)
+
+// ErrNoRecoveryStore is returned when using recovery codes without a RecoveryStore configured.
+var ErrNoRecoveryStore = errors.New("totp: no recovery store configured")
+
+// encoding is the unpadded base32 encoding of secrets expected by authenticator apps.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryStore stores the hashes of the recovery codes of accounts.
+type RecoveryStore interface {
+	// Replace replaces the recovery code hashes of the account.
+	Replace(ctx context.Context, account string, hashes []string) error
+
+	// Consume removes the hash from the recovery code hashes of the account,
+	// reporting whether it was one of them.
+	Consume(ctx context.Context, account string, hash string) (bool, error)
+}
+
+// Module provides the TOTP service to every module of the application.
+type Module struct {
+	// Issuer names the application in authenticator apps.
+	Issuer string
+
+	// Digits is the number of digits of codes. Defaults to 6.
+	Digits int
+
+	// Period is how long codes are valid, as a whole number of seconds, as authenticator
+	// apps expect. Defaults to 30 seconds.
+	Period time.Duration
+
+	// Skew is the number of periods before and after the current one whose codes are
+	// accepted, to allow for clock drift. Defaults to 1; a negative skew accepts none.
+	Skew int
+
+	// Recovery stores recovery codes. Recovery codes cannot be used if nil.
+	Recovery RecoveryStore
+}
+
+// Validate implements the godi.Validator interface.
+func (m *Module) Validate() error {
+	if m.Issuer == "" {
+		return errors.New("totp: no issuer configured")
+	}
+	if m.Digits != 0 && (m.Digits < 6 || m.Digits > 8) {
+		return fmt.Errorf("totp: invalid number of digits (%d), must be 6 to 8", m.Digits)
+	}
+	if m.Period != 0 && (m.Period < time.Second || m.Period%time.Second != 0) {
+		return fmt.Errorf("totp: invalid period (%s), must be a whole number of seconds", m.Period)
+	}
+	return nil
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newTOTP},
+		ExportsCtors:   []godi.ProviderConstructor{m.newTOTP},
+	}
+}
+
+func (m *Module) newTOTP() *TOTP {
+	t := &TOTP{
+		issuer:   m.Issuer,
+		digits:   m.Digits,
+		period:   m.Period,
+		skew:     m.Skew,
+		recovery: m.Recovery,
+		now:      time.Now,
+	}
+	if t.digits == 0 {
+		t.digits = defaultDigits
+	}
+	if t.period == 0 {
+		t.period = defaultPeriod
+	}
+	if t.skew == 0 {
+		t.skew = defaultSkew
+	}
+	if t.skew < 0 {
+		t.skew = 0
+	}
+	return t
+}
+
+// TOTP generates secrets and verifies one-time passwords and recovery codes.
+type TOTP struct {
+	issuer   string
+	digits   int
+	period   time.Duration
+	skew     int
+	recovery RecoveryStore
+	now      func() time.Time
+}
+
+// Secret returns a new random base32-encoded secret.
+func (t *TOTP) Secret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("totp: error generating secret: %w", err)
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// URI returns the otpauth provisioning URI of the account's secret,
+// to be rendered as a QR code scanned by authenticator apps.
+func (t *TOTP) URI(account, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", t.issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", strconv.Itoa(t.digits))
+	q.Set("period", strconv.Itoa(int(t.period.Seconds())))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + t.issuer + ":" + account,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+// Code returns the current code of the secret.
+func (t *TOTP) Code(secret string) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return t.code(key, t.step(t.now())), nil
+}
+
+// Verify reports whether the code is valid for the secret at the current time.
+func (t *TOTP) Verify(secret, code string) bool {
+	_, ok := t.VerifyStep(secret, code, 0)
+	return ok
+}
+
+// VerifyStep reports whether the code is valid for the secret at the current time and
+// was generated after the lastStep, returning its time step. Storing the step of the
+// last accepted code and passing it on the next verification prevents code replays.
+func (t *TOTP) VerifyStep(secret, code string, lastStep int64) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(code) != t.digits {
+		return 0, false
+	}
+
+	current := t.step(t.now())
+	for i := -t.skew; i <= t.skew; i++ {
+		step := current + int64(i)
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(t.code(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// RecoveryCodes generates n recovery codes for the account, replacing its previous ones,
+// and returns them to be shown to the user once. Only their hashes are stored.
+func (t *TOTP) RecoveryCodes(ctx context.Context, account string, n int) ([]string, error) {
+	if t.recovery == nil {
+		return nil, ErrNoRecoveryStore
+	}
+
+	codes := make([]string, n)
+	hashes := make([]string, n)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("totp: error generating recovery code: %w", err)
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	err := t.recovery.Replace(ctx, account, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("totp: error storing recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// Recover reports whether the code is an unused recovery code of the account,
+// consuming it if so.
+func (t *TOTP) Recover(ctx context.Context, account, code string) (bool, error) {
+	if t.recovery == nil {
+		return false, ErrNoRecoveryStore
+	}
+
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	ok, err := t.recovery.Consume(ctx, account, hashRecoveryCode(code))
+	if err != nil {
+		return false, fmt.Errorf("totp: error consuming recovery code: %w", err)
+	}
+	return ok, nil
+}
+
+// step returns the time step of the time.
+func (t *TOTP) step(now time.Time) int64 {
+	return now.Unix() / int64(t.period.Seconds())
+}
+
+// code returns the code of the key at the time step, as defined by RFC 4226.
+func (t *TOTP) code(key []byte, step int64) string {
+	mac := hmac.New(sha1.New, key)
+	binary.Write(mac, binary.BigEndian, step)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range t.digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", t.digits, value%mod)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	key, err := encoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("totp: invalid secret: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, errors.New("totp: empty secret")
+	}
+	return key, nil
+}
+
+// hashRecoveryCode returns the stored hash of a normalized recovery code. Codes have
+// 40 bits of entropy and are single-use, so a fast hash is sufficient.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,181 @@
+package totp
+
+import (
+	"context"
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the test vectors of RFC 6238, "12345678901234567890".
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// the SHA1 test vectors of RFC 6238, appendix B
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			totp := newTestTOTP(&Module{Issuer: "Acme", Digits: 8}, tt.unix)
+
+			got, err := totp.Code(rfcSecret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+			}
+			if !totp.Verify(rfcSecret, tt.want) {
+				t.Errorf("Verify(%s) at %d = false", tt.want, tt.unix)
+			}
+		})
+	}
+
+	t.Run("six digits", func(t *testing.T) {
+		got, err := newTestTOTP(&Module{Issuer: "Acme"}, 59).Code(rfcSecret)
+		if err != nil || got != "287082" {
+			t.Errorf("Code = %s, %v, want 287082", got, err)
+		}
+	})
+}
+
+func TestVerifyStep(t *testing.T) {
+	const now = 1111111111 // step 37037037
+
+	codeAt := func(unix int64) string {
+		code, err := newTestTOTP(&Module{Issuer: "Acme"}, unix).Code(rfcSecret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		skew     int
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current", 0, codeAt(now), 0, 37037037, true},
+		{"previous period", 1, codeAt(now - 30), 0, 37037036, true},
+		{"next period", 1, codeAt(now + 30), 0, 37037038, true},
+		{"previous period without skew", -1, codeAt(now - 30), 0, 0, false},
+		{"two periods ago", 1, codeAt(now - 60), 0, 0, false},
+		{"replayed", 1, codeAt(now), 37037037, 0, false},
+		{"after an earlier code", 1, codeAt(now), 37037036, 37037037, true},
+		{"wrong code", 1, "000000", 0, 0, false},
+		{"wrong length", 1, codeAt(now)[:5], 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			totp := newTestTOTP(&Module{Issuer: "Acme", Skew: tt.skew}, now)
+
+			step, ok := totp.VerifyStep(rfcSecret, tt.code, tt.lastStep)
+			if step != tt.wantStep || ok != tt.wantOK {
+				t.Errorf("VerifyStep = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	t.Run("lowercase secret with spaces", func(t *testing.T) {
+		totp := newTestTOTP(&Module{Issuer: "Acme"}, now)
+
+		secret := strings.ToLower(rfcSecret[:8] + " " + rfcSecret[8:])
+		if !totp.Verify(secret, codeAt(now)) {
+			t.Error("Verify = false, want true")
+		}
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		totp := newTestTOTP(&Module{Issuer: "Acme"}, now)
+
+		if totp.Verify("not base32!", codeAt(now)) {
+			t.Error("Verify = true, want false")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Module
+		ok   bool
+	}{
+		{"defaults", Module{Issuer: "Acme"}, true},
+		{"no issuer", Module{}, false},
+		{"8 digits", Module{Issuer: "Acme", Digits: 8}, true},
+		{"5 digits", Module{Issuer: "Acme", Digits: 5}, false},
+		{"9 digits", Module{Issuer: "Acme", Digits: 9}, false},
+		{"60s period", Module{Issuer: "Acme", Period: time.Minute}, true},
+		{"sub-second period", Module{Issuer: "Acme", Period: 500 * time.Millisecond}, false},
+		{"fractional period", Module{Issuer: "Acme", Period: 1500 * time.Millisecond}, false},
+		{"negative period", Module{Issuer: "Acme", Period: -time.Second}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.m.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		totp = newTestTOTP(&Module{Issuer: "Acme", Recovery: NewMemory()}, 59)
+	)
+
+	codes, err := totp.RecoveryCodes(ctx, "user-1", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 3 {
+		t.Fatalf("RecoveryCodes = %v, want 3 codes", codes)
+	}
+
+	tests := []struct {
+		name    string
+		account string
+		code    string
+		want    bool
+	}{
+		{"code", "user-1", codes[0], true},
+		{"used code", "user-1", codes[0], false},
+		{"uppercase without dash", "user-1", strings.ToUpper(strings.ReplaceAll(codes[1], "-", "")), true},
+		{"other account", "user-2", codes[2], false},
+		{"unknown code", "user-1", "aaaa-aaaa", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := totp.Recover(ctx, tt.account, tt.code)
+			if err != nil || ok != tt.want {
+				t.Errorf("Recover = %v, %v, want %v", ok, err, tt.want)
+			}
+		})
+	}
+}
+
+// newTestTOTP returns the TOTP of the module at the unix time.
+func newTestTOTP(m *Module, unix int64) *TOTP {
+	t := m.newTOTP()
+	t.now = func() time.Time { return time.Unix(unix, 0) }
+	return t
+}
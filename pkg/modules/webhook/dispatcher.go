@@ -0,0 +1,324 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxFinished is the number of finished deliveries kept for inspection.
+const maxFinished = 1000
+
+// ErrClosed is returned when sending webhooks with a closed dispatcher.
+var ErrClosed = errors.New("webhook: dispatcher closed")
+
+// Endpoint is a receiver of webhooks.
+type Endpoint struct {
+	// ID identifies the endpoint.
+	ID string
+
+	// URL is where webhooks are posted.
+	URL string
+
+	// Secret signs the webhooks sent to the endpoint.
+	Secret string
+
+	// Events lists the events sent to the endpoint. Every event is sent if empty.
+	Events []string
+}
+
+func (e Endpoint) subscribed(event string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, event)
+}
+
+// Status is the state of a delivery.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+
+	// StatusCanceled is the status of the deliveries interrupted by closing the dispatcher.
+	StatusCanceled Status = "canceled"
+)
+
+// Delivery is the state of a webhook sent to an endpoint.
+type Delivery struct {
+	ID         string     `json:"id"`
+	EndpointID string     `json:"endpointId"`
+	Event      string     `json:"event"`
+	Status     Status     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	StatusCode int        `json:"statusCode,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	NextRetry  *time.Time `json:"nextRetry,omitempty"`
+}
+
+func (d *Delivery) clone() Delivery {
+	c := *d
+	if d.NextRetry != nil {
+		next := *d.NextRetry
+		c.NextRetry = &next
+	}
+	return c
+}
+
+// Dispatcher sends webhooks to the registered endpoints.
+type Dispatcher struct {
+	opts Options
+	sem  chan struct{}
+	wg   sync.WaitGroup
+
+	ctx    context.Context // canceled once closed, stopping the scheduling of attempts.
+	cancel context.CancelFunc
+
+	attemptCtx context.Context // canceled once closing timed out, aborting in-flight attempts.
+	abort      context.CancelFunc
+
+	mu         sync.Mutex
+	endpoints  map[string]Endpoint
+	deliveries map[string]*Delivery
+	finished   []string
+}
+
+// NewDispatcher returns a dispatcher with the options.
+func NewDispatcher(opts Options) *Dispatcher {
+	opts = opts.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	attemptCtx, abort := context.WithCancel(context.Background())
+
+	return &Dispatcher{
+		opts:       opts,
+		sem:        make(chan struct{}, opts.Concurrency),
+		ctx:        ctx,
+		cancel:     cancel,
+		attemptCtx: attemptCtx,
+		abort:      abort,
+		endpoints:  map[string]Endpoint{},
+		deliveries: map[string]*Delivery{},
+	}
+}
+
+// Register adds or replaces an endpoint.
+func (d *Dispatcher) Register(endpoint Endpoint) error {
+	if endpoint.ID == "" || endpoint.URL == "" {
+		return errors.New("webhook: endpoint ID and URL are required")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.endpoints[endpoint.ID] = endpoint
+	return nil
+}
+
+// Unregister removes an endpoint. Pending deliveries to it are still attempted.
+func (d *Dispatcher) Unregister(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.endpoints, id)
+}
+
+// Send encodes the payload as JSON and delivers it in the background to every
+// endpoint subscribed to the event, returning the IDs of the deliveries.
+func (d *Dispatcher) Send(ctx context.Context, event string, payload any) ([]string, error) {
+	body, err := json.Marshal(map[string]any{"event": event, "data": payload})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// checked with the lock held, so no delivery starts once Close waits for them
+	if d.ctx.Err() != nil {
+		return nil, ErrClosed
+	}
+
+	var ids []string
+	for _, endpoint := range d.endpoints {
+		if !endpoint.subscribed(event) {
+			continue
+		}
+
+		delivery := &Delivery{
+			ID:         d.opts.IDs.NewID(),
+			EndpointID: endpoint.ID,
+			Event:      event,
+			Status:     StatusPending,
+			CreatedAt:  time.Now(),
+		}
+		d.deliveries[delivery.ID] = delivery
+		ids = append(ids, delivery.ID)
+
+		d.wg.Add(1)
+		go d.deliver(endpoint, delivery.ID, body)
+	}
+
+	return ids, nil
+}
+
+// Delivery returns the state of the delivery, if it is known.
+func (d *Dispatcher) Delivery(id string) (Delivery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[id]
+	if !ok {
+		return Delivery{}, false
+	}
+	return delivery.clone(), true
+}
+
+// Deliveries returns the pending deliveries and the most recently finished ones.
+func (d *Dispatcher) Deliveries() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]Delivery, 0, len(d.deliveries))
+	for _, delivery := range d.deliveries {
+		deliveries = append(deliveries, delivery.clone())
+	}
+
+	slices.SortFunc(deliveries, func(a, b Delivery) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return deliveries
+}
+
+// Close stops scheduling delivery attempts and retries, and waits for in-flight attempts
+// to finish. In-flight attempts are aborted once ctx is done. Deliveries that are not
+// attempted again are marked StatusCanceled.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	d.cancel()
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.abort()
+		return ctx.Err()
+	}
+}
+
+// deliver attempts the delivery until it succeeds, runs out of attempts or the dispatcher is closed.
+func (d *Dispatcher) deliver(endpoint Endpoint, id string, body []byte) {
+	defer d.wg.Done()
+
+	backoff := d.opts.Backoff
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case d.sem <- struct{}{}:
+		case <-d.ctx.Done():
+			d.update(id, canceled)
+			return
+		}
+		code, err := d.attempt(endpoint, body)
+		<-d.sem
+
+		if err == nil {
+			d.update(id, func(dl *Delivery) {
+				dl.Status, dl.Attempts, dl.StatusCode, dl.Error, dl.NextRetry = StatusSucceeded, attempt, code, "", nil
+			})
+			return
+		}
+
+		if attempt >= d.opts.MaxAttempts {
+			d.update(id, func(dl *Delivery) {
+				dl.Status, dl.Attempts, dl.StatusCode, dl.Error, dl.NextRetry = StatusFailed, attempt, code, err.Error(), nil
+			})
+			return
+		}
+
+		next := time.Now().Add(backoff)
+		d.update(id, func(dl *Delivery) {
+			dl.Attempts, dl.StatusCode, dl.Error, dl.NextRetry = attempt, code, err.Error(), &next
+		})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-d.ctx.Done():
+			timer.Stop()
+			d.update(id, canceled)
+			return
+		}
+
+		backoff = min(2*backoff, d.opts.MaxBackoff)
+	}
+}
+
+// canceled marks a delivery interrupted by closing the dispatcher.
+func canceled(dl *Delivery) {
+	dl.Status, dl.Error, dl.NextRetry = StatusCanceled, ErrClosed.Error(), nil
+}
+
+// attempt posts the signed body to the endpoint, returning the response status code.
+func (d *Dispatcher) attempt(endpoint Endpoint, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(d.attemptCtx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now(), body))
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// update applies fn to the delivery, evicting the oldest finished deliveries
+// once more than maxFinished are kept.
+func (d *Dispatcher) update(id string, fn func(*Delivery)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delivery, ok := d.deliveries[id]
+	if !ok {
+		return
+	}
+
+	fn(delivery)
+
+	if delivery.Status != StatusPending {
+		d.finished = append(d.finished, id)
+		if len(d.finished) > maxFinished {
+			delete(d.deliveries, d.finished[0])
+			d.finished = d.finished[1:]
+		}
+	}
+}
+
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
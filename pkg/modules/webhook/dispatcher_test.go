@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcherClose(t *testing.T) {
+	var (
+		started = make(chan struct{}, 1)
+		release = make(chan struct{})
+		srv     = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/slow":
+				started <- struct{}{}
+				<-release
+			case "/failing":
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+	)
+	defer srv.Close()
+
+	d := NewDispatcher(Options{Backoff: time.Hour})
+	for _, id := range []string{"slow", "failing"} {
+		if err := d.Register(Endpoint{ID: id, URL: srv.URL + "/" + id, Events: []string{id}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	slow := send(t, d, "slow")
+	failing := send(t, d, "failing")
+	waitFor(t, d, failing, func(dl Delivery) bool { return dl.Attempts == 1 })
+	<-started
+
+	closed := make(chan error)
+	go func() { closed <- d.Close(context.Background()) }()
+
+	// the in-flight attempt finishes before Close returns
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if dl, _ := d.Delivery(slow); dl.Status != StatusSucceeded {
+		t.Errorf("in-flight delivery status = %s, want %s", dl.Status, StatusSucceeded)
+	}
+	if dl, _ := d.Delivery(failing); dl.Status != StatusCanceled || dl.NextRetry != nil {
+		t.Errorf("retried delivery status = %s, next retry %v, want %s without retry", dl.Status, dl.NextRetry, StatusCanceled)
+	}
+
+	_, err := d.Send(context.Background(), "slow", nil)
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("Send after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestDispatcherCloseAborts(t *testing.T) {
+	var (
+		started = make(chan struct{}, 1)
+		release = make(chan struct{})
+		srv     = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		}))
+	)
+	defer srv.Close()
+	defer close(release)
+
+	d := NewDispatcher(Options{})
+	if err := d.Register(Endpoint{ID: "hanging", URL: srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	id := send(t, d, "event")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := d.Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close = %v, want context.DeadlineExceeded", err)
+	}
+	waitFor(t, d, id, func(dl Delivery) bool { return dl.Status == StatusCanceled })
+}
+
+func TestDispatcherSendDuringClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	d := NewDispatcher(Options{})
+	if err := d.Register(Endpoint{ID: "endpoint", URL: srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := d.Send(context.Background(), "event", nil); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	wg.Wait()
+
+	for _, dl := range d.Deliveries() {
+		if dl.Status == StatusPending {
+			t.Fatalf("delivery %s still pending after Close", dl.ID)
+		}
+	}
+}
+
+func send(t *testing.T, d *Dispatcher, event string) string {
+	t.Helper()
+
+	ids, err := d.Send(context.Background(), event, map[string]string{"id": "1"})
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("Send = %v, %v, want one delivery", ids, err)
+	}
+	return ids[0]
+}
+
+// waitFor waits for the delivery to satisfy cond.
+func waitFor(t *testing.T, d *Dispatcher, id string, cond func(Delivery) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		dl, ok := d.Delivery(id)
+		if ok && cond(dl) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery %s: unexpected state %+v", id, dl)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
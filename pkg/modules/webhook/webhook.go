@@ -0,0 +1,103 @@
+// Package webhook provides a godi module dispatching signed webhooks to registered
+// endpoints, retrying failed deliveries with exponential backoff and keeping their
+// status for inspection.
+//
+//	Imports: []godi.Module{
+//		&webhook.Module{MaxAttempts: 8},
+//	}
+//
+//	func (s *OrderService) Ship(ctx context.Context, order Order) error {
+//		...
+//		_, err := s.webhooks.Send(ctx, "order.shipped", order)
+//		return err
+//	}
+//
+// Payloads are signed with the endpoint's secret, and receivers verify them with
+// [Verify]. Deliveries are kept in memory and are not resumed after a restart.
+package webhook
+
+import (
+	"cmp"
+	"net/http"
+	"time"
+
+	"github.com/huboh/godi"
+)
+
+// Module provides the webhook Dispatcher to every module of the application.
+type Module struct {
+	// Endpoints are registered with the dispatcher when it is created.
+	Endpoints []Endpoint
+
+	// Client sends the webhooks. Defaults to a client with a 10 second timeout.
+	Client *http.Client
+
+	// MaxAttempts is the number of times a delivery is attempted. Defaults to 5.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, doubled on every retry. Defaults to a second.
+	Backoff time.Duration
+
+	// MaxBackoff caps the delay between retries. Defaults to an hour.
+	MaxBackoff time.Duration
+
+	// Concurrency is the maximum number of deliveries attempted at once. Defaults to 10.
+	Concurrency int
+}
+
+func (m *Module) Config() *godi.ModuleConfig {
+	return &godi.ModuleConfig{
+		IsGlobal:       true,
+		ProvidersCtors: []godi.ProviderConstructor{m.newDispatcher},
+		ExportsCtors:   []godi.ProviderConstructor{m.newDispatcher},
+	}
+}
+
+// newDispatcher returns the dispatcher, generating delivery IDs with the application's
+// ID generator and closing it once in-flight requests are drained.
+func (m *Module) newDispatcher(server *godi.HttpServer, ids godi.IDGenerator) (*Dispatcher, error) {
+	d := NewDispatcher(Options{
+		Client:      m.Client,
+		MaxAttempts: m.MaxAttempts,
+		Backoff:     m.Backoff,
+		MaxBackoff:  m.MaxBackoff,
+		Concurrency: m.Concurrency,
+		IDs:         ids,
+	})
+
+	for _, endpoint := range m.Endpoints {
+		err := d.Register(endpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	server.OnShutdown(d.Close, godi.PostDrain)
+	return d, nil
+}
+
+// Options configures a Dispatcher.
+type Options struct {
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Concurrency int
+
+	// IDs generates the IDs of deliveries. Defaults to random hexadecimal IDs.
+	IDs godi.IDGenerator
+}
+
+func (o Options) withDefaults() Options {
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if o.IDs == nil {
+		o.IDs = godi.IDGeneratorFunc(newID)
+	}
+	o.MaxAttempts = cmp.Or(o.MaxAttempts, 5)
+	o.Backoff = cmp.Or(o.Backoff, time.Second)
+	o.MaxBackoff = cmp.Or(o.MaxBackoff, time.Hour)
+	o.Concurrency = cmp.Or(o.Concurrency, 10)
+	return o
+}
@@ -0,0 +1,54 @@
+package godi
+
+import (
+	"context"
+	"sync"
+)
+
+// principalHolder holds the principal attached to a request by a guard.
+type principalHolder struct {
+	mu    sync.RWMutex
+	value any
+}
+
+// setPrincipal attaches the principal to the request context, reporting whether
+// the context belongs to a route's request.
+func setPrincipal(ctx context.Context, principal any) bool {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return false
+	}
+
+	h := state.principal
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.value = principal
+	return true
+}
+
+// PrincipalFrom returns the principal a guard attached to the request with
+// GuardContext.SetPrincipal, reporting whether one of type T was attached.
+//
+//	func (c *UserController) handleProfile(w http.ResponseWriter, r *http.Request) {
+//		claims, ok := godi.PrincipalFrom[*auth.Claims](r.Context())
+//		...
+//	}
+func PrincipalFrom[T any](ctx context.Context) (T, bool) {
+	principal, ok := principalFrom(ctx).(T)
+	return principal, ok
+}
+
+// principalFrom returns the principal attached to the request context, if any.
+func principalFrom(ctx context.Context) any {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return nil
+	}
+
+	h := state.principal
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.value
+}
@@ -0,0 +1,58 @@
+package godi
+
+import "slices"
+
+// Profile is the environment an application runs in, e.g. dev or prod, used to
+// select the modules and providers that are wired.
+//
+// The active profile can be injected into constructors.
+type Profile string
+
+// Common profiles.
+const (
+	ProfileDev     Profile = "dev"
+	ProfileTest    Profile = "test"
+	ProfileStaging Profile = "staging"
+	ProfileProd    Profile = "prod"
+)
+
+// WithProfile sets the profile the application runs with.
+//
+// Modules whose config lists Profiles are only imported, and providers registered
+// with OnProfile only provided, when one of their profiles is active.
+func WithProfile(p Profile) Option {
+	return func(o *options) {
+		o.profile = p
+	}
+}
+
+// Profile returns the profile the application runs with.
+func (a *App) Profile() Profile {
+	return a.opts.profile
+}
+
+// OnProfile returns a ProviderConstructor that is only provided when one of the
+// profiles is active, e.g. to use an in-memory store in tests:
+//
+//	ProvidersCtors: []godi.ProviderConstructor{
+//		godi.OnProfile(NewPostgresStore, godi.ProfileStaging, godi.ProfileProd),
+//		godi.OnProfile(NewMemoryStore, godi.ProfileDev, godi.ProfileTest),
+//	}
+func OnProfile(ctor ProviderConstructor, profiles ...Profile) ProviderConstructor {
+	a := annotate(ctor)
+	a.profiles = slices.Concat(a.profiles, profiles)
+	return a
+}
+
+// profileActive reports whether the active profile is one of the profiles.
+// An empty list of profiles is active in every profile.
+func (o *options) profileActive(profiles []Profile) bool {
+	return len(profiles) == 0 || slices.Contains(profiles, o.profile)
+}
+
+// isExcluded reports whether the provider constructor is restricted to
+// profiles other than the active one.
+func (a *App) isExcluded(ctor ProviderConstructor) bool {
+	an, ok := ctor.(annotated)
+	return ok && !a.opts.profileActive(an.profiles)
+}
@@ -1,5 +1,11 @@
 package godi
 
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
 // Provider is a marker interface for types that can be provided as dependencies.
 type Provider interface{}
 
@@ -21,3 +27,205 @@ type Provider interface{}
 // Any dependencies needed by the constructor will be resolved and instantiated
 // by the module's DI scope.
 type ProviderConstructor constructor
+
+// As returns a ProviderConstructor that provides the constructor's result both as
+// its own type and as the interface I, so consumers can depend on the interface
+// without manual adapter constructors. Both resolve to the same instance.
+//
+// As can be nested to bind a result to several interfaces.
+//
+// Example:
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.As[Mailer](NewSMTPMailer)}
+func As[I any](ctor ProviderConstructor) ProviderConstructor {
+	a := annotate(ctor)
+	a.as = append(a.as, reflect.TypeFor[I]())
+	return a
+}
+
+// Fallback returns a ProviderConstructor that is only used when no other provider
+// in the application produces any of the same types, e.g. a no-op Mailer or an
+// in-memory Cache. This makes modules usable out of the box while letting
+// applications override them with real implementations.
+//
+// Example:
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Fallback(godi.As[Mailer](NewNoopMailer))}
+func Fallback(ctor ProviderConstructor) ProviderConstructor {
+	a := annotate(ctor)
+	a.fallback = true
+	return a
+}
+
+// Group returns a ProviderConstructor that contributes the constructor's results
+// to the value group with the given name instead of providing them directly.
+//
+// Values contributed to a group are visible to every module in the application,
+// and any constructor can consume the full slice through a dig.In struct field or
+// a godi.Struct field tagged with the group name. This enables plugin-style
+// extension points between modules, such as health checks or migrations.
+//
+// When combined with As, the values are contributed as the interface only.
+//
+// Example:
+//
+//	ProvidersCtors: []godi.ProviderConstructor{
+//		godi.Group("health.checks", godi.As[HealthCheck](NewDatabaseCheck)),
+//	}
+//
+//	type HealthService struct {
+//		Checks []HealthCheck `godi:"inject,group=health.checks"`
+//	}
+func Group(name string, ctor ProviderConstructor) ProviderConstructor {
+	a := annotate(ctor)
+	a.group = name
+	return a
+}
+
+// annotated is a provider constructor annotated with options
+// controlling how its results are provided.
+type annotated struct {
+	ctor     constructor
+	as       []reflect.Type // interfaces the constructor's result is also provided as.
+	name     string         // the name the constructor's results are provided under.
+	group    string         // the value group the constructor's results are contributed to.
+	fallback bool           // whether the constructor is skipped when others provide its types.
+	profiles []Profile      // the profiles the constructor is provided in, if restricted.
+	err      error          // the error found while annotating the constructor.
+}
+
+// isFallback reports whether the provider constructor is a fallback.
+func isFallback(ctor ProviderConstructor) bool {
+	a, ok := ctor.(annotated)
+	return ok && a.fallback
+}
+
+// collectProvided records the result keys of every non-fallback provider
+// in the module tree of the active profile, so fallbacks can be skipped.
+func (a *App) collectProvided(m Module, visited map[string]bool) {
+	if m == nil || visited[GetToken(m)] {
+		return
+	}
+	visited[GetToken(m)] = true
+
+	mCfg := m.Config()
+	if mCfg == nil {
+		return
+	}
+
+	for _, pvdCtor := range mCfg.ProvidersCtors {
+		if isFallback(pvdCtor) || a.isExcluded(pvdCtor) {
+			continue
+		}
+		for _, k := range resultKeys(pvdCtor) {
+			a.provided[k] = true
+		}
+	}
+
+	for _, imported := range mCfg.Imports {
+		if imported != nil && imported.Config() != nil && !a.opts.profileActive(imported.Config().Profiles) {
+			continue
+		}
+		a.collectProvided(imported, visited)
+	}
+}
+
+// isOverridden reports whether the provider constructor is a fallback
+// and another provider in the application produces one of its types.
+func (a *App) isOverridden(ctor ProviderConstructor) bool {
+	if !isFallback(ctor) {
+		return false
+	}
+	for _, k := range resultKeys(ctor) {
+		if a.provided[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// annotate returns the annotations of a provider constructor,
+// wrapping it if it isn't annotated already.
+func annotate(ctor ProviderConstructor) annotated {
+	if a, ok := ctor.(annotated); ok {
+		a.as = slices.Clone(a.as)
+		a.profiles = slices.Clone(a.profiles)
+		return a
+	}
+	return annotated{ctor: ctor}
+}
+
+// result returns the type of the value produced by the constructor.
+func (a annotated) result() (reflect.Type, error) {
+	t := reflect.TypeOf(a.ctor)
+	if t == nil || t.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%T is not a function", a.ctor)
+	}
+
+	var results []reflect.Type
+	for i := range t.NumOut() {
+		if t.Out(i) != errorType {
+			results = append(results, t.Out(i))
+		}
+	}
+	if len(results) != 1 || isOutStruct(results[0]) {
+		return nil, fmt.Errorf("%s must produce a single value to be bound to an interface", funcName(a.ctor))
+	}
+
+	return results[0], nil
+}
+
+// binders returns constructors converting the constructor's result to each of the interfaces it is bound to.
+func (a annotated) binders() ([]any, error) {
+	if len(a.as) == 0 {
+		return nil, nil
+	}
+
+	result, err := a.result()
+	if err != nil {
+		return nil, err
+	}
+
+	var binders []any
+	for _, iface := range a.as {
+		if iface.Kind() != reflect.Interface {
+			return nil, fmt.Errorf("cannot bind %s to %v: not an interface", funcName(a.ctor), iface)
+		}
+		if !result.Implements(iface) {
+			return nil, fmt.Errorf("cannot bind %s to %v: %v does not implement it", funcName(a.ctor), iface, result)
+		}
+
+		binders = append(binders, reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{providerKey{t: result, name: a.name}.in()}, []reflect.Type{iface}, false),
+			func(args []reflect.Value) []reflect.Value {
+				v := reflect.New(iface).Elem()
+				v.Set(args[0].Field(1))
+				return []reflect.Value{v}
+			},
+		).Interface())
+	}
+
+	return binders, nil
+}
+
+// ExportedTypes returns the types of the values the module exports to the modules
+// importing it, in the order they are declared, e.g. to generate test doubles for them.
+func ExportedTypes(m Module) []reflect.Type {
+	mCfg := m.Config()
+	if mCfg == nil {
+		return nil
+	}
+
+	var types []reflect.Type
+	for _, export := range mCfg.Exports {
+		types = append(types, reflect.TypeOf(export))
+	}
+	for _, exportCtor := range mCfg.ExportsCtors {
+		for _, k := range resultKeys(exportCtor) {
+			if !slices.Contains(types, k.t) {
+				types = append(types, k.t)
+			}
+		}
+	}
+	return types
+}
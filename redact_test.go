@@ -0,0 +1,125 @@
+package godi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	type leaf struct {
+		X int
+	}
+
+	type outer struct {
+		*outer
+		Name string
+	}
+
+	type config struct {
+		Host     string `json:"host"`
+		Password string `json:"password"`
+		Internal string `json:"internal" redact:"true"`
+		Hidden   string `json:"-"`
+		Empty    string `json:"empty,omitempty"`
+		Timeout  time.Duration
+		Started  time.Time `json:"started"`
+		Extra    map[string]any
+	}
+
+	self := &node{Name: "a"}
+	self.Next = self
+
+	ring := &node{Name: "a", Next: &node{Name: "b"}}
+	ring.Next.Next = ring
+
+	selfMap := map[string]any{"a": 1}
+	selfMap["self"] = selfMap
+
+	selfSlice := []any{1, nil}
+	selfSlice[1] = selfSlice
+
+	embedded := &outer{Name: "x"}
+	embedded.outer = embedded
+
+	shared := &leaf{X: 1}
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"nil", nil, `null`},
+		{"self pointer", self, `{"Name":"a","Next":"[CYCLE]"}`},
+		{"ring", ring, `{"Name":"a","Next":{"Name":"b","Next":"[CYCLE]"}}`},
+		{"self map", selfMap, `{"a":1,"self":"[CYCLE]"}`},
+		{"self slice", selfSlice, `[1,"[CYCLE]"]`},
+		{"embedded self pointer", embedded, `{"Name":"x"}`},
+		{"shared pointer", map[string]*leaf{"a": shared, "b": shared}, `{"a":{"X":1},"b":{"X":1}}`},
+		{"shared pointer in slice", []*leaf{shared, shared}, `[{"X":1},{"X":1}]`},
+		{
+			"fields",
+			config{
+				Host:     "db",
+				Password: "hunter2",
+				Internal: "x",
+				Hidden:   "y",
+				Timeout:  time.Second,
+				Started:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Extra:    map[string]any{"apiKey": "k", "nested": map[string]any{"db_dsn": "d", "port": 5432}},
+			},
+			`{"Extra":{"apiKey":"[REDACTED]","nested":{"db_dsn":"[REDACTED]","port":5432}},"Timeout":1000000000,` +
+				`"host":"db","internal":"[REDACTED]","password":"[REDACTED]","started":"2024-01-02T03:04:05Z"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(Redact(tt.v))
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Redact = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"no secrets here", "no secrets here"},
+		{"password=hunter2 user=bob", "password=[REDACTED] user=bob"},
+		{`{"token": "abc", "id": 1}`, `{"token": [REDACTED], "id": 1}`},
+		{"db_password: 'x y'", "db_password: [REDACTED]"},
+		{"dial postgres://bob:hunter2@db:5432/app", "dial postgres://bob:[REDACTED]@db:5432/app"},
+		{"?api_key=k&page=2", "?api_key=[REDACTED]&page=2"},
+	}
+
+	for _, tt := range tests {
+		if got := RedactString(tt.in); got != tt.want {
+			t.Errorf("RedactString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	base := errors.New("connect: password=hunter2")
+	err := defaultRedactor.RedactError(base)
+
+	if err.Error() != "connect: password=[REDACTED]" {
+		t.Errorf("Error = %q", err.Error())
+	}
+	if !errors.Is(err, base) {
+		t.Error("the redacted error does not wrap the original one")
+	}
+}
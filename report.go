@@ -0,0 +1,121 @@
+package godi
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+// StartupReport describes how long the application took to initialize,
+// broken down by module and by constructor.
+type StartupReport struct {
+	// Total is the time spent creating the application.
+	Total time.Duration
+
+	// Modules lists the time each module spent registering its providers
+	// and controllers, excluding the modules it imports.
+	Modules []ModuleTiming
+
+	// Constructors lists the time each constructor took to run.
+	Constructors []ConstructorTiming
+}
+
+// ModuleTiming is the initialization time of a single module.
+type ModuleTiming struct {
+	Module   string
+	Duration time.Duration
+}
+
+// ConstructorTiming is the run time of a single constructor.
+type ConstructorTiming struct {
+	// Name is the constructor's function name in the format <package>.<function>,
+	// or its type for generated constructors such as those returned by Struct.
+	Name string
+
+	// Module is the token of the module the constructor was registered in.
+	Module string
+
+	// Duration is the time the constructor took to run.
+	Duration time.Duration
+
+	// Err is the error returned by the constructor, if any.
+	Err error
+}
+
+// String formats the report as a human readable summary, listing the slowest
+// modules and constructors first.
+func (r StartupReport) String() string {
+	var (
+		b     strings.Builder
+		mods  = slices.Clone(r.Modules)
+		ctors = slices.Clone(r.Constructors)
+	)
+
+	slices.SortStableFunc(mods, func(a, b ModuleTiming) int { return cmp.Compare(b.Duration, a.Duration) })
+	slices.SortStableFunc(ctors, func(a, b ConstructorTiming) int { return cmp.Compare(b.Duration, a.Duration) })
+
+	fmt.Fprintf(&b, "startup took %s (%d modules, %d constructors)\n", r.Total, len(mods), len(ctors))
+	for _, m := range mods {
+		fmt.Fprintf(&b, "  module %s: %s\n", m.Module, m.Duration)
+	}
+	for _, c := range ctors {
+		fmt.Fprintf(&b, "  constructor %s (%s): %s\n", c.Name, c.Module, c.Duration)
+	}
+
+	return b.String()
+}
+
+// StartupReport returns the time the application took to initialize each
+// module and constructor.
+func (a *App) StartupReport() StartupReport {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return StartupReport{
+		Total:        a.report.Total,
+		Modules:      slices.Clone(a.report.Modules),
+		Constructors: slices.Clone(a.report.Constructors),
+	}
+}
+
+func (a *App) recordModule(m *module, d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.report.Modules = append(a.report.Modules, ModuleTiming{
+		Module:   GetToken(m.Module),
+		Duration: d,
+	})
+}
+
+// constructorCallback returns a provide option that records the run time of
+// a constructor registered in the given module.
+func (a *App) constructorCallback(m *module, ctor constructor) dig.ProvideOption {
+	name := funcName(ctor)
+	return dig.WithProviderCallback(
+		func(ci dig.CallbackInfo) {
+			a.recordConstructor(m, ctor, name, ci.Runtime, ci.Error)
+		},
+	)
+}
+
+func (a *App) recordConstructor(m *module, ctor constructor, name string, d time.Duration, err error) {
+	a.debugf("built %s in %s (%s, err: %v)", name, m.path(), d, err)
+	if err == nil {
+		a.recordInstance(m, ctor, name, d)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.report.Constructors = append(a.report.Constructors, ConstructorTiming{
+		Name:     name,
+		Module:   GetToken(m.Module),
+		Duration: d,
+		Err:      a.opts.redactor.RedactError(err),
+	})
+}
@@ -0,0 +1,72 @@
+package godi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"sync"
+)
+
+// requestStateKey is the context key of the state of a request handled by a route.
+type requestStateKey struct{}
+
+// requestState holds the state attached to every request handled by a route: its
+// principal, correlation fields, route and error renderer. It is stored in a single
+// context value, and holds the values it points to, so it is allocated once per request.
+type requestState struct {
+	principal   *principalHolder
+	log         *requestLog
+	route       *RouteInfo
+	renderer    ErrorRenderer
+	translators []ValidationTranslator
+	codecs      []BodyCodec
+	proxies     []netip.Prefix
+	maxBodySize int64
+	guards      guardRequest
+	page        *PageRequest
+	query       *ListQuery
+
+	mu      sync.Mutex  // guards the values created by concurrent handler goroutines.
+	loaders map[any]any // the batchers of the data loaders used by the request.
+	events  []any       // the domain events recorded while handling the request.
+
+	principalValue principalHolder
+	logValue       requestLog
+}
+
+// requestStateFrom returns the state of the request handled with ctx, if any.
+func requestStateFrom(ctx context.Context) *requestState {
+	s, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return s
+}
+
+// withRequestState returns the request with the state of the route in its context.
+//
+// A request already handled by a route, e.g. by an application mounted in another
+// one, keeps its principal and correlation fields.
+func withRequestState(w http.ResponseWriter, r *http.Request, route *RouteInfo, renderer ErrorRenderer, logger *slog.Logger, ids IDGenerator) (*http.Request, *requestState) {
+	s := &requestState{
+		route:    route,
+		renderer: renderer,
+	}
+
+	if outer := requestStateFrom(r.Context()); outer != nil {
+		s.principal = outer.principal
+		s.log = outer.log
+	} else {
+		s.logValue = newRequestLog(w, r, logger, ids)
+		s.principal = &s.principalValue
+		s.log = &s.logValue
+
+		// requests over mutual TLS start with the peer's identity as principal
+		if peer, ok := PeerIdentityFrom(r); ok {
+			s.principalValue.value = peer
+		}
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), requestStateKey{}, s))
+	s.guards.r = r
+
+	return r, s
+}
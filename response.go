@@ -0,0 +1,246 @@
+package godi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ResponseMapper is an Interceptor that transforms the JSON body written by the
+// handlers it wraps, e.g. to convert internal models to DTOs or to strip fields
+// the requesting user is not allowed to see.
+//
+// The mapper receives the status code and decoded body of the response and returns
+// the body to encode in its place. Responses that are not JSON are written unchanged.
+type ResponseMapper func(ictx InterceptorContext, status int, body any) (any, error)
+
+// Intercept implements the Interceptor interface.
+func (fn ResponseMapper) Intercept(ictx InterceptorContext, next http.Handler) error {
+	var (
+		w   = ictx.Http.W
+		buf = &bufferedResponse{w: w}
+	)
+
+	ictx.Http.W = buf
+	next.ServeHTTP(buf, ictx.Http.R)
+
+	// streamed responses have already been sent as is
+	if buf.streaming {
+		return nil
+	}
+
+	status := buf.statusCode()
+	if !isJSON(w.Header().Get("Content-Type")) || buf.body.Len() == 0 {
+		w.WriteHeader(status)
+		_, err := w.Write(buf.body.Bytes())
+		return err
+	}
+
+	var body any
+	dec := json.NewDecoder(&buf.body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return err
+	}
+
+	mapped, err := fn(ictx, status, body)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(mapped)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Envelope returns an Interceptor that wraps successful JSON responses in an
+// envelope of the form {"data": ..., "meta": ...}.
+//
+// meta is called for every response to build its metadata and may be nil.
+// Responses with an error status code, and responses already written in an envelope
+// by Paginated, are written unchanged.
+func Envelope(meta func(InterceptorContext) any) Interceptor {
+	return ResponseMapper(
+		func(ictx InterceptorContext, status int, body any) (any, error) {
+			if status >= http.StatusBadRequest || isEnveloped(ictx.Http.W) {
+				return body, nil
+			}
+
+			env := envelope{Data: body}
+			if meta != nil {
+				env.Meta = meta(ictx)
+			}
+			return env, nil
+		},
+	)
+}
+
+// envelope is the response body written by the Envelope interceptor.
+type envelope struct {
+	Data any `json:"data"`
+	Meta any `json:"meta,omitempty"`
+}
+
+// bufferedResponse is an http.ResponseWriter that holds back the status code
+// and body written by a handler so they can be transformed before being sent.
+//
+// Flushing the response switches it to streaming, sending what has been written
+// so far and passing later writes through untransformed.
+type bufferedResponse struct {
+	w         http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	streaming bool
+	enveloped bool
+}
+
+// markEnveloped records that the response body is already an envelope,
+// along with the responses the buffered response writes to.
+func (b *bufferedResponse) markEnveloped() {
+	b.enveloped = true
+	if e, ok := b.w.(interface{ markEnveloped() }); ok {
+		e.markEnveloped()
+	}
+}
+
+// markEnveloped marks the first buffered response found by unwrapping w,
+// which may be wrapped by other interceptors, e.g. to record its status.
+func markEnveloped(w http.ResponseWriter) {
+	for w != nil {
+		if e, ok := w.(interface{ markEnveloped() }); ok {
+			e.markEnveloped()
+			return
+		}
+
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = u.Unwrap()
+	}
+}
+
+func isEnveloped(w http.ResponseWriter) bool {
+	b, ok := w.(*bufferedResponse)
+	return ok && b.enveloped
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.w.Header()
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.streaming {
+		b.w.WriteHeader(status)
+		return
+	}
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.w.Write(p)
+	}
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) FlushError() error {
+	if !b.streaming {
+		b.streaming = true
+		b.w.WriteHeader(b.statusCode())
+		if _, err := b.w.Write(b.body.Bytes()); err != nil {
+			return err
+		}
+		b.body.Reset()
+	}
+	return http.NewResponseController(b.w).Flush()
+}
+
+func (b *bufferedResponse) SetWriteDeadline(deadline time.Time) error {
+	return http.NewResponseController(b.w).SetWriteDeadline(deadline)
+}
+
+func (b *bufferedResponse) statusCode() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
+
+// isJSON reports whether the content type denotes a JSON document.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Redirect redirects the request to url with the status code, e.g. http.StatusSeeOther
+// after a form submission. Unlike http.Redirect, it writes no body.
+func Redirect(w http.ResponseWriter, url string, code int) {
+	w.Header().Set("Location", url)
+	w.WriteHeader(code)
+}
+
+// Created responds with 201 Created and the JSON encoded body, setting the Location
+// header to the URL of the created resource if it is not empty:
+//
+//	location, _ := c.app.URL("user", "id", user.ID)
+//	return godi.Created(w, location, user)
+func Created(w http.ResponseWriter, location string, body any) error {
+	if location != "" {
+		w.Header().Set("Location", location)
+	}
+	return writeJSON(w, http.StatusCreated, body)
+}
+
+// Paginated responds with a page of items in the envelope written by the Envelope
+// interceptor, with the cursor of the next page in its metadata, which is omitted on the last page:
+//
+//	{"data": [...], "meta": {"nextCursor": "b2Zmc2V0PTIw"}}
+//
+// The Envelope interceptor leaves the response unchanged, so the shape of paginated
+// responses does not depend on whether it wraps the route.
+func Paginated[T any](w http.ResponseWriter, items []T, cursor string) error {
+	return writePage(w, items, PageMeta{NextCursor: cursor})
+}
+
+// writePage writes the items and their metadata in an envelope,
+// marking the response so the Envelope interceptor leaves it unchanged.
+func writePage[T any](w http.ResponseWriter, items []T, meta PageMeta) error {
+	if items == nil {
+		items = []T{}
+	}
+
+	markEnveloped(w)
+	return writeJSON(w, http.StatusOK, envelope{Data: items, Meta: meta})
+}
+
+// writeJSON responds with the status code and the JSON encoded body,
+// writing nothing if the body cannot be encoded.
+func writeJSON(w http.ResponseWriter, status int, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -3,26 +3,45 @@ package godi
 import (
 	"fmt"
 	"net/http"
-
-	"go.uber.org/dig"
+	"strings"
 )
 
 // RouteConfig defines the configuration for a route.
 type RouteConfig struct {
-	Method   string       // The HTTP method (e.g., GET, POST) for the route.
+	Method   string       // The HTTP method (e.g., GET, POST) for the route. Every method is handled if empty and Methods is not set.
+	Methods  []string     // Optional HTTP methods handled by the route, sharing its handler, guards and metadata.
 	Pattern  string       // The URL pattern that the route will match.
+	Host     string       // Optional host the route is restricted to, overriding the controller's host.
 	Handler  http.Handler // The HTTP handler to process requests on this route.
 	Metadata any          // Optional metadata that can be associated with the route.
+	Name     string       // Optional unique name used to build the route's URL with App.URL.
+
+	Consumes []string // Media types accepted in request bodies, e.g. "application/json". Others are rejected with 415.
+	Produces []string // Media types the handler responds with. Requests not accepting any are rejected with 406.
 
 	Guards      []Guard            // Guards to enforce conditions before route handling.
 	GuardsCtors []GuardConstructor // Guard constructors for dynamic guard instantiation.
+
+	Interceptors      []Interceptor            // Interceptors wrapping the route handler.
+	InterceptorsCtors []InterceptorConstructor // Interceptor constructors for dynamic interceptor instantiation.
 }
 
 // route is a wrapper for managing route.
 type route struct {
 	*RouteConfig
-	guards     []*guard    // Registered guards for the route.
-	controller *controller // The controller that the route belongs to.
+	guards       []*guard       // Registered guards for the route.
+	interceptors []*interceptor // Registered interceptors for the route.
+	controller   *controller    // The controller that the route belongs to.
+
+	// the request pipeline of the route, built once when it is registered
+	chain          []*guard           // Controller and route guards, run in order.
+	handler        http.Handler       // The route handler wrapped by the interceptors.
+	info           *RouteInfo         // The route info attached to its requests.
+	guardCtx       GuardContext       // The guard context copied for each request.
+	interceptorCtx InterceptorContext // The interceptor context copied for each request.
+	exempt         bool               // Whether the route stays live in maintenance mode.
+	priority       int                // The priority of the route when shedding load.
+	maxBodySize    int64              // The maximum size of the bodies decoded by typed handlers.
 }
 
 func newRoute(rCfg *RouteConfig, ctrl *controller) (*route, error) {
@@ -31,49 +50,42 @@ func newRoute(rCfg *RouteConfig, ctrl *controller) (*route, error) {
 		RouteConfig: rCfg,
 	}
 
-	err := r._registerGuards()
+	err := rCfg.validate()
 	if err != nil {
-		return nil, err
+		return nil, wrapErrors(
+			err, "invalid config for route (%s) of controller (%T) in module (%T)",
+			strings.TrimSpace(strings.Join(rCfg.methods(), ",")+" "+rCfg.Pattern), ctrl.Controller, ctrl.module.Module,
+		)
 	}
 
-	return r, nil
-}
+	r.priority, err = routePriority(rCfg.Metadata, ctrl.Config().Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error registering route: %w", err)
+	}
 
-// _registerGuards registers all guards defined in the route configuration.
-func (r *route) _registerGuards() error {
-	var (
-		scp  = r.controller.module.scope
-		rCfg = r.RouteConfig
-		opts = []dig.ProvideOption{
-			dig.As(new(Guard)),
-			dig.Group(groupGuards.String()),
-		}
-	)
+	r.maxBodySize, err = routeMaxBodySize(ctrl.module.app.opts.maxBodySize, rCfg.Metadata, ctrl.Config().Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error registering route: %w", err)
+	}
 
-	for _, grd := range rCfg.Guards {
-		err := scp.Provide(func() Guard { return grd }, opts...)
-		if err != nil {
-			return fmt.Errorf("error providing route guard (%T): %w", grd, err)
-		}
+	r.guards, err = buildGuards(ctrl.module, rCfg.Guards, rCfg.GuardsCtors)
+	if err != nil {
+		return nil, fmt.Errorf("error registering route guards: %w", err)
 	}
 
-	for _, grdCtor := range rCfg.GuardsCtors {
-		err := scp.Provide(grdCtor, opts...)
-		if err != nil {
-			return fmt.Errorf("error providing route guard (%T): %w", grdCtor, err)
-		}
+	r.interceptors, err = buildInterceptors(ctrl.module, rCfg.Interceptors, rCfg.InterceptorsCtors)
+	if err != nil {
+		return nil, fmt.Errorf("error registering route interceptors: %w", err)
 	}
 
-	return scp.Invoke(
-		func(input guardGroupInput) error {
-			for _, grd := range input.Guards {
-				g, err := newGuard(grd)
-				if err != nil {
-					return err
-				}
-				r.guards = append(r.guards, g)
-			}
-			return nil
-		},
-	)
+	return r, nil
+}
+
+// methods returns the methods handled by the route,
+// or a single empty method if it handles every method.
+func (r *RouteConfig) methods() []string {
+	if len(r.Methods) > 0 {
+		return r.Methods
+	}
+	return []string{r.Method}
 }
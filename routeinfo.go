@@ -0,0 +1,59 @@
+package godi
+
+import "context"
+
+// RouteInfo describes the route matched by a request.
+type RouteInfo struct {
+	// Name is the name of the route, if any.
+	Name string
+
+	// Pattern is the path pattern of the route, including the controller's
+	// pattern, e.g. "/users/{id}".
+	Pattern string
+
+	// Host is the host the route is restricted to, if any, e.g. "{tenant}.example.com".
+	Host string
+
+	// Methods are the methods handled by the route. It is empty if the route
+	// handles every method.
+	Methods []string
+
+	// Metadata and ControllerMetadata are the metadata of the route and its controller.
+	Metadata           any
+	ControllerMetadata any
+}
+
+// newRouteInfo returns the RouteInfo of a route of the controller.
+func newRouteInfo(c *controller, r *route) *RouteInfo {
+	var methods []string
+	for _, method := range r.methods() {
+		if method != "" {
+			methods = append(methods, method)
+		}
+	}
+
+	return &RouteInfo{
+		Name:               r.Name,
+		Pattern:            c.getPath(*r),
+		Host:               c.getHost(*r),
+		Methods:            methods,
+		Metadata:           r.Metadata,
+		ControllerMetadata: c.Config().Metadata,
+	}
+}
+
+// RouteFrom returns the RouteInfo of the route matched by the request handled with ctx,
+// so generic guards, interceptors and handlers can make route-aware decisions:
+//
+//	if route, ok := godi.RouteFrom(r.Context()); ok {
+//		logger.Info("request handled", "route", route.Pattern)
+//	}
+//
+// It reports false if ctx does not belong to a route's request.
+func RouteFrom(ctx context.Context) (RouteInfo, bool) {
+	state := requestStateFrom(ctx)
+	if state == nil {
+		return RouteInfo{}, false
+	}
+	return *state.route, true
+}
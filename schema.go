@@ -0,0 +1,260 @@
+package godi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// schemaDialect is the JSON Schema dialect of the generated schemas.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+	// defNamePattern matches the characters not allowed in schema definition names,
+	// e.g. in the names of generic types.
+	defNamePattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// Schema is a JSON Schema describing the JSON encoding of a Go type.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// RouteSchema describes the request and response bodies of a route.
+type RouteSchema struct {
+	Name     string   `json:"name,omitempty"`
+	Method   string   `json:"method"`
+	Methods  []string `json:"methods,omitempty"`
+	Path     string   `json:"path"`
+	Request  *Schema  `json:"request,omitempty"`
+	Response *Schema  `json:"response,omitempty"`
+
+	// MediaTypes are the media types the route's bodies are served in: JSON, and those
+	// of the application's BodyCodecs.
+	MediaTypes []string `json:"mediaTypes,omitempty"`
+
+	// ValidationError describes the body of the 422 responses of routes validating
+	// their request bodies, as rendered by the JSONErrorRenderer.
+	ValidationError *Schema `json:"validationError,omitempty"`
+}
+
+// SchemaOf returns the JSON Schema of the JSON encoding of values of type t.
+//
+// Named struct types are described in the schema's definitions and referenced,
+// so recursive types are supported. Fields are required unless tagged omitempty or omitzero.
+func SchemaOf(t reflect.Type) *Schema {
+	g := &schemaGenerator{
+		defs:  map[string]*Schema{},
+		names: map[reflect.Type]string{},
+	}
+
+	s := g.schema(t)
+	s.Schema = schemaDialect
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+// Schemas returns the schemas of the request and response bodies of every route
+// whose handler is a TypedHandler, e.g. one created with HandleJSON, so contract
+// tests and client generators can consume them.
+func (a *App) Schemas() []RouteSchema {
+	return a.module.schemas()
+}
+
+func (a *App) handleSchemas(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(a.Schemas())
+}
+
+func (m *module) schemas() []RouteSchema {
+	schemas := []RouteSchema{}
+
+	for _, imported := range m.imports {
+		schemas = append(schemas, imported.schemas()...)
+	}
+
+	for _, c := range m.controllers {
+		for _, r := range c.routes {
+			h, ok := r.Handler.(TypedHandler)
+			if !ok {
+				continue
+			}
+
+			s := RouteSchema{
+				Name:       r.Name,
+				Method:     r.Method,
+				Methods:    r.Methods,
+				Path:       c.getPath(*r),
+				MediaTypes: mediaTypes(m.app.opts.bodyCodecs),
+			}
+			if t := h.RequestType(); t != nil {
+				s.Request = SchemaOf(t)
+				if validates(t) {
+					s.ValidationError = SchemaOf(reflect.TypeFor[ValidationErrorResponse]())
+				}
+			}
+			if t := h.ResponseType(); t != nil {
+				s.Response = SchemaOf(t)
+			}
+
+			schemas = append(schemas, s)
+		}
+	}
+
+	return schemas
+}
+
+// schemaGenerator builds a schema, collecting the definitions of named structs.
+type schemaGenerator struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case implementsMarshaler(t):
+		if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + g.define(t)}
+	}
+
+	// interfaces and other kinds accept any value
+	return &Schema{}
+}
+
+// define adds the definition of the named struct type, returning its name.
+func (g *schemaGenerator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := defNamePattern.ReplaceAllString(t.Name(), "_")
+	if _, taken := g.defs[name]; taken {
+		name = defNamePattern.ReplaceAllString(t.PkgPath()+"."+t.Name(), "_")
+	}
+
+	// register the name first, so recursive references resolve to it
+	g.names[t] = name
+	g.defs[name] = nil
+	g.defs[name] = g.structSchema(t)
+
+	return name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(t, s)
+	return s
+}
+
+// addFields adds the exported fields of the struct to the schema, keyed by their
+// JSON names and flattening embedded structs the way encoding/json does.
+func (g *schemaGenerator) addFields(t reflect.Type, s *Schema) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !implementsMarshaler(ft) {
+				g.addFields(ft, s)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fs := g.schema(field.Type)
+		if hasTagOption(opts, "string") && fs.Type != "" && fs.Type != "object" && fs.Type != "array" {
+			fs = &Schema{Type: "string"}
+		}
+
+		s.Properties[name] = fs
+		if !hasTagOption(opts, "omitempty") && !hasTagOption(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// hasTagOption reports whether the comma-separated struct tag options contain option.
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
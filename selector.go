@@ -0,0 +1,120 @@
+package godi
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+)
+
+// RouteSelector selects routes by their metadata, path and methods, e.g. to apply a
+// global interceptor only to the routes tagged "cacheable". A route must match every
+// criterion set, and the zero RouteSelector selects every route.
+type RouteSelector struct {
+	// Metadata lists the metadata keys the route must have, with their values. An empty value
+	// only requires the key. Keys are looked up in the route's metadata, then in its controller's,
+	// which must be a map[string]string or map[string]any.
+	Metadata map[string]string
+
+	// Path is a pattern the route's full path must match, with the syntax of path.Match,
+	// e.g. "/users/*". A trailing "/**" matches the prefix and every path below it.
+	Path string
+
+	// Methods lists the methods the route must handle at least one of. Routes handling
+	// every method match any method.
+	Methods []string
+}
+
+// Match reports whether the route described by info is selected.
+func (s RouteSelector) Match(info *RouteInfo) bool {
+	for key, want := range s.Metadata {
+		v, ok := MetadataValue(key, info.Metadata, info.ControllerMetadata)
+		if !ok || (want != "" && v != want) {
+			return false
+		}
+	}
+
+	if s.Path != "" && !matchPath(s.Path, info.Pattern) {
+		return false
+	}
+
+	if len(s.Methods) > 0 && len(info.Methods) > 0 {
+		return slices.ContainsFunc(s.Methods, func(method string) bool {
+			return slices.Contains(info.Methods, strings.ToUpper(method))
+		})
+	}
+	return true
+}
+
+// SelectiveInterceptor is an Interceptor wrapping only the routes selected by its
+// RouteSelector. Routes are selected when the application is created, so routes
+// that are not selected do not pay for the interceptor:
+//
+//	godi.WithInterceptors(
+//		godi.Select(godi.RouteSelector{Metadata: map[string]string{"cacheable": ""}}, cache),
+//		godi.Select(godi.RouteSelector{Metadata: map[string]string{"sensitive": "true"}}, audit),
+//	)
+type SelectiveInterceptor struct {
+	selector    RouteSelector
+	interceptor Interceptor
+}
+
+// Select returns a SelectiveInterceptor applying ic to the routes selected by sel.
+func Select(sel RouteSelector, ic Interceptor) *SelectiveInterceptor {
+	return &SelectiveInterceptor{selector: sel, interceptor: ic}
+}
+
+// Intercept implements the Interceptor interface.
+func (s *SelectiveInterceptor) Intercept(ictx InterceptorContext, next http.Handler) error {
+	return s.interceptor.Intercept(ictx, next)
+}
+
+// selectInterceptors returns the interceptors applying to the route described by info,
+// replacing selective interceptors by the interceptors they wrap.
+func selectInterceptors(interceptors []*interceptor, info *RouteInfo) []*interceptor {
+	var selected []*interceptor
+	for _, ic := range interceptors {
+		s, ok := ic.Interceptor.(*SelectiveInterceptor)
+		switch {
+		case !ok:
+			selected = append(selected, ic)
+		case s.selector.Match(info):
+			selected = append(selected, &interceptor{Interceptor: s.interceptor})
+		}
+	}
+	return selected
+}
+
+// matchPath reports whether the path matches the pattern of a RouteSelector.
+func matchPath(pattern, p string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	matched, _ := path.Match(pattern, p)
+	return matched
+}
+
+// MetadataValue returns the value of key in the first metadata that defines it, among
+// metadata of type map[string]string or map[string]any, formatting the values of the
+// latter with fmt.Sprint. Guards and interceptors read their settings with it, letting
+// routes override the metadata of their controller:
+//
+//	v, ok := godi.MetadataValue("region", ictx.RouteCfg.Metadata, ictx.ControllerCfg.Metadata)
+func MetadataValue(key string, metadata ...any) (string, bool) {
+	for _, md := range metadata {
+		switch md := md.(type) {
+		case map[string]string:
+			if v, ok := md[key]; ok {
+				return v, true
+			}
+		case map[string]any:
+			if v, ok := md[key]; ok {
+				return fmt.Sprint(v), true
+			}
+		}
+	}
+	return "", false
+}
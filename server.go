@@ -1,6 +1,7 @@
 package godi
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -9,6 +10,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -16,20 +20,47 @@ import (
 type HttpServer struct {
 	mux    *http.ServeMux
 	server *http.Server
+
+	mu              sync.Mutex
+	addr            net.Addr
+	hooks           map[ShutdownPhase][]ShutdownHook
+	startHooks      []StartHook
+	connStateHooks  []ConnStateHook
+	shutdownTimeout time.Duration
+	listenRetry     listenRetry
+
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	inflight     requestTracker
+	shuttingDown atomic.Bool
 }
 
-func newHttpServer(mux *http.ServeMux) *HttpServer {
-	return &HttpServer{
-		mux: mux,
-		server: &http.Server{
-			Handler: mux,
-		},
+func newHttpServer(mux *http.ServeMux, o *options) *HttpServer {
+	s := &HttpServer{
+		mux:             mux,
+		shutdownTimeout: cmp.Or(o.shutdownTimeout, defaultShutdownTimeout),
+		listenRetry:     o.listenRetry,
+		ready:           make(chan struct{}),
 	}
+
+	s.server = &http.Server{
+		Handler:     s.track(mux),
+		BaseContext: o.baseContext,
+		ConnContext: o.connContext,
+		ConnState:   s.connState,
+		IdleTimeout: o.idleTimeout,
+		TLSConfig:   o.tlsConfig,
+	}
+
+	return s
 }
 
 // Listen starts the HTTP server on the specified host and port, and listens
 // for incoming requests.
 //
+// A port of "0" binds an ephemeral port, which Addr reports once the server listens.
+//
 // Also listens for system signals like SIGINT and SIGTERM to enable graceful shutdown.
 func (s *HttpServer) Listen(host string, port string) error {
 	errChan := make(chan error, 1)
@@ -39,10 +70,32 @@ func (s *HttpServer) Listen(host string, port string) error {
 	// listen for signals to allow graceful shutdown
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 
+	ln, err := s.listen()
+	if err != nil {
+		return fmt.Errorf("error listening on (%s) : %w", s.server.Addr, err)
+	}
+
+	s.mu.Lock()
+	s.addr = ln.Addr()
+	s.server.Addr = ln.Addr().String()
+	s.mu.Unlock()
+
+	err = s.runStartHooks(context.Background(), ln.Addr())
+	if err != nil {
+		return errors.Join(err, ln.Close())
+	}
+
+	s.readyOnce.Do(func() { close(s.ready) })
+
 	go func() {
 		defer close(errChan)
 
-		err := s.server.ListenAndServe()
+		var err error
+		if s.server.TLSConfig != nil {
+			err = s.server.ServeTLS(ln, "", "")
+		} else {
+			err = s.server.Serve(ln)
+		}
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errChan <- err
 		}
@@ -59,9 +112,128 @@ func (s *HttpServer) Listen(host string, port string) error {
 	}
 }
 
+// Handler returns the application's fully wired handler, serving every registered
+// route along with the request tracking used for graceful shutdown.
+//
+// It allows mounting the application in an existing server, a serverless adapter
+// or a test harness without calling Listen:
+//
+//	srv := httptest.NewServer(app.Handler())
+func (s *HttpServer) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// Addr returns the address the server listens on, e.g. the ephemeral port bound
+// for port "0", or nil if the server is not listening yet.
+func (s *HttpServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// Ready returns a channel closed once the server's listener is bound and its start
+// hooks have run, so tests and orchestration code can wait for the server to accept
+// connections instead of sleeping:
+//
+//	go app.Listen("localhost", "0")
+//	<-app.Ready()
+//	resp, err := http.Get("http://" + app.Addr().String() + "/health")
+//
+// The channel is never closed if Listen fails before serving requests.
+func (s *HttpServer) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// ListenerFDEnv is the environment variable holding the file descriptor of a
+// listener inherited from a parent process, such as the dev runner, which keeps
+// the socket open across restarts so no connection is refused while reloading.
+const ListenerFDEnv = "GODI_LISTENER_FD"
+
+// listen returns the listener inherited through ListenerFDEnv if any,
+// or a new listener on the server's address.
+func (s *HttpServer) listen() (net.Listener, error) {
+	fd, ok := os.LookupEnv(ListenerFDEnv)
+	if !ok {
+		return s.listenRetry.listen(s.server.Addr)
+	}
+
+	n, err := strconv.Atoi(fd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s (%s): %w", ListenerFDEnv, fd, err)
+	}
+
+	file := os.NewFile(uintptr(n), "listener")
+	defer file.Close()
+
+	return net.FileListener(file)
+}
+
+// listenRetry configures how binding an address already in use is retried.
+type listenRetry struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// listen binds addr, retrying with an exponential backoff while it is in use,
+// e.g. by the previous process of a restarting application.
+func (l listenRetry) listen(addr string) (net.Listener, error) {
+	backoff := l.backoff
+	for attempt := 0; ; attempt++ {
+		ln, err := net.Listen("tcp", addr)
+		if err == nil || !errors.Is(err, syscall.EADDRINUSE) || attempt >= l.attempts {
+			return ln, err
+		}
+
+		log.Printf("address (%s) in use, retrying in %s\n", addr, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// WithListenRetry makes Listen retry binding an address already in use up to
+// attempts times, waiting backoff before the first retry and doubling it after
+// each one. By default, Listen fails as soon as the address is in use.
+func WithListenRetry(attempts int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.listenRetry = listenRetry{attempts: attempts, backoff: backoff}
+	}
+}
+
+// WithBaseContext sets the function returning the base context of the requests
+// received on the server's listener, e.g. to carry global values such as the region
+// or instance ID, or to tie requests to an application-level context.
+// Defaults to context.Background().
+func WithBaseContext(fn func(ln net.Listener) context.Context) Option {
+	return func(o *options) {
+		o.baseContext = fn
+	}
+}
+
+// WithConnContext sets the function deriving the context of each new connection
+// from the base context, e.g. to attach the connection's TLS state or peer address.
+func WithConnContext(fn func(ctx context.Context, c net.Conn) context.Context) Option {
+	return func(o *options) {
+		o.connContext = fn
+	}
+}
+
 // Shutdown gracefully shuts down the HTTP server.
+//
+// The pre-drain hooks run first, then the listener is closed and in-flight requests
+// are drained, followed by the post-drain and final hooks. Each phase is bounded by
+// the shutdown timeout, and the remaining phases run even if an earlier one fails.
 func (s *HttpServer) Shutdown(c context.Context) error {
-	ctx, cancel := context.WithTimeout(c, (time.Second * 5))
+	s.shuttingDown.Store(true)
+	errs := []error{s.runHooks(c, PreDrain)}
+
+	ctx, cancel := context.WithTimeout(c, s.shutdownTimeout)
 	defer cancel()
-	return s.server.Shutdown(ctx)
+
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("error draining requests: %w", err))
+	}
+
+	errs = append(errs, s.runHooks(c, PostDrain), s.runHooks(c, Final))
+	return errors.Join(errs...)
 }
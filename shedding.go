@@ -0,0 +1,241 @@
+package godi
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PriorityMetadataKey is the metadata key setting the priority of a route, or of every
+// route of a controller, as an integer, e.g. map[string]string{"priority": "10"}.
+// Routes without it have priority 0, and routes of the lowest priorities are shed first.
+const PriorityMetadataKey = "priority"
+
+// maxLatencySamples bounds the number of route latencies sampled per interval.
+const maxLatencySamples = 4096
+
+// ErrOverloaded is rendered with 503 for the requests shed while the application is overloaded.
+var ErrOverloaded = errors.New("service overloaded")
+
+// LoadShedding configures the load shedding enabled with WithLoadShedding.
+// Thresholds that are zero are not checked.
+type LoadShedding struct {
+	// MaxCPU is the highest share of the CPU time available to the process, as defined
+	// by GOMAXPROCS, it may use, from 0 to 1, e.g. 0.9. It is only measured on Unix systems.
+	MaxCPU float64
+
+	// MaxGoroutines is the highest number of goroutines the process may run.
+	MaxGoroutines int
+
+	// MaxLatency is the highest 99th percentile of the time routes take to respond.
+	MaxLatency time.Duration
+
+	// Interval is how often the signals are sampled and the shedding adjusted.
+	// Defaults to a second.
+	Interval time.Duration
+
+	// RetryAfter is the delay shed requests are advised to retry after.
+	// The Retry-After header is omitted if it is zero.
+	RetryAfter time.Duration
+}
+
+// WithLoadShedding protects the application from overload by responding to the requests of
+// its lowest-priority routes with 503 and ErrOverloaded, before their guards run, while its
+// CPU usage, goroutine count or route latency exceed the thresholds of cfg.
+//
+// Shedding is adaptive: every interval the signals exceed a threshold, the routes of the next
+// lowest priority are shed, and every interval they are all below, the routes of the highest
+// priority shed are restored. Routes of the highest priority are never shed, so routes are
+// given priorities with their PriorityMetadataKey metadata, e.g. core endpoints and probes
+// a higher one than reports and exports.
+func WithLoadShedding(cfg LoadShedding) Option {
+	return func(o *options) {
+		o.loadShedding = &cfg
+	}
+}
+
+// LoadShedder sheds the requests of low-priority routes while the application is overloaded.
+// It is returned by App.LoadShedder when load shedding is enabled.
+type LoadShedder struct {
+	cfg    LoadShedding
+	logger *slog.Logger
+
+	next        atomic.Int64 // when the signals are next sampled, in Unix nanoseconds.
+	minPriority atomic.Int64 // the priority routes must have to be served.
+
+	mu         sync.Mutex
+	priorities []int // the distinct priorities of the routes, in increasing order.
+	shed       int   // the number of the lowest priorities shed.
+	latencies  []time.Duration
+	cpuTime    time.Duration // the CPU time used by the process when last sampled.
+	sampledAt  time.Time
+	status     LoadStatus
+}
+
+// LoadStatus is the state of the load shedder as of its last sample.
+type LoadStatus struct {
+	CPU        float64       // the share of the available CPU time used, from 0 to 1.
+	Goroutines int           // the number of goroutines.
+	Latency    time.Duration // the 99th percentile of route latencies.
+	Overloaded bool          // whether a signal exceeded its threshold.
+
+	// Shedding reports whether routes are shed, which are those of a lower priority than MinPriority.
+	Shedding    bool
+	MinPriority int
+
+	SampledAt time.Time
+}
+
+func newLoadShedder(cfg LoadShedding, logger *slog.Logger) *LoadShedder {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &LoadShedder{cfg: cfg, logger: logger}
+	s.minPriority.Store(math.MinInt64)
+	return s
+}
+
+// LoadShedder returns the application's load shedder, or nil if load shedding
+// is not enabled with WithLoadShedding.
+func (a *App) LoadShedder() *LoadShedder {
+	return a.shedder
+}
+
+// Status returns the state of the load shedder as of its last sample.
+func (s *LoadShedder) Status() LoadStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
+// addPriority registers the priority of a route.
+func (s *LoadShedder) addPriority(priority int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i, found := slices.BinarySearch(s.priorities, priority); !found {
+		s.priorities = slices.Insert(s.priorities, i, priority)
+	}
+}
+
+// reject responds to the request with 503 if the route's priority is shed,
+// reporting whether it did. The signals are sampled once the interval elapsed.
+func (s *LoadShedder) reject(w http.ResponseWriter, r *http.Request, priority int, renderer ErrorRenderer) bool {
+	now := time.Now()
+	if next := s.next.Load(); now.UnixNano() >= next && s.next.CompareAndSwap(next, now.Add(s.cfg.Interval).UnixNano()) {
+		s.sample(now)
+	}
+
+	if int64(priority) >= s.minPriority.Load() {
+		return false
+	}
+
+	if s.cfg.RetryAfter > 0 {
+		secs := int64(math.Ceil(s.cfg.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+	}
+
+	renderer.RenderError(w, r, http.StatusServiceUnavailable, ErrOverloaded)
+	return true
+}
+
+// observe records the latency of a request served since start.
+func (s *LoadShedder) observe(start time.Time) {
+	d := time.Since(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.latencies) < maxLatencySamples {
+		s.latencies = append(s.latencies, d)
+	}
+}
+
+// sample samples the signals, shedding the routes of the next lowest priority if any
+// exceeds its threshold, or restoring those of the highest priority shed otherwise.
+func (s *LoadShedder) sample(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := LoadStatus{
+		Goroutines: runtime.NumGoroutine(),
+		Latency:    percentile(s.latencies, 0.99),
+		SampledAt:  now,
+	}
+	s.latencies = s.latencies[:0]
+
+	if cpuTime, ok := processCPUTime(); ok {
+		if elapsed := now.Sub(s.sampledAt); !s.sampledAt.IsZero() && elapsed > 0 {
+			status.CPU = float64(cpuTime-s.cpuTime) / (float64(elapsed) * float64(runtime.GOMAXPROCS(0)))
+		}
+		s.cpuTime = cpuTime
+	}
+	s.sampledAt = now
+
+	status.Overloaded = (s.cfg.MaxCPU > 0 && status.CPU > s.cfg.MaxCPU) ||
+		(s.cfg.MaxGoroutines > 0 && status.Goroutines > s.cfg.MaxGoroutines) ||
+		(s.cfg.MaxLatency > 0 && status.Latency > s.cfg.MaxLatency)
+
+	shed := s.shed
+	switch {
+	case status.Overloaded:
+		shed = min(shed+1, max(len(s.priorities)-1, 0))
+	case shed > 0:
+		shed--
+	}
+
+	minPriority := int64(math.MinInt64)
+	if shed > 0 {
+		minPriority = int64(s.priorities[shed])
+		status.Shedding = true
+		status.MinPriority = s.priorities[shed]
+	}
+	s.minPriority.Store(minPriority)
+
+	if shed != s.shed {
+		s.logger.Warn("load shedding adjusted",
+			"shedding", status.Shedding, "minPriority", status.MinPriority,
+			"cpu", status.CPU, "goroutines", status.Goroutines, "latency", status.Latency,
+		)
+	}
+	s.shed = shed
+	s.status = status
+}
+
+// percentile returns the p-th percentile of the durations, reordering them,
+// or zero if there are none.
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	slices.Sort(durations)
+	return durations[int(math.Ceil(p*float64(len(durations))))-1]
+}
+
+// routePriority returns the priority set by the PriorityMetadataKey metadata,
+// which take precedence in order.
+func routePriority(metadata ...any) (int, error) {
+	v, ok := MetadataValue(PriorityMetadataKey, metadata...)
+	if !ok {
+		return 0, nil
+	}
+
+	priority, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s metadata (%s): %w", PriorityMetadataKey, v, err)
+	}
+	return priority, nil
+}
@@ -0,0 +1,93 @@
+package godi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ShutdownPhase is a stage of the server's graceful shutdown.
+type ShutdownPhase int
+
+const (
+	// PreDrain hooks run before the listener closes, while requests are still served.
+	// E.g. deregistering from service discovery or failing readiness checks.
+	PreDrain ShutdownPhase = iota
+
+	// PostDrain hooks run after in-flight requests have finished.
+	// E.g. flushing buffered logs, metrics or queued messages.
+	PostDrain
+
+	// Final hooks run last. E.g. closing database connections.
+	Final
+)
+
+func (p ShutdownPhase) String() string {
+	switch p {
+	case PreDrain:
+		return "pre-drain"
+	case PostDrain:
+		return "post-drain"
+	case Final:
+		return "final"
+	default:
+		return fmt.Sprintf("ShutdownPhase(%d)", int(p))
+	}
+}
+
+// defaultShutdownTimeout bounds each phase of the shutdown when no timeout is configured.
+const defaultShutdownTimeout = 5 * time.Second
+
+// ShutdownHook is a function run during a phase of the server's graceful shutdown.
+// The context is canceled when the phase's timeout elapses.
+type ShutdownHook func(ctx context.Context) error
+
+// OnShutdown registers a hook to run during the given phase of the server's graceful shutdown.
+//
+// Hooks of a phase run in the reverse order they were registered, so resources are
+// released in the opposite order they were acquired. The server can be injected into
+// constructors to register hooks for the resources they create.
+func (s *HttpServer) OnShutdown(hook ShutdownHook, phase ShutdownPhase) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.hooks == nil {
+		s.hooks = map[ShutdownPhase][]ShutdownHook{}
+	}
+	s.hooks[phase] = append(s.hooks[phase], hook)
+}
+
+// runHooks runs the hooks of a shutdown phase, bounded by the shutdown timeout.
+func (s *HttpServer) runHooks(c context.Context, phase ShutdownPhase) error {
+	s.mu.Lock()
+	hooks := slices.Clone(s.hooks[phase])
+	s.mu.Unlock()
+
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, hook := range slices.Backward(hooks) {
+		err := hook(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error running %s shutdown hook: %w", phase, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithShutdownTimeout sets how long each phase of the graceful shutdown may take:
+// the pre-drain hooks, draining in-flight requests, the post-drain hooks and the final hooks.
+// Defaults to 5 seconds.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = d
+	}
+}
@@ -0,0 +1,108 @@
+package godi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// StreamWriter writes a chunked response, flushing every write to the client so
+// long-running endpoints, like exports, deliver data as it is produced.
+//
+// Writes fail with the request context's error once the client disconnects,
+// so streaming loops stop instead of producing data nobody reads.
+type StreamWriter struct {
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	ctx context.Context
+}
+
+// NewStreamWriter returns a StreamWriter for the response to the request.
+//
+// The server's write deadline is lifted for the response, as a stream may
+// take longer to complete than a regular response.
+func NewStreamWriter(w http.ResponseWriter, r *http.Request) *StreamWriter {
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	return &StreamWriter{
+		w:   w,
+		rc:  rc,
+		ctx: r.Context(),
+	}
+}
+
+// Header returns the response headers, which must be set before the first write.
+func (s *StreamWriter) Header() http.Header {
+	return s.w.Header()
+}
+
+// Write writes p to the client and flushes it.
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	if err := s.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := s.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, s.Flush()
+}
+
+// Flush sends any buffered data to the client. Response writers that cannot
+// flush are tolerated; their data is sent when the handler returns.
+func (s *StreamWriter) Flush() error {
+	err := s.rc.Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// Done returns a channel that is closed when the client disconnects
+// or the request is otherwise canceled.
+func (s *StreamWriter) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Err returns the reason the stream was canceled, or nil while the client is connected.
+func (s *StreamWriter) Err() error {
+	return s.ctx.Err()
+}
+
+// NDJSONWriter streams values as newline delimited JSON.
+//
+//	func (c *ExportController) handleExport(w http.ResponseWriter, r *http.Request) {
+//		stream := godi.NewNDJSONWriter(w, r)
+//		for row := range c.store.Rows(r.Context()) {
+//			if err := stream.Encode(row); err != nil {
+//				return
+//			}
+//		}
+//	}
+type NDJSONWriter struct {
+	*StreamWriter
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns an NDJSONWriter for the response to the request,
+// setting the response's content type to "application/x-ndjson" if unset.
+func NewNDJSONWriter(w http.ResponseWriter, r *http.Request) *NDJSONWriter {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+
+	s := NewStreamWriter(w, r)
+	return &NDJSONWriter{
+		StreamWriter: s,
+		enc:          json.NewEncoder(s),
+	}
+}
+
+// Encode writes the JSON encoding of v followed by a newline and flushes it.
+func (n *NDJSONWriter) Encode(v any) error {
+	return n.enc.Encode(v)
+}
@@ -0,0 +1,163 @@
+package godi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutMetadataKey is the metadata key overriding the timeout of a route, or of every
+// route of a controller, as a duration, e.g. map[string]string{"timeout": "30s"}.
+// A zero duration disables the timeout, e.g. for streaming routes.
+const TimeoutMetadataKey = "timeout"
+
+// ErrHandlerTimeout is rendered with 504 when a route handler does not respond within its timeout.
+var ErrHandlerTimeout = errors.New("handler timed out")
+
+// WithRequestTimeout bounds the time every route may take to respond by wrapping every
+// route with Timeout(d), outside of any other interceptor. Routes override it with their
+// TimeoutMetadataKey metadata. By default, requests are not bounded.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.requestTimeout = d
+	}
+}
+
+// TimeoutEnforcer is an Interceptor bounding the time the handlers it wraps take to respond.
+// The request's context is canceled once the timeout expires, and the request is responded to
+// with 504 and ErrHandlerTimeout through the route's ErrorRenderer, discarding what the handler
+// writes afterwards.
+//
+// The response is held back until the handler returns, so routes streaming their response
+// should disable the timeout with their TimeoutMetadataKey metadata.
+type TimeoutEnforcer struct {
+	timeout time.Duration
+}
+
+// Timeout returns a TimeoutEnforcer bounding handlers by d, unless overridden by the
+// TimeoutMetadataKey metadata of their route or controller.
+func Timeout(d time.Duration) *TimeoutEnforcer {
+	return &TimeoutEnforcer{timeout: d}
+}
+
+// Intercept implements the Interceptor interface.
+func (t *TimeoutEnforcer) Intercept(ictx InterceptorContext, next http.Handler) error {
+	var (
+		w = ictx.Http.W
+		r = ictx.Http.R
+	)
+
+	timeout := t.timeout
+	if v, ok := MetadataValue(TimeoutMetadataKey, ictx.RouteCfg.Metadata, ictx.ControllerCfg.Metadata); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s metadata (%s): %w", TimeoutMetadataKey, v, err)
+		}
+		timeout = d
+	}
+
+	if timeout <= 0 {
+		next.ServeHTTP(w, r)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	var (
+		tw       = &timeoutWriter{w: w, header: w.Header().Clone()}
+		done     = make(chan struct{})
+		panicked = make(chan any, 1)
+	)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		// re-raise the handler's panic on the request's goroutine
+		panic(p)
+	case <-done:
+		tw.flush()
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+
+		tw.timedOut = true
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			RenderError(w, r, http.StatusGatewayTimeout, ErrHandlerTimeout)
+		}
+	}
+	return nil
+}
+
+// timeoutWriter holds back the response of a handler until it returns in time,
+// discarding it once the timeout expired.
+type timeoutWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+
+	mu       sync.Mutex
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (t *timeoutWriter) Header() http.Header {
+	return t.header
+}
+
+func (t *timeoutWriter) WriteHeader(status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.timedOut && t.status == 0 {
+		t.status = status
+	}
+}
+
+func (t *timeoutWriter) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.timedOut {
+		return 0, ErrHandlerTimeout
+	}
+	if t.status == 0 {
+		t.status = http.StatusOK
+	}
+	return t.body.Write(p)
+}
+
+// flush sends the response written by the handler.
+func (t *timeoutWriter) flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	dst := t.w.Header()
+	maps.Copy(dst, t.header)
+	for key := range dst {
+		if _, ok := t.header[key]; !ok {
+			dst.Del(key)
+		}
+	}
+
+	if t.status != 0 {
+		t.w.WriteHeader(t.status)
+	}
+	if t.body.Len() > 0 {
+		t.w.Write(t.body.Bytes())
+	}
+}
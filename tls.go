@@ -0,0 +1,100 @@
+package godi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"path"
+	"slices"
+)
+
+// WithTLS serves requests over TLS with the config, which holds the server's certificates.
+// Requiring client certificates, e.g. in a zero-trust mesh, attaches the identity of the
+// peer service to requests:
+//
+//	godi.WithTLS(&tls.Config{
+//		Certificates: []tls.Certificate{cert},
+//		ClientCAs:    meshCAs,
+//		ClientAuth:   tls.RequireAndVerifyClientCert,
+//	})
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// PeerIdentity is the identity of the peer of a mutual TLS connection, taken from its
+// verified client certificate. It is the initial principal of the requests received over
+// the connection, until a guard attaches another one:
+//
+//	peer, ok := godi.PrincipalFrom[*godi.PeerIdentity](r.Context())
+type PeerIdentity struct {
+	// SPIFFEID is the SPIFFE ID of the peer, e.g. "spiffe://example.org/ns/prod/sa/billing",
+	// or empty if the certificate has no spiffe URI SAN.
+	SPIFFEID string
+
+	// CommonName is the subject common name of the certificate.
+	CommonName string
+
+	// DNSNames are the DNS SANs of the certificate.
+	DNSNames []string
+
+	// Certificate is the verified leaf certificate of the peer.
+	Certificate *x509.Certificate
+}
+
+// ID returns the SPIFFE ID of the peer, or its common name if it has none.
+func (p *PeerIdentity) ID() string {
+	if p.SPIFFEID != "" {
+		return p.SPIFFEID
+	}
+	return p.CommonName
+}
+
+// PeerIdentityFrom returns the identity of the peer of the request's connection, reporting
+// false unless the request was received over TLS with a verified client certificate.
+func PeerIdentityFrom(r *http.Request) (*PeerIdentity, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+	peer := &PeerIdentity{
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		Certificate: cert,
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			peer.SPIFFEID = uri.String()
+			break
+		}
+	}
+	return peer, true
+}
+
+// PeerGuard is a Guard allowing the requests of the peer services it lists, identified
+// by the SPIFFE ID or common name of their client certificate. Requests received without
+// a verified client certificate are rejected.
+type PeerGuard struct {
+	patterns []string
+}
+
+// AllowPeers returns a PeerGuard allowing the peers whose ID matches one of the patterns,
+// with the syntax of path.Match, e.g. "spiffe://example.org/ns/prod/sa/*".
+func AllowPeers(patterns ...string) *PeerGuard {
+	return &PeerGuard{patterns: patterns}
+}
+
+// Allow implements the Guard interface.
+func (g *PeerGuard) Allow(gctx GuardContext) (bool, error) {
+	peer, ok := PeerIdentityFrom(gctx.Http.R)
+	if !ok {
+		return false, nil
+	}
+
+	return slices.ContainsFunc(g.patterns, func(pattern string) bool {
+		matched, _ := path.Match(pattern, peer.ID())
+		return matched
+	}), nil
+}
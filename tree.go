@@ -0,0 +1,165 @@
+package godi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// ModuleNode is a serializable description of a module, its imports, exports,
+// providers and controllers.
+type ModuleNode struct {
+	Module      string           `json:"module"`
+	IsGlobal    bool             `json:"isGlobal"`
+	Imports     []ModuleNode     `json:"imports"`
+	Exports     []string         `json:"exports"`
+	Providers   []string         `json:"providers"`
+	Controllers []ControllerNode `json:"controllers"`
+}
+
+// ControllerNode is a serializable description of a controller and its routes.
+type ControllerNode struct {
+	Controller string      `json:"controller"`
+	Host       string      `json:"host,omitempty"`
+	Pattern    string      `json:"pattern"`
+	Routes     []RouteNode `json:"routes"`
+}
+
+// RouteNode is a serializable description of a route.
+type RouteNode struct {
+	Name     string   `json:"name,omitempty"`
+	Method   string   `json:"method"`
+	Methods  []string `json:"methods,omitempty"`
+	Host     string   `json:"host,omitempty"`
+	Pattern  string   `json:"pattern"`
+	Path     string   `json:"path"`
+	Consumes []string `json:"consumes,omitempty"`
+	Produces []string `json:"produces,omitempty"`
+	Guards   []string `json:"guards,omitempty"`
+	Metadata string   `json:"metadata,omitempty"`
+}
+
+// ModuleTree returns the application's module tree, starting at the root module.
+func (a *App) ModuleTree() ModuleNode {
+	return a.module.node()
+}
+
+// defaultIntrospectionPath is the path the module tree is served on by WithIntrospection.
+const defaultIntrospectionPath = "/debug/godi"
+
+// WithIntrospection mounts an endpoint at "/debug/godi" that renders the module
+// tree as JSON, for architecture visibility in running services, one at
+// "/debug/godi/schemas" that renders the route schemas returned by App.Schemas,
+// and one at "/debug/godi/errors" that renders the error codes returned by App.ErrorCodes.
+func WithIntrospection() Option {
+	return func(o *options) {
+		o.introspection = true
+	}
+}
+
+func (a *App) handleModuleTree(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(a.ModuleTree())
+}
+
+func (m *module) node() ModuleNode {
+	mCfg := m.Config()
+	node := ModuleNode{
+		Module:      GetToken(m.Module),
+		IsGlobal:    mCfg.IsGlobal,
+		Imports:     []ModuleNode{},
+		Exports:     []string{},
+		Providers:   []string{},
+		Controllers: []ControllerNode{},
+	}
+
+	for _, imported := range m.imports {
+		node.Imports = append(node.Imports, imported.node())
+	}
+	for _, export := range mCfg.Exports {
+		node.Exports = append(node.Exports, GetToken(export))
+	}
+	for _, exportCtor := range mCfg.ExportsCtors {
+		node.Exports = append(node.Exports, funcName(exportCtor))
+	}
+	for _, pvd := range mCfg.Providers {
+		node.Providers = append(node.Providers, GetToken(pvd))
+	}
+	for _, pvdCtor := range mCfg.ProvidersCtors {
+		node.Providers = append(node.Providers, funcName(pvdCtor))
+	}
+	for _, ctrl := range m.controllers {
+		node.Controllers = append(node.Controllers, ctrl.node())
+	}
+
+	return node
+}
+
+func (c *controller) node() ControllerNode {
+	node := ControllerNode{
+		Controller: GetToken(c.Controller),
+		Host:       c.Config().Host,
+		Pattern:    c.Config().Pattern,
+		Routes:     []RouteNode{},
+	}
+
+	for _, r := range c.routes {
+		node.Routes = append(node.Routes, RouteNode{
+			Name:     r.Name,
+			Method:   r.Method,
+			Methods:  r.Methods,
+			Host:     c.getHost(*r),
+			Pattern:  r.Pattern,
+			Path:     c.getPath(*r),
+			Consumes: r.Consumes,
+			Produces: r.Produces,
+			Guards:   guardNames(r.chain),
+			Metadata: formatMetadata(r.Metadata),
+		})
+	}
+
+	return node
+}
+
+// funcName returns the name of a constructor function in the format <package>.<function>,
+// falling back to its type for values that are not functions.
+//
+// Functions created with reflect.MakeFunc, and closures of generic functions,
+// share the same name regardless of what they construct, so they are
+// identified by their type instead.
+func funcName(fn any) string {
+	if a, ok := fn.(annotated); ok {
+		return funcName(a.ctor)
+	}
+
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return GetToken(fn)
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f != nil && f.Name() != "reflect.makeFuncStub" && !strings.Contains(f.Name(), "[...]") {
+		return f.Name()
+	}
+	return GetToken(fn)
+}
+
+func guardNames(guards []*guard) []string {
+	var names []string
+	for _, g := range guards {
+		names = append(names, GetToken(g.Guard))
+	}
+	return names
+}
+
+// formatMetadata formats route metadata, which may not be serializable.
+func formatMetadata(metadata any) string {
+	if metadata == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+v", metadata)
+}
@@ -0,0 +1,104 @@
+package godi
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provide returns a ProviderConstructor that provides the constructor's result as T.
+//
+// The constructor must produce exactly T, or a type implementing T when T is an interface,
+// in which case the result is provided both as its own type and as T. Naming T at the
+// registration site documents what is provided and is checked when the app is created.
+//
+// Example:
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Provide[*UserService](NewUserService)}
+func Provide[T any](ctor ProviderConstructor) ProviderConstructor {
+	var (
+		a = annotate(ctor)
+		t = reflect.TypeFor[T]()
+	)
+
+	result, err := a.result()
+	switch {
+	case err != nil:
+		a.err = err
+	case result == t:
+	case t.Kind() == reflect.Interface && result.Implements(t):
+		a.as = append(a.as, t)
+	default:
+		a.err = fmt.Errorf("%s produces %v, not %v", funcName(a.ctor), result, t)
+	}
+
+	return a
+}
+
+// Supply returns a ProviderConstructor that provides the value as T,
+// for dependencies that do not require a constructor.
+//
+// Example:
+//
+//	ProvidersCtors: []godi.ProviderConstructor{godi.Supply(&Config{Port: "5000"})}
+func Supply[T any](value T) ProviderConstructor {
+	return func() T { return value }
+}
+
+// Key identifies a named value of type T in the container.
+type Key[T any] struct {
+	name string
+}
+
+// Named returns the key of the value of type T with the given name,
+// allowing several values of the same type to be provided side by side.
+//
+// Example:
+//
+//	var PrimaryDB = godi.Named[*sql.DB]("primary")
+//
+//	ProvidersCtors: []godi.ProviderConstructor{PrimaryDB.Provide(NewPrimaryDB)}
+//
+//	type Repository struct {
+//		DB *sql.DB `godi:"inject,name=primary"`
+//	}
+func Named[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name returns the name of the key.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Provide returns a ProviderConstructor that provides the constructor's result as T under the key's name.
+func (k Key[T]) Provide(ctor ProviderConstructor) ProviderConstructor {
+	a := annotate(Provide[T](ctor))
+	a.name = k.name
+	return a
+}
+
+// Supply returns a ProviderConstructor that provides the value as T under the key's name.
+func (k Key[T]) Supply(value T) ProviderConstructor {
+	a := annotate(Supply(value))
+	a.name = k.name
+	return a
+}
+
+// Resolve retrieves the value identified by the key from the app's root module,
+// building it and its dependencies if they haven't been built already.
+func (k Key[T]) Resolve(a *App) (T, error) {
+	v, err := a.module.resolve(providerKey{t: reflect.TypeFor[T](), name: k.name})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	t, _ := v.Interface().(T)
+	return t, nil
+}
+
+// Resolve retrieves the value of type T from the app's root module,
+// building it and its dependencies if they haven't been built already.
+func Resolve[T any](a *App) (T, error) {
+	return Key[T]{}.Resolve(a)
+}
@@ -1,8 +1,24 @@
 package godi
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // GetToken generates a unique token for the given value based on its type.
 func GetToken(v any) string {
 	return fmt.Sprintf("%T", v)
 }
+
+// wrapErrors wraps each of the errors joined in err with the formatted message,
+// so every error reported for the module tree keeps its context.
+func wrapErrors(err error, format string, args ...any) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, wrapErrors(e, format, args...))
+		}
+		return errors.Join(errs...)
+	}
+	return fmt.Errorf(format+": %w", append(args, err)...)
+}
@@ -0,0 +1,230 @@
+package godi
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Validator is implemented by modules checking their parameters before they are wired,
+// e.g. the required options of modules configured through their fields:
+//
+//	func (m *Module) Validate() error {
+//		if m.DSN == "" {
+//			return errors.New("DSN is required")
+//		}
+//		return nil
+//	}
+//
+// Validate is called when the application is created, before any module is wired,
+// so invalid parameters are reported as configuration errors rather than causing
+// nil pointer panics in constructors later on.
+type Validator interface {
+	Validate() error
+}
+
+// validateModules calls the Validate method of the modules of the tree active in the
+// application's profile, reporting the errors of every module. The imports of invalid
+// modules are not validated, as their config may depend on the invalid parameters.
+func (a *App) validateModules(m Module) error {
+	if v, ok := m.(Validator); ok {
+		err := v.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid module (%T): %w", m, err)
+		}
+	}
+
+	mCfg := m.Config()
+	if mCfg == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, imported := range mCfg.Imports {
+		if imported == nil || (imported.Config() != nil && !a.opts.profileActive(imported.Config().Profiles)) {
+			continue
+		}
+		errs = append(errs, a.validateModules(imported))
+	}
+	return errors.Join(errs...)
+}
+
+// validate reports every problem found in the module config.
+func (cfg *ModuleConfig) validate() error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+
+	var errs []error
+	for i, imported := range cfg.Imports {
+		if imported == nil {
+			errs = append(errs, fmt.Errorf("import at index %d is nil", i))
+		}
+	}
+	for i, optional := range cfg.ImportsOptional {
+		if optional == nil {
+			errs = append(errs, fmt.Errorf("optional import at index %d is nil", i))
+		}
+	}
+	for i, pvdCtor := range cfg.ProvidersCtors {
+		if pvdCtor == nil {
+			errs = append(errs, fmt.Errorf("provider constructor at index %d is nil", i))
+		}
+	}
+	for i, ctrlCtor := range cfg.ControllersCtors {
+		if ctrlCtor == nil {
+			errs = append(errs, fmt.Errorf("controller constructor at index %d is nil", i))
+		}
+	}
+	for i, ctrl := range cfg.Controllers {
+		if ctrl == nil {
+			errs = append(errs, fmt.Errorf("controller at index %d is nil", i))
+		}
+	}
+	for i, grd := range cfg.Guards {
+		if grd == nil {
+			errs = append(errs, fmt.Errorf("guard at index %d is nil", i))
+		}
+	}
+	for i, ic := range cfg.Interceptors {
+		if ic == nil {
+			errs = append(errs, fmt.Errorf("interceptor at index %d is nil", i))
+		}
+	}
+	if cfg.Lazy && len(cfg.LazyPaths) == 0 {
+		errs = append(errs, errors.New("lazy module has no LazyPaths"))
+	}
+	for i, ec := range cfg.ErrorCodes {
+		switch {
+		case ec == nil:
+			errs = append(errs, fmt.Errorf("error code at index %d is nil", i))
+		case ec.Code == "":
+			errs = append(errs, fmt.Errorf("error code at index %d has no code", i))
+		case ec.Status < http.StatusBadRequest || ec.Status > 599:
+			errs = append(errs, fmt.Errorf("error code %q has invalid status %d", ec.Code, ec.Status))
+		}
+	}
+	for _, export := range cfg.ExportsCtors {
+		if !containsToken(cfg.ProvidersCtors, export, funcName) {
+			errs = append(errs, fmt.Errorf("exported constructor %s is not listed in ProvidersCtors", funcName(export)))
+		}
+	}
+	for _, export := range cfg.Exports {
+		if !containsToken(cfg.Providers, export, GetToken) {
+			errs = append(errs, fmt.Errorf("exported provider %T is not listed in Providers", export))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate reports every problem found in the controller config.
+func (cfg *ControllerConfig) validate() error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+
+	var errs []error
+	if !isValidHost(cfg.Host) {
+		errs = append(errs, fmt.Errorf("invalid host %q", cfg.Host))
+	}
+	for i, rCfg := range cfg.RoutesCfgs {
+		if rCfg == nil {
+			errs = append(errs, fmt.Errorf("route config at index %d is nil", i))
+		}
+	}
+	for i, grd := range cfg.Guards {
+		if grd == nil {
+			errs = append(errs, fmt.Errorf("guard at index %d is nil", i))
+		}
+	}
+	for i, ic := range cfg.Interceptors {
+		if ic == nil {
+			errs = append(errs, fmt.Errorf("interceptor at index %d is nil", i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate reports every problem found in the route config.
+func (cfg *RouteConfig) validate() error {
+	var errs []error
+	if !isValidHost(cfg.Host) {
+		errs = append(errs, fmt.Errorf("invalid host %q", cfg.Host))
+	}
+	if cfg.Handler == nil {
+		errs = append(errs, errors.New("handler is nil"))
+	}
+	if cfg.Method != "" && len(cfg.Methods) > 0 {
+		errs = append(errs, errors.New("method and methods are mutually exclusive"))
+	}
+	for _, method := range cfg.methods() {
+		if method != "" && !isValidMethod(method) || method == "" && len(cfg.Methods) > 0 {
+			errs = append(errs, fmt.Errorf("invalid method %q", method))
+		}
+	}
+	if len(cfg.Methods) != len(slices.Compact(slices.Sorted(slices.Values(cfg.Methods)))) {
+		errs = append(errs, errors.New("methods contain duplicates"))
+	}
+	if strings.ContainsAny(cfg.Pattern, " \t\n") {
+		errs = append(errs, fmt.Errorf("pattern %q contains whitespace", cfg.Pattern))
+	}
+	for _, mediaType := range slices.Concat(cfg.Consumes, cfg.Produces) {
+		if _, _, err := mime.ParseMediaType(mediaType); err != nil || !strings.Contains(mediaType, "/") {
+			errs = append(errs, fmt.Errorf("invalid media type %q", mediaType))
+		}
+	}
+	for i, grd := range cfg.Guards {
+		if grd == nil {
+			errs = append(errs, fmt.Errorf("guard at index %d is nil", i))
+		}
+	}
+	for i, ic := range cfg.Interceptors {
+		if ic == nil {
+			errs = append(errs, fmt.Errorf("interceptor at index %d is nil", i))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isValidHost reports whether the host is a valid host or host template.
+// An empty host matches every host.
+func isValidHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	if strings.ContainsAny(host, "/ \t\n") {
+		return false
+	}
+	_, err := parseHostTemplate(host)
+	return err == nil
+}
+
+// isValidMethod reports whether the method is a non-empty HTTP token as defined by RFC 9110.
+func isValidMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+	for _, c := range method {
+		isAlnum := ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+		if !isAlnum && !strings.ContainsRune("!#$%&'*+-.^_`|~", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsToken reports whether v is in values, comparing the tokens returned by token.
+func containsToken[T any](values []T, v T, token func(any) string) bool {
+	for _, value := range values {
+		if token(value) == token(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package godi
+
+import (
+	"cmp"
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrValidation is matched by the ValidationErrors of requests failing validation.
+var ErrValidation = errors.New("validation failed")
+
+// FieldError is a validation rule a field of a request body failed.
+type FieldError struct {
+	// Field is the path of the field in the request body, e.g. "items[0].name".
+	// It is empty for rules applying to the whole body.
+	Field string `json:"field"`
+
+	// Tag is the rule the field failed, e.g. "required" or "max".
+	Tag string `json:"tag"`
+
+	// Param is the parameter of the rule, if any, e.g. "100" for "max=100".
+	Param string `json:"param,omitempty"`
+
+	// Message is the user-facing description of the failure.
+	Message string `json:"message"`
+}
+
+// ValidationErrors are the rules a request body failed, returned by the Validate method
+// of request bodies, e.g. by adapting the errors of a struct tag validator:
+//
+//	func (req CreateUser) Validate() error {
+//		if req.Email == "" {
+//			return godi.ValidationErrors{{Field: "email", Tag: "required"}}
+//		}
+//		return nil
+//	}
+//
+// They are rendered with 422 by HandleJSON handlers, and the JSONErrorRenderer renders
+// them as {"status": 422, "error": "Unprocessable Entity", "errors": [...]}, after their
+// messages are translated by the application's ValidationTranslators.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + cmp.Or(fe.Message, fe.Tag)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ValidationErrorResponse is the body the JSONErrorRenderer renders ValidationErrors as,
+// described by the ValidationError schema of the routes validating their requests, so
+// clients can decode it.
+type ValidationErrorResponse struct {
+	Status int              `json:"status"`
+	Error  string           `json:"error"`
+	Errors ValidationErrors `json:"errors"`
+}
+
+// ValidationTranslator translates the failed rules of requests into user-facing messages
+// in a language, e.g. from message catalogs, reporting false if it has no message for it.
+type ValidationTranslator interface {
+	Translate(fe FieldError, lang string) (string, bool)
+}
+
+// ValidationTranslatorFunc is a function implementing ValidationTranslator.
+type ValidationTranslatorFunc func(fe FieldError, lang string) (string, bool)
+
+func (fn ValidationTranslatorFunc) Translate(fe FieldError, lang string) (string, bool) {
+	return fn(fe, lang)
+}
+
+// Messages is a ValidationTranslator of message templates by language and tag, in which
+// "{field}" and "{param}" are replaced by the field and parameter of the failed rule:
+//
+//	godi.Messages{
+//		"en": {"required": "{field} is required", "max": "{field} must be at most {param}"},
+//		"fr": {"required": "{field} est obligatoire"},
+//	}
+type Messages map[string]map[string]string
+
+func (m Messages) Translate(fe FieldError, lang string) (string, bool) {
+	tmpl, ok := m[lang][fe.Tag]
+	if !ok {
+		return "", false
+	}
+	return strings.NewReplacer("{field}", fe.Field, "{param}", fe.Param).Replace(tmpl), true
+}
+
+// WithValidationTranslators sets the translators of the messages of ValidationErrors,
+// tried in order for each language accepted by the request, then for "en". Failed rules
+// no translator has a message for keep their message, or are described by their tag.
+func WithValidationTranslators(translators ...ValidationTranslator) Option {
+	return func(o *options) {
+		o.validationTranslators = append(o.validationTranslators, translators...)
+	}
+}
+
+// TranslateValidation returns a copy of the errors with their messages translated by
+// the application's ValidationTranslators in the languages accepted by the request.
+func TranslateValidation(r *http.Request, errs ValidationErrors) ValidationErrors {
+	var translators []ValidationTranslator
+	if state := requestStateFrom(r.Context()); state != nil {
+		translators = state.translators
+	}
+
+	langs := append(acceptedLanguages(r.Header.Get("Accept-Language")), "en")
+	translated := make(ValidationErrors, len(errs))
+
+	for i, fe := range errs {
+		translated[i] = fe
+		translated[i].Message = translateFieldError(translators, fe, langs)
+	}
+	return translated
+}
+
+func translateFieldError(translators []ValidationTranslator, fe FieldError, langs []string) string {
+	for _, lang := range langs {
+		for _, t := range translators {
+			if msg, ok := t.Translate(fe, lang); ok {
+				return msg
+			}
+		}
+	}
+	if fe.Message != "" {
+		return fe.Message
+	}
+	if fe.Field == "" {
+		return "failed " + fe.Tag + " validation"
+	}
+	return fe.Field + " failed " + fe.Tag + " validation"
+}
+
+// acceptedLanguages returns the languages of an Accept-Language header by decreasing
+// preference, each followed by its base language, e.g. "fr-CA" then "fr".
+func acceptedLanguages(header string) []string {
+	type accepted struct {
+		lang string
+		q    float64
+	}
+
+	var list []accepted
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			list = append(list, accepted{lang: strings.ToLower(lang), q: q})
+		}
+	}
+
+	slices.SortStableFunc(list, func(a, b accepted) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	langs := make([]string, 0, len(list)*2)
+	for _, a := range list {
+		langs = append(langs, a.lang)
+		if base, _, ok := strings.Cut(a.lang, "-"); ok {
+			langs = append(langs, base)
+		}
+	}
+	return langs
+}
+
+// validateRequest calls the Validate method of the request body, if it has one.
+// Request bodies of pointer types are not validated when nil, e.g. when absent.
+func validateRequest[T any](req *T) error {
+	if v, ok := any(req).(Validator); ok {
+		return v.Validate()
+	}
+	if rv := reflect.ValueOf(*req); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		if v, ok := any(*req).(Validator); ok {
+			return v.Validate()
+		}
+	}
+	return nil
+}
+
+// validates reports whether request bodies of type t are validated by HandleJSON.
+func validates(t reflect.Type) bool {
+	validator := reflect.TypeFor[Validator]()
+	return t.Implements(validator) || reflect.PointerTo(t).Implements(validator)
+}
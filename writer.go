@@ -0,0 +1,93 @@
+package godi
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// ResponseBuffer is a ResponseWriter holding back a response so it can be sent later,
+// or discarded, e.g. by interceptors that must act on the outcome of a handler before
+// its response reaches the client.
+type ResponseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// NewResponseBuffer returns an empty ResponseBuffer.
+func NewResponseBuffer() *ResponseBuffer {
+	return &ResponseBuffer{header: http.Header{}}
+}
+
+func (b *ResponseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *ResponseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *ResponseBuffer) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// Status returns the status code written to the buffer, or 200 if none was.
+func (b *ResponseBuffer) Status() int {
+	return max(b.status, http.StatusOK)
+}
+
+// Replay writes the buffered response to w. It can be called several times.
+func (b *ResponseBuffer) Replay(w http.ResponseWriter) error {
+	for k, v := range b.header {
+		w.Header()[k] = append([]string(nil), v...)
+	}
+
+	w.WriteHeader(b.Status())
+	_, err := w.Write(b.body.Bytes())
+	return err
+}
+
+// StatusRecorder is a ResponseWriter recording the status code written by a handler,
+// e.g. for interceptors acting on the outcome of the requests they wrap.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// NewStatusRecorder returns a StatusRecorder writing the response to w.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+func (s *StatusRecorder) WriteHeader(status int) {
+	if s.status == 0 && status >= http.StatusOK {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *StatusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+func (s *StatusRecorder) FlushError() error {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+func (s *StatusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Status returns the status code written by the handler, or 200 if none was.
+func (s *StatusRecorder) Status() int {
+	return max(s.status, http.StatusOK)
+}